@@ -113,6 +113,46 @@ func (d jupyterDisplayer) Raw(contentType string, v interface{}, id *string) err
 	return nil
 }
 
+func (d jupyterDisplayer) Widget(modelID string, id *string) error {
+	v, err := core.WidgetView(modelID)
+	if err != nil {
+		return err
+	}
+	d.display(&scaffold.DisplayData{
+		Data: map[string]interface{}{
+			core.WidgetViewMIMEType: v,
+		},
+	}, id)
+	return nil
+}
+
+func (d jupyterDisplayer) DisplayBundle(bundle map[string]interface{}, metadata map[string]interface{}, id *string) error {
+	if _, err := json.Marshal(bundle); err != nil {
+		return err
+	}
+	d.display(&scaffold.DisplayData{
+		Data:     bundle,
+		Metadata: metadata,
+	}, id)
+	return nil
+}
+
+// SupportedMIMETypes returns the default set because Jupyter does not tell kernels which front-end is used.
+func (d jupyterDisplayer) SupportedMIMETypes() []string {
+	return core.DefaultSupportedMIMETypes()
+}
+
+func (d jupyterDisplayer) UpdateDisplay(id string, bundle map[string]interface{}) error {
+	if err := core.CheckDisplayUpdate(id, bundle); err != nil {
+		return err
+	}
+	d(&scaffold.DisplayData{
+		Data:      bundle,
+		Transient: map[string]interface{}{"display_id": id},
+	}, true)
+	return nil
+}
+
 func (d jupyterDisplayer) displayString(contentType, content string, id *string) {
 	d.display(&scaffold.DisplayData{
 		Data: map[string]interface{}{
@@ -142,6 +182,18 @@ func (d jupyterDisplayer) GIF(b []byte, id *string)      { d.displayBytes("image
 func (d jupyterDisplayer) PDF(b []byte, id *string)      { d.displayBytes("application/pdf", b, id) }
 func (d jupyterDisplayer) Text(s string, id *string)     { d.displayString("text/plain", s, id) }
 
+// statusProgress is the core.ProgressReporter of executions. The progress is reported as
+// {"lgo_progress": fraction} in the metadata of busy status messages so that it is not mixed with outputs of the cell.
+type statusProgress struct {
+	ctx context.Context
+}
+
+func (p statusProgress) ReportProgress(fraction float64) {
+	if err := scaffold.PublishStatusMetadata(p.ctx, map[string]interface{}{"lgo_progress": fraction}); err != nil {
+		glog.Errorf("Failed to report the progress: %v", err)
+	}
+}
+
 func (h *handlers) HandleExecuteRequest(ctx context.Context, r *scaffold.ExecuteRequest, stream func(string, string), displayData func(data *scaffold.DisplayData, update bool)) *scaffold.ExecuteResult {
 	h.execCount++
 	rDone := make(chan struct{})
@@ -165,8 +217,10 @@ func (h *handlers) HandleExecuteRequest(ctx context.Context, r *scaffold.Execute
 			ExecutionCount: h.execCount,
 		}
 	}
+	// TODO: Set Input once gojupyterscaffold supports input_request on the stdin channel.
 	lgoCtx := core.LgoContext{
 		Context: ctx, Display: jupyterDisplayer(displayData),
+		Progress: statusProgress{ctx},
 	}
 	func() {
 		defer func() {
@@ -177,7 +231,7 @@ func (h *handlers) HandleExecuteRequest(ctx context.Context, r *scaffold.Execute
 			}
 		}()
 		// Print the err in the notebook
-		if err = h.runner.Run(lgoCtx, r.Code); err != nil {
+		if err = h.runner.RunWithTags(lgoCtx, r.Code, cellTags(r.Metadata)); err != nil {
 			runner.PrintError(os.Stderr, err)
 		}
 	}()
@@ -197,6 +251,18 @@ func (h *handlers) HandleExecuteRequest(ctx context.Context, r *scaffold.Execute
 	}
 }
 
+// cellTags returns the tags in the metadata of an execute_request.
+func cellTags(metadata map[string]interface{}) []string {
+	vals, _ := metadata["tags"].([]interface{})
+	var tags []string
+	for _, v := range vals {
+		if s, ok := v.(string); ok {
+			tags = append(tags, s)
+		}
+	}
+	return tags
+}
+
 func runeOffsetToByteOffset(s string, roff int) int {
 	var runes int
 	for boff := range s {
@@ -34,7 +34,7 @@ type printer struct{}
 
 func (*printer) Println(args ...interface{}) {
 	for _, arg := range args {
-		fmt.Println(arg)
+		fmt.Println(core.FormatValue(arg))
 	}
 }
 
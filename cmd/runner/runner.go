@@ -27,7 +27,7 @@ import (
 */
 import "C"
 
-func loadShared(ctx core.LgoContext, buildPkgDir, pkgPath string) error {
+func loadShared(ctx core.LgoContext, buildPkgDir, pkgPath string, tags []string) error {
 	// This code is implemented based on https://golang.org/src/plugin/plugin_dlopen.go
 	sofile := "lib" + strings.Replace(pkgPath, "/", "-", -1) + ".so"
 	handle := C.dlopen(C.CString(path.Join(buildPkgDir, sofile)), C.RTLD_NOW|C.RTLD_GLOBAL)
@@ -58,9 +58,9 @@ func loadShared(ctx core.LgoContext, buildPkgDir, pkgPath string) error {
 	}
 	lgoInitFuncP := &lgoInitFuncPC
 	lgoInitFunc := *(*func())(unsafe.Pointer(&lgoInitFuncP))
-	return core.ExecLgoEntryPoint(ctx, func() {
+	return core.ExecLgoEntryPointWithTags(ctx, func() {
 		lgoInitFunc()
-	})
+	}, tags)
 }
 
 func loadSharedInternal(buildPkgDir, pkgPath string) {
@@ -151,6 +151,12 @@ func (rn *LgoRunner) installDeps(deps []string) error {
 const lgoExportPrefix = "LgoExport_"
 
 func (rn *LgoRunner) Run(ctx core.LgoContext, src string) error {
+	return rn.RunWithTags(ctx, src, nil)
+}
+
+// RunWithTags is Run which executes src with tags of the code block (e.g. tags of a cell).
+// See core.ExecLgoEntryPointWithTags.
+func (rn *LgoRunner) RunWithTags(ctx core.LgoContext, src string, tags []string) error {
 	rn.execCount++
 	sessDir := "github.com/yunabe/lgo/" + rn.sessID.Marshal()
 	pkgPath := path.Join(sessDir, fmt.Sprintf("exec%d", rn.execCount))
@@ -163,13 +169,15 @@ func (rn *LgoRunner) Run(ctx core.LgoContext, src string) error {
 		oldImports = append(oldImports, im)
 	}
 	result := converter.Convert(src, &converter.Config{
-		Olds:         olds,
-		OldImports:   oldImports,
-		DefPrefix:    lgoExportPrefix,
-		RefPrefix:    lgoExportPrefix,
-		LgoPkgPath:   pkgPath,
-		AutoExitCode: true,
-		RegisterVars: true,
+		Olds:             olds,
+		OldImports:       oldImports,
+		DefPrefix:        lgoExportPrefix,
+		RefPrefix:        lgoExportPrefix,
+		LgoPkgPath:       pkgPath,
+		AutoExitCode:     true,
+		RegisterVars:     true,
+		MarkAccessedVars: true,
+		SourceName:       fmt.Sprintf("cell%d.go", rn.execCount),
 	})
 	// converted, pkg, _, err
 	if result.Err != nil {
@@ -206,7 +214,7 @@ func (rn *LgoRunner) Run(ctx core.LgoContext, src string) error {
 	if err != nil {
 		return fmt.Errorf("Failed to build a shared library of %s: %v", pkgPath, err)
 	}
-	return loadShared(ctx, buildPkgDir, pkgPath)
+	return loadShared(ctx, buildPkgDir, pkgPath, tags)
 }
 
 func (rn *LgoRunner) Complete(ctx context.Context, src string, index int) (matches []string, start, end int) {
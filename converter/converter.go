@@ -216,11 +216,16 @@ func convertToPhase2(ph1 phase1Out, pkg *types.Package, checker *types.Checker,
 		}
 		if stmt == ph1.lastExpr {
 			var target ast.Expr
+			printer := "LgoPrintln"
 			if ph1.lastExprWrapped {
 				target = ph1.lastExpr.X.(*ast.CallExpr).Args[0]
 			} else if tuple, ok := checker.Types[ph1.lastExpr.X].Type.(*types.Tuple); !ok || tuple.Len() > 0 {
 				// "!ok" means single return value.
 				target = ph1.lastExpr.X
+				if ok && tuple.Len() > 1 {
+					// Print multiple return values as a tuple.
+					printer = "LgoPrintlnMulti"
+				}
 			}
 			if target != nil {
 				corePkg, err := lgoImporter.Import(core.SelfPkgPath)
@@ -231,7 +236,7 @@ func convertToPhase2(ph1 phase1Out, pkg *types.Package, checker *types.Checker,
 				ph1.lastExpr.X = &ast.CallExpr{
 					Fun: &ast.SelectorExpr{
 						X:   &ast.Ident{Name: immg.shortName(corePkg)},
-						Sel: &ast.Ident{Name: "LgoPrintln"},
+						Sel: &ast.Ident{Name: printer},
 					},
 					Args: []ast.Expr{target},
 				}
@@ -456,6 +461,11 @@ type Config struct {
 	LgoPkgPath   string
 	AutoExitCode bool
 	RegisterVars bool
+	// MarkAccessedVars injects core.MarkVarAccessed calls for variables in Olds referred from the code.
+	MarkAccessedVars bool
+	// SourceName is the name of the source (e.g. "cell1.go") used to embed positions of go statements.
+	// If it is not empty, go statements are tracked with core.InitGoroutineAt instead of core.InitGoroutine.
+	SourceName string
 }
 
 // A ConvertResult is a result of code conversion by Convert.
@@ -932,6 +942,61 @@ func prependPkgToOlds(conf *Config, checker *types.Checker, file *ast.File, immg
 	})
 }
 
+// injectMarkVarAccessed injects core.MarkVarAccessed calls for old variables referred in file to the head of lgo_init.
+// References from functions are also counted though the functions may not be called.
+// Nothing is injected if file does not have lgo_init.
+func injectMarkVarAccessed(conf *Config, checker *types.Checker, file *ast.File, immg *importManager) {
+	isOld := make(map[types.Object]bool)
+	for _, old := range conf.Olds {
+		if _, ok := old.(*types.Var); ok {
+			isOld[old] = true
+		}
+	}
+	used := make(map[string]bool)
+	for _, obj := range checker.Uses {
+		if isOld[obj] {
+			used[obj.Name()] = true
+		}
+	}
+	if len(used) == 0 {
+		return
+	}
+	var initFunc *ast.FuncDecl
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == lgoInitFuncName {
+			initFunc = fn
+		}
+	}
+	if initFunc == nil {
+		return
+	}
+	corePkg, err := lgoImporter.Import(core.SelfPkgPath)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to import core: %v", err))
+	}
+	var names []string
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var marks []ast.Stmt
+	for _, name := range names {
+		marks = append(marks, &ast.ExprStmt{X: &ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X:   &ast.Ident{Name: immg.shortName(corePkg)},
+				Sel: &ast.Ident{Name: "MarkVarAccessed"},
+			},
+			Args: []ast.Expr{
+				&ast.BasicLit{
+					Kind:  token.STRING,
+					Value: fmt.Sprintf("%q", name),
+				},
+			},
+		}})
+	}
+	initFunc.Body.List = append(marks, initFunc.Body.List...)
+}
+
 // prependPrefixToID prepends a prefix to the name of ident.
 // It prepends the prefix the last element if ident.Name contains "."
 func prependPrefixToID(indent *ast.Ident, prefix string) {
@@ -1081,6 +1146,9 @@ func finalCheckAndRename(file *ast.File, fset *token.FileSet, conf *Config) (str
 	}
 	immg := newImportManager(pkg, file, checker)
 	prependPkgToOlds(conf, checker, file, immg)
+	if conf.MarkAccessedVars {
+		injectMarkVarAccessed(conf, checker, file, immg)
+	}
 	rewriteExpr(file, func(expr ast.Expr) ast.Expr {
 		// Rewrite _ctx with core.GetExecContext().
 		id, ok := expr.(*ast.Ident)
@@ -1106,7 +1174,7 @@ func finalCheckAndRename(file *ast.File, fset *token.FileSet, conf *Config) (str
 	if conf.AutoExitCode {
 		injectAutoExitToFile(file, immg)
 	}
-	capturePanicInGoRoutine(file, immg, checker)
+	capturePanicInGoRoutine(file, fset, conf, immg, checker)
 
 	// Import lgo packages implicitly referred code inside functions.
 	var newDecls []ast.Decl
@@ -1208,9 +1276,9 @@ func finalCheckAndRename(file *ast.File, fset *token.FileSet, conf *Config) (str
 	return finalSrc, pkg, checker, deps, nil
 }
 
-func capturePanicInGoRoutine(file *ast.File, immg *importManager, checker *types.Checker) {
+func capturePanicInGoRoutine(file *ast.File, fset *token.FileSet, conf *Config, immg *importManager, checker *types.Checker) {
 	picker := newNamePicker(checker.Defs)
-	ast.Walk(&wrapGoStmtVisitor{immg, picker, checker}, file)
+	ast.Walk(&wrapGoStmtVisitor{immg, picker, checker, fset, conf.SourceName}, file)
 }
 
 // wrapGoStmtVisitor injects code to wrap go statements.
@@ -1232,10 +1300,14 @@ func capturePanicInGoRoutine(file *ast.File, immg *importManager, checker *types
 //   defer core.FinalizeGoRoutine(core.InitGoroutine())
 //   f(x, y)
 // }()
+//
+// If srcName is not empty, InitGoroutineAt and FinalizeGoroutineAt are used with the position of the go statement.
 type wrapGoStmtVisitor struct {
 	immg    *importManager
 	picker  *namePicker
 	checker *types.Checker
+	fset    *token.FileSet
+	srcName string
 }
 
 func (v *wrapGoStmtVisitor) Visit(node ast.Node) ast.Visitor {
@@ -1280,10 +1352,23 @@ func (v *wrapGoStmtVisitor) Visit(node ast.Node) ast.Visitor {
 		}
 		// Add ectx := InitGoroutine()
 		ectx := v.picker.NewName("ectx")
+		initName, finalName := "InitGoroutine", "FinalizeGoroutine"
+		var initArgs []ast.Expr
+		finalArgs := []ast.Expr{&ast.Ident{Name: ectx}}
+		if v.srcName != "" {
+			initName, finalName = "InitGoroutineAt", "FinalizeGoroutineAt"
+			pos := &ast.BasicLit{
+				Kind:  token.STRING,
+				Value: strconv.Quote(fmt.Sprintf("%s:%d", v.srcName, v.fset.Position(g.Pos()).Line)),
+			}
+			initArgs = []ast.Expr{pos}
+			finalArgs = append(finalArgs, pos)
+		}
 		body = append(body, &ast.AssignStmt{
 			Lhs: []ast.Expr{&ast.Ident{Name: ectx}},
 			Rhs: []ast.Expr{&ast.CallExpr{
-				Fun: ast.NewIdent(v.immg.shortName(corePkg) + ".InitGoroutine"),
+				Fun:  ast.NewIdent(v.immg.shortName(corePkg) + "." + initName),
+				Args: initArgs,
 			}},
 			Tok: token.DEFINE,
 		})
@@ -1302,9 +1387,9 @@ func (v *wrapGoStmtVisitor) Visit(node ast.Node) ast.Visitor {
 								Call: &ast.CallExpr{
 									Fun: &ast.SelectorExpr{
 										X:   &ast.Ident{Name: v.immg.shortName(corePkg)},
-										Sel: &ast.Ident{Name: "FinalizeGoroutine"},
+										Sel: &ast.Ident{Name: finalName},
 									},
-									Args: []ast.Expr{&ast.Ident{Name: ectx}},
+									Args: finalArgs,
 								},
 							},
 							&ast.ExprStmt{X: &ast.CallExpr{
@@ -192,6 +192,53 @@ func TestConvert_withOld(t *testing.T) {
 	checkGolden(t, result.Src, "testdata/withold.golden")
 }
 
+func TestConvert_markAccessedVars(t *testing.T) {
+	io, err := lgoImporter.Import("io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	olds := []types.Object{io.Scope().Lookup("EOF"), io.Scope().Lookup("Copy")}
+	src := `
+	err := EOF
+	Copy(nil, nil)
+	`
+	result := Convert(src, &Config{Olds: olds, MarkAccessedVars: true})
+	if result.Err != nil {
+		t.Fatal(result.Err)
+	}
+	if !strings.Contains(result.Src, `.MarkVarAccessed("EOF")`) {
+		t.Errorf("MarkVarAccessed is not injected: %s", result.Src)
+	}
+	if strings.Contains(result.Src, `.MarkVarAccessed("Copy")`) {
+		t.Errorf("MarkVarAccessed is injected for a func: %s", result.Src)
+	}
+
+	result = Convert(src, &Config{Olds: olds})
+	if result.Err != nil {
+		t.Fatal(result.Err)
+	}
+	if strings.Contains(result.Src, "MarkVarAccessed") {
+		t.Errorf("MarkVarAccessed is injected unexpectedly: %s", result.Src)
+	}
+}
+
+func TestConvert_goStmtPosition(t *testing.T) {
+	src := `
+	f := func() {}
+	go f()
+	`
+	result := Convert(src, &Config{SourceName: "cell1.go"})
+	if result.Err != nil {
+		t.Fatal(result.Err)
+	}
+	if !strings.Contains(result.Src, `core.InitGoroutineAt("cell1.go:3")`) {
+		t.Errorf("InitGoroutineAt is not injected: %s", result.Src)
+	}
+	if !strings.Contains(result.Src, `core.FinalizeGoroutineAt(ectx, "cell1.go:3")`) {
+		t.Errorf("FinalizeGoroutineAt is not injected: %s", result.Src)
+	}
+}
+
 func TestConvert_withOldPkgDup(t *testing.T) {
 	// This test demonstrates how old values are renamed if the package where an old value is defined is also imported in source code.
 	// This situation would not happen in the real world because old values must be defined in lgo-packages which should not be imported
@@ -490,6 +537,18 @@ func TestConvert_lastExpr(t *testing.T) {
 		return
 	}
 	checkGolden(t, result.Src, "testdata/last_expr3.golden")
+
+	result = Convert(`
+	func f() (int, string, error) {
+		return 1, "a", nil
+	}
+	f()
+	`, &Config{LgoPkgPath: "lgo/pkg0"})
+	if result.Err != nil {
+		t.Error(result.Err)
+		return
+	}
+	checkGolden(t, result.Src, "testdata/last_expr4.golden")
 }
 
 func TestConvert_emptyResult(t *testing.T) {
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// AfterExecution registers fn to be called with the report of the current code execution when it finishes
+// (e.g. to summarize or clean up based on the outcome). Callbacks are called in the order of registration
+// after the execution is torn down and its report is computed, so they can not display outputs to the execution.
+// Panics in fn are recovered and reported like panics of lgo routines (See SetPanicDisplayer and SetSuppressStderrPanics).
+//
+// If lgo does not execute any code blocks, fn is called immediately with a nil report.
+func AfterExecution(fn func(report *ExecReport)) {
+	e := getExecState()
+	if e == nil {
+		runAfterExecution(fn, nil)
+		return
+	}
+	e.afterMu.Lock()
+	defer e.afterMu.Unlock()
+	e.afterFns = append(e.afterFns, fn)
+}
+
+// runAfterExecution calls callbacks registered with AfterExecution with r.
+func (e *ExecutionState) runAfterExecution(r *ExecReport) {
+	e.afterMu.Lock()
+	fns := e.afterFns
+	e.afterFns = nil
+	e.afterMu.Unlock()
+	for _, fn := range fns {
+		runAfterExecution(fn, r)
+	}
+}
+
+// runAfterExecution calls fn with r. A panic in fn is appended to r.RecoveredPanics
+// because the execution of r has finished.
+func runAfterExecution(fn func(report *ExecReport), r *ExecReport) {
+	defer func() {
+		if p := recover(); p != nil {
+			msg, stack := "panic in AfterExecution callback: "+formatPanic(p), debug.Stack()
+			if r != nil && len(r.RecoveredPanics) < maxRecordedPanics {
+				r.RecoveredPanics = append(r.RecoveredPanics, fmt.Sprintf("%s\n\n%s", msg, stack))
+			}
+			emitPanic(msg, stack)
+		}
+	}()
+	fn(r)
+}
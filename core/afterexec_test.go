@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAfterExecution(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var calls []string
+	var got *ExecReport
+	r, err := ExecLgoEntryPointReport(LgoContext{Context: context.Background()}, func() {
+		AfterExecution(func(r *ExecReport) {
+			calls = append(calls, "first")
+			got = r
+			if getExecState() != nil {
+				t.Error("The execution is still active in AfterExecution callbacks")
+			}
+		})
+		AfterExecution(func(r *ExecReport) {
+			calls = append(calls, "panic")
+			panic("callback failed")
+		})
+		AfterExecution(func(r *ExecReport) {
+			calls = append(calls, "last")
+		})
+		state := InitGoroutine()
+		go func() {
+			defer FinalizeGoroutine(state)
+		}()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 3 || calls[0] != "first" || calls[1] != "panic" || calls[2] != "last" {
+		t.Errorf("Unexpected calls: %v", calls)
+	}
+	if got != r || got.Goroutines != 1 || got.Summary != "" || got.CancelReason != "" {
+		t.Errorf("Unexpected report: %+v", got)
+	}
+}
+
+func TestAfterExecutionCanceled(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var got *ExecReport
+	// Use ExecLgoEntryPoint to check callbacks are called without requesting reports.
+	err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		AfterExecution(func(r *ExecReport) {
+			got = r
+		})
+		Interrupt()
+		ExitIfCtxDone()
+	})
+	if err == nil {
+		t.Fatal("ExecLgoEntryPoint succeeded unexpectedly")
+	}
+	if got == nil || got.CancelReason != interruptedReason || got.Summary != err.Error() {
+		t.Errorf("Unexpected report: %+v (err = %v)", got, err)
+	}
+}
+
+func TestAfterExecutionIdle(t *testing.T) {
+	called := false
+	AfterExecution(func(r *ExecReport) {
+		called = true
+		if r != nil {
+			t.Errorf("Got %+v; want nil", r)
+		}
+	})
+	if !called {
+		t.Error("AfterExecution must call fn immediately when idle")
+	}
+}
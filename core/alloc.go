@@ -0,0 +1,61 @@
+package core
+
+import (
+	"runtime"
+	"sync"
+)
+
+// allocMu protects the allocation tracking settings and results below.
+var allocMu sync.Mutex
+
+// allocTracking indicates allocations of each execution are tracked.
+var allocTracking bool
+
+// lastAllocBytes and lastAllocObjects keep the allocations of the last tracked execution.
+var lastAllocBytes, lastAllocObjects uint64
+
+// SetAllocTracking enables or disables tracking of memory allocations of lgo executions.
+// When enabled, the bytes and the number of objects allocated in the last execution
+// are available from LastExecutionAllocs.
+//
+// Allocations are measured with runtime.ReadMemStats, which stops the world, at the start and the end
+// of each execution. Allocations of goroutines which run concurrently (e.g. goroutines left
+// from previous executions) are also counted.
+func SetAllocTracking(enabled bool) {
+	allocMu.Lock()
+	defer allocMu.Unlock()
+	allocTracking = enabled
+}
+
+// LastExecutionAllocs returns the bytes and the number of objects allocated in the last tracked execution.
+func LastExecutionAllocs() (bytes uint64, objects uint64) {
+	allocMu.Lock()
+	defer allocMu.Unlock()
+	return lastAllocBytes, lastAllocObjects
+}
+
+// startAllocTracking records the allocation counters at the start of e if tracking is enabled.
+func (e *ExecutionState) startAllocTracking() {
+	allocMu.Lock()
+	enabled := allocTracking
+	allocMu.Unlock()
+	if !enabled {
+		return
+	}
+	e.allocStart = new(runtime.MemStats)
+	runtime.ReadMemStats(e.allocStart)
+}
+
+// stopAllocTracking stores the allocations of e tracked by startAllocTracking.
+func (e *ExecutionState) stopAllocTracking() {
+	if e.allocStart == nil {
+		return
+	}
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	allocMu.Lock()
+	defer allocMu.Unlock()
+	lastAllocBytes = m.TotalAlloc - e.allocStart.TotalAlloc
+	lastAllocObjects = m.Mallocs - e.allocStart.Mallocs
+	e.allocStart = nil
+}
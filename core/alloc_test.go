@@ -0,0 +1,39 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+var allocSink [][]byte
+
+func TestAllocTracking(t *testing.T) {
+	SetAllocTracking(true)
+	defer SetAllocTracking(false)
+	atomic.StoreUint32(&isRunning, 0)
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		for i := 0; i < 100; i++ {
+			allocSink = append(allocSink, make([]byte, 1<<20))
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	allocSink = nil
+	bytes, objects := LastExecutionAllocs()
+	if bytes < 100<<20 || bytes > 110<<20 {
+		t.Errorf("Unexpected bytes: %d", bytes)
+	}
+	if objects < 100 || objects > 10000 {
+		t.Errorf("Unexpected objects: %d", objects)
+	}
+
+	// The result is not updated if tracking is disabled.
+	SetAllocTracking(false)
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {}); err != nil {
+		t.Fatal(err)
+	}
+	if b, o := LastExecutionAllocs(); b != bytes || o != objects {
+		t.Errorf("Got (%d, %d); want (%d, %d)", b, o, bytes, objects)
+	}
+}
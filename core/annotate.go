@@ -0,0 +1,186 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+)
+
+const (
+	// annotationBorder is the width of the borders of bounding boxes in pixels.
+	annotationBorder = 2
+	// annotationPadding is the padding around labels of bounding boxes in pixels.
+	annotationPadding = 2
+	// glyphWidth and glyphHeight are the size of characters of annotationFont in pixels.
+	glyphWidth  = 5
+	glyphHeight = 7
+)
+
+// defaultBoxColor is the color of bounding boxes without colors.
+var defaultBoxColor = color.RGBA{0xe5, 0x39, 0x35, 0xff}
+
+// annotationFont is a 5x7 bitmap font of labels of bounding boxes. Each row of a glyph is encoded
+// in the lower 5 bits from the left column to the right. Lowercase letters are drawn in uppercase and
+// characters not in the font are drawn as '?'.
+var annotationFont = map[rune][glyphHeight]uint8{
+	'0': {0x0e, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0e},
+	'1': {0x04, 0x0c, 0x04, 0x04, 0x04, 0x04, 0x0e},
+	'2': {0x0e, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1f},
+	'3': {0x1f, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0e},
+	'4': {0x02, 0x06, 0x0a, 0x12, 0x1f, 0x02, 0x02},
+	'5': {0x1f, 0x10, 0x1e, 0x01, 0x01, 0x11, 0x0e},
+	'6': {0x06, 0x08, 0x10, 0x1e, 0x11, 0x11, 0x0e},
+	'7': {0x1f, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08},
+	'8': {0x0e, 0x11, 0x11, 0x0e, 0x11, 0x11, 0x0e},
+	'9': {0x0e, 0x11, 0x11, 0x0f, 0x01, 0x02, 0x0c},
+	'A': {0x0e, 0x11, 0x11, 0x11, 0x1f, 0x11, 0x11},
+	'B': {0x1e, 0x11, 0x11, 0x1e, 0x11, 0x11, 0x1e},
+	'C': {0x0e, 0x11, 0x10, 0x10, 0x10, 0x11, 0x0e},
+	'D': {0x1c, 0x12, 0x11, 0x11, 0x11, 0x12, 0x1c},
+	'E': {0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x1f},
+	'F': {0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x10},
+	'G': {0x0e, 0x11, 0x10, 0x17, 0x11, 0x11, 0x0f},
+	'H': {0x11, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11},
+	'I': {0x0e, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0e},
+	'J': {0x07, 0x02, 0x02, 0x02, 0x02, 0x12, 0x0c},
+	'K': {0x11, 0x12, 0x14, 0x18, 0x14, 0x12, 0x11},
+	'L': {0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x1f},
+	'M': {0x11, 0x1b, 0x15, 0x15, 0x11, 0x11, 0x11},
+	'N': {0x11, 0x11, 0x19, 0x15, 0x13, 0x11, 0x11},
+	'O': {0x0e, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e},
+	'P': {0x1e, 0x11, 0x11, 0x1e, 0x10, 0x10, 0x10},
+	'Q': {0x0e, 0x11, 0x11, 0x11, 0x15, 0x12, 0x0d},
+	'R': {0x1e, 0x11, 0x11, 0x1e, 0x14, 0x12, 0x11},
+	'S': {0x0f, 0x10, 0x10, 0x0e, 0x01, 0x01, 0x1e},
+	'T': {0x1f, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04},
+	'U': {0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e},
+	'V': {0x11, 0x11, 0x11, 0x11, 0x11, 0x0a, 0x04},
+	'W': {0x11, 0x11, 0x11, 0x15, 0x15, 0x15, 0x0a},
+	'X': {0x11, 0x11, 0x0a, 0x04, 0x0a, 0x11, 0x11},
+	'Y': {0x11, 0x11, 0x11, 0x0a, 0x04, 0x04, 0x04},
+	'Z': {0x1f, 0x01, 0x02, 0x04, 0x08, 0x10, 0x1f},
+	' ': {},
+	'.': {0x00, 0x00, 0x00, 0x00, 0x00, 0x0c, 0x0c},
+	',': {0x00, 0x00, 0x00, 0x00, 0x0c, 0x04, 0x08},
+	'-': {0x00, 0x00, 0x00, 0x1f, 0x00, 0x00, 0x00},
+	'_': {0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1f},
+	':': {0x00, 0x0c, 0x0c, 0x00, 0x0c, 0x0c, 0x00},
+	'%': {0x18, 0x19, 0x02, 0x04, 0x08, 0x13, 0x03},
+	'(': {0x02, 0x04, 0x08, 0x08, 0x08, 0x04, 0x02},
+	')': {0x08, 0x04, 0x02, 0x02, 0x02, 0x04, 0x08},
+	'/': {0x00, 0x01, 0x02, 0x04, 0x08, 0x10, 0x00},
+	'#': {0x0a, 0x0a, 0x1f, 0x0a, 0x1f, 0x0a, 0x0a},
+	'?': {0x0e, 0x11, 0x01, 0x02, 0x04, 0x00, 0x04},
+}
+
+// BoundingBox is a rectangle annotated on an image with DisplayAnnotatedImage.
+type BoundingBox struct {
+	// Rect is the rectangle in the coordinates of the image.
+	Rect image.Rectangle
+	// Label is drawn above the rectangle. Empty if the box has no label.
+	Label string
+	// Color is the color of the rectangle and the background of the label. nil means red.
+	Color color.Color
+}
+
+// DisplayAnnotatedImage displays img with boxes drawn on a copy of img as PNG (e.g. objects
+// detected in the image). Labels are drawn above the boxes, or inside the boxes at the top edge of img,
+// with a small bitmap font of uppercase letters, digits and some symbols.
+// Boxes partially out of the bounds of img are clipped to the bounds. It returns an error if a box
+// is not well-formed (e.g. Rect.Min.X > Rect.Max.X) or does not overlap img.
+func DisplayAnnotatedImage(d DataDisplayer, img image.Image, boxes []BoundingBox, id *string) error {
+	if img == nil {
+		return errors.New("image is nil")
+	}
+	b := img.Bounds()
+	for i, box := range boxes {
+		if box.Rect != box.Rect.Canon() {
+			return fmt.Errorf("box %d has an invalid rectangle %v", i, box.Rect)
+		}
+		if box.Rect.Intersect(b).Empty() {
+			return fmt.Errorf("box %d %v is out of the image bounds %v", i, box.Rect, b)
+		}
+	}
+	dst := image.NewRGBA(b)
+	draw.Draw(dst, b, img, b.Min, draw.Src)
+	for _, box := range boxes {
+		drawBoundingBox(dst, box)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return err
+	}
+	d.PNG(buf.Bytes(), id)
+	return nil
+}
+
+// drawBoundingBox draws box on dst. box.Rect must overlap dst.
+func drawBoundingBox(dst *image.RGBA, box BoundingBox) {
+	c := box.Color
+	if c == nil {
+		c = defaultBoxColor
+	}
+	src := image.NewUniform(c)
+	r := box.Rect.Intersect(dst.Bounds())
+	w := annotationBorder
+	for _, edge := range []image.Rectangle{
+		image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+w),
+		image.Rect(r.Min.X, r.Max.Y-w, r.Max.X, r.Max.Y),
+		image.Rect(r.Min.X, r.Min.Y, r.Min.X+w, r.Max.Y),
+		image.Rect(r.Max.X-w, r.Min.Y, r.Max.X, r.Max.Y),
+	} {
+		draw.Draw(dst, edge.Intersect(r), src, image.Point{}, draw.Over)
+	}
+	if box.Label == "" {
+		return
+	}
+	label := []rune(box.Label)
+	lw := len(label)*(glyphWidth+1) - 1 + 2*annotationPadding
+	lh := glyphHeight + 2*annotationPadding
+	lr := image.Rect(r.Min.X, r.Min.Y-lh, r.Min.X+lw, r.Min.Y)
+	if lr.Min.Y < dst.Bounds().Min.Y {
+		// No space above the box.
+		lr = lr.Add(image.Pt(0, lh))
+	}
+	draw.Draw(dst, lr.Intersect(dst.Bounds()), src, image.Point{}, draw.Over)
+	drawLabel(dst, lr.Min.Add(image.Pt(annotationPadding, annotationPadding)), label, labelTextColor(c))
+}
+
+// labelTextColor returns the color of labels readable on the background bg.
+func labelTextColor(bg color.Color) color.Color {
+	r, g, b, _ := bg.RGBA()
+	// Same threshold as heatmapTextColor.
+	if 0.2126*float64(r>>8)+0.7152*float64(g>>8)+0.0722*float64(b>>8) > 140 {
+		return color.Black
+	}
+	return color.White
+}
+
+// drawLabel draws label with annotationFont at p of dst.
+func drawLabel(dst *image.RGBA, p image.Point, label []rune, c color.Color) {
+	b := dst.Bounds()
+	for i, ch := range label {
+		if ch >= 'a' && ch <= 'z' {
+			ch -= 'a' - 'A'
+		}
+		glyph, ok := annotationFont[ch]
+		if !ok {
+			glyph = annotationFont['?']
+		}
+		x0 := p.X + i*(glyphWidth+1)
+		for y, row := range glyph {
+			for x := 0; x < glyphWidth; x++ {
+				if row&(1<<uint(glyphWidth-1-x)) == 0 {
+					continue
+				}
+				if pt := image.Pt(x0+x, p.Y+y); pt.In(b) {
+					dst.Set(pt.X, pt.Y, c)
+				}
+			}
+		}
+	}
+}
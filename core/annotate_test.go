@@ -0,0 +1,83 @@
+package core
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"testing"
+)
+
+// whiteImage returns a white image of w x h.
+func whiteImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	return img
+}
+
+func TestDisplayAnnotatedImage(t *testing.T) {
+	img := whiteImage(40, 30)
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	boxes := []BoundingBox{
+		{Rect: image.Rect(5, 15, 20, 28), Label: "a", Color: red},
+		// Clipped to the bounds.
+		{Rect: image.Rect(30, -10, 100, 10)},
+	}
+	var d fakeDisplayer
+	id := ""
+	if err := DisplayAnnotatedImage(&d, img, boxes, &id); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.contents) != 1 || d.contents[0].contentType != "image/png" || id != "id1" {
+		t.Fatalf("Unexpected contents: %v (id: %q)", d.contents, id)
+	}
+	out, err := png.Decode(bytes.NewReader(d.contents[0].content.([]byte)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	white := color.RGBA{0xff, 0xff, 0xff, 0xff}
+	tests := []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{5, 20, red},    // the left border
+		{19, 20, red},   // the right border
+		{10, 27, red},   // the bottom border
+		{10, 20, white}, // inside the box
+		{7, 6, red},     // the background of the label above the box
+		{8, 6, white},   // the top of "A"
+		{30, 5, defaultBoxColor},
+		{35, 5, white},
+		{2, 2, white},
+	}
+	for _, tc := range tests {
+		if got := color.RGBAModel.Convert(out.At(tc.x, tc.y)).(color.RGBA); got != tc.want {
+			t.Errorf("(%d, %d): Got %v; want %v", tc.x, tc.y, got, tc.want)
+		}
+	}
+	// The original image is not modified.
+	if got := img.RGBAAt(5, 20); got != white {
+		t.Errorf("The image is modified: %v", got)
+	}
+}
+
+func TestDisplayAnnotatedImageError(t *testing.T) {
+	img := whiteImage(10, 10)
+	var d fakeDisplayer
+	tests := []struct {
+		box  BoundingBox
+		want string
+	}{
+		{BoundingBox{Rect: image.Rect(20, 20, 30, 30)}, "box 0 (20,20)-(30,30) is out of the image bounds (0,0)-(10,10)"},
+		{BoundingBox{Rect: image.Rectangle{Min: image.Pt(5, 5), Max: image.Pt(2, 8)}}, "box 0 has an invalid rectangle (5,5)-(2,8)"},
+	}
+	for _, tc := range tests {
+		if err := DisplayAnnotatedImage(&d, img, []BoundingBox{tc.box}, nil); err == nil || err.Error() != tc.want {
+			t.Errorf("Got %v; want %q", err, tc.want)
+		}
+	}
+	if len(d.contents) != 0 {
+		t.Errorf("Unexpected contents: %v", d.contents)
+	}
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+)
+
+// OutputAreaMetadataKey is the key of the display_data metadata which names the output area of the content.
+const OutputAreaMetadataKey = "lgo_output_area"
+
+// BundleDisplayer is the interface implemented by DataDisplayers which display a bundle, which maps
+// MIME types to contents, with metadata in one display_data message.
+type BundleDisplayer interface {
+	DisplayBundle(bundle map[string]interface{}, metadata map[string]interface{}, id *string) error
+}
+
+// errBundleUnsupported is returned from DisplayBundle of DataDisplayers which wrap a DataDisplayer without BundleDisplayer.
+var errBundleUnsupported = errors.New("bundle is not supported")
+
+// fallbackMIMETypes is the preference of MIME types to display a bundle without BundleDisplayer.
+var fallbackMIMETypes = []string{
+	"text/html",
+	"image/svg+xml",
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"text/markdown",
+	"text/latex",
+	"text/plain",
+}
+
+// DisplayTo displays bundle, which maps MIME types to contents, to the output area named area
+// (e.g. a sidebar) in front-ends which support multiple output areas.
+// The area is sent as the display_data metadata of OutputAreaMetadataKey. Front-ends which do not know
+// the area display the content in the main output of the cell. If area is empty, the main output is used.
+//
+// If d does not implement BundleDisplayer, the content of the most preferred MIME type in bundle
+// which is supported by d (See DataDisplayer.SupportedMIMETypes) is displayed to the main output.
+func DisplayTo(d DataDisplayer, area string, bundle map[string]interface{}, id *string) error {
+	if len(bundle) == 0 {
+		return errors.New("bundle is empty")
+	}
+	if _, err := json.Marshal(bundle); err != nil {
+		return err
+	}
+	if bd, ok := d.(BundleDisplayer); ok {
+		metadata := make(map[string]interface{})
+		if area != "" {
+			metadata[OutputAreaMetadataKey] = area
+		}
+		if err := bd.DisplayBundle(bundle, metadata, id); err != errBundleUnsupported {
+			return err
+		}
+	}
+	contentType := preferredMIMEType(bundle, d.SupportedMIMETypes())
+	return d.Raw(contentType, bundle[contentType], id)
+}
+
+// preferredMIMEType returns the most preferred MIME type in bundle. MIME types in supported are preferred.
+func preferredMIMEType(bundle map[string]interface{}, supported []string) string {
+	for _, t := range fallbackMIMETypes {
+		if _, ok := bundle[t]; ok && containsString(supported, t) {
+			return t
+		}
+	}
+	for _, t := range supported {
+		if _, ok := bundle[t]; ok {
+			return t
+		}
+	}
+	for _, t := range fallbackMIMETypes {
+		if _, ok := bundle[t]; ok {
+			return t
+		}
+	}
+	var types []string
+	for t := range bundle {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types[0]
+}
+
+func containsString(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
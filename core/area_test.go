@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+// bundleDisplayer is a fakeDisplayer which supports bundles.
+type bundleDisplayer struct {
+	fakeDisplayer
+	metadata []map[string]interface{}
+}
+
+func (d *bundleDisplayer) DisplayBundle(bundle map[string]interface{}, metadata map[string]interface{}, id *string) error {
+	d.display("bundle", bundle, id)
+	d.metadata = append(d.metadata, metadata)
+	return nil
+}
+
+func TestDisplayTo(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var bd bundleDisplayer
+	bundle := map[string]interface{}{"text/plain": "a", "text/html": "<b>a</b>"}
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Display: &bd}, func() {
+		if err := DisplayTo(GetExecContext().Display, "sidebar", bundle, nil); err != nil {
+			t.Error(err)
+		}
+		if err := DisplayTo(GetExecContext().Display, "", bundle, nil); err != nil {
+			t.Error(err)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]interface{}{{OutputAreaMetadataKey: "sidebar"}, {}}
+	if !reflect.DeepEqual(bd.metadata, want) {
+		t.Errorf("Got %v; want %v", bd.metadata, want)
+	}
+	if len(bd.contents) != 2 || bd.contents[0].contentType != "bundle" {
+		t.Errorf("Unexpected contents: %v", bd.contents)
+	}
+
+	// Fall back to the preferred content without BundleDisplayer.
+	atomic.StoreUint32(&isRunning, 0)
+	var fd fakeDisplayer
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Display: &fd}, func() {
+		if err := DisplayTo(GetExecContext().Display, "sidebar", bundle, nil); err != nil {
+			t.Error(err)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(fd.contents) != 1 || fd.contents[0].contentType != "text/html" {
+		t.Errorf("Unexpected contents: %v", fd.contents)
+	}
+	if err := DisplayTo(&fd, "sidebar", nil, nil); err == nil {
+		t.Error("DisplayTo must fail with an empty bundle")
+	}
+}
+
+func TestDisplayToSupportedMIMETypes(t *testing.T) {
+	fd := fakeDisplayer{mimeTypes: []string{"text/plain", "image/png"}}
+	bundle := map[string]interface{}{"text/plain": "a", "text/html": "<b>a</b>", "image/png": []byte("png")}
+	if err := DisplayTo(&fd, "", bundle, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(fd.contents) != 1 || fd.contents[0].contentType != "image/png" {
+		t.Errorf("Unexpected contents: %v", fd.contents)
+	}
+}
@@ -0,0 +1,78 @@
+package core
+
+import "time"
+
+// NotifyOnCancel registers ch to receive a value when the current code execution is canceled
+// (e.g. interrupted) so that libraries with their own notification patterns can hook into the cancellation
+// without selecting on the context. A value is sent to ch exactly once. If the execution is canceled already,
+// the value is sent immediately. Executions which finish without cancellation send nothing.
+//
+// The value is sent without blocking if ch is ready (e.g. ch is buffered). Otherwise, it is sent when a receiver
+// gets ready until the execution finishes. The registration is removed when the execution finishes.
+// If lgo does not execute any code blocks, the value is sent to ch as if the execution is canceled.
+// Then, the value is dropped unless a receiver gets ready within the time lgo waits for goroutines
+// after a cancellation (1s).
+func NotifyOnCancel(ch chan<- struct{}) {
+	e := getExecState()
+	if e == nil {
+		notifyIdle(ch)
+		return
+	}
+	e.cancelMu.Lock()
+	if e.notifyClosed || (e.canceled && e.cancelReason == "") {
+		// The execution finished without cancellation.
+		e.cancelMu.Unlock()
+		return
+	}
+	if !e.canceled {
+		e.cancelNotify = append(e.cancelNotify, ch)
+		e.cancelMu.Unlock()
+		return
+	}
+	e.cancelMu.Unlock()
+	e.notifyCancel(ch)
+}
+
+// notifyCancel sends the cancellation of e to ch.
+func (e *ExecutionState) notifyCancel(ch chan<- struct{}) {
+	select {
+	case ch <- struct{}{}:
+		return
+	default:
+	}
+	go func() {
+		select {
+		case ch <- struct{}{}:
+		case <-e.notifyDone:
+		}
+	}()
+}
+
+// notifyIdle sends a value to ch when no code is executed.
+func notifyIdle(ch chan<- struct{}) {
+	select {
+	case ch <- struct{}{}:
+		return
+	default:
+	}
+	timer := time.NewTimer(getExecWaitDuration())
+	go func() {
+		defer timer.Stop()
+		select {
+		case ch <- struct{}{}:
+		case <-timer.C:
+		}
+	}()
+}
+
+// stopCancelNotify removes the channels registered with NotifyOnCancel and stops pending notifications.
+func (e *ExecutionState) stopCancelNotify() {
+	e.cancelMu.Lock()
+	defer e.cancelMu.Unlock()
+	if e.notifyClosed {
+		return
+	}
+	e.notifyClosed = true
+	e.cancelNotify = nil
+	close(e.notifyDone)
+}
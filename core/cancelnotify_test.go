@@ -0,0 +1,79 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNotifyOnCancel(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	before := make(chan struct{}, 2)
+	unbuffered := make(chan struct{})
+	after := make(chan struct{}, 2)
+	registered := make(chan struct{})
+	e := startExec(LgoContext{Context: ctx}, func() {
+		NotifyOnCancel(before)
+		NotifyOnCancel(unbuffered)
+		close(registered)
+		<-unbuffered
+		// Registered after the cancellation.
+		NotifyOnCancel(after)
+		<-GetExecContext().Done()
+		panic(Bailout)
+	})
+	<-registered
+	cancel()
+	finalizeExec(e)
+	for name, ch := range map[string]chan struct{}{"before": before, "after": after} {
+		if n := len(ch); n != 1 {
+			t.Errorf("%s: Got %d notifications; want 1", name, n)
+		}
+	}
+}
+
+func TestNotifyOnCancelNotCanceled(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	ch := make(chan struct{}, 1)
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		NotifyOnCancel(ch)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-ch:
+		t.Error("Executions finished without cancellation must not notify")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// Idle.
+	NotifyOnCancel(ch)
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Error("NotifyOnCancel must notify when lgo does not execute code")
+	}
+}
+
+func TestNotifyOnCancelIdleUnbuffered(t *testing.T) {
+	defer setExecWaitDuration(setExecWaitDuration(10 * time.Millisecond))
+	atomic.StoreUint32(&isRunning, 0)
+	ch := make(chan struct{})
+	NotifyOnCancel(ch)
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case <-ch:
+		t.Error("The notification must be dropped if nobody receives it")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// A receiver gets ready in time.
+	NotifyOnCancel(ch)
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Error("NotifyOnCancel must notify when lgo does not execute code")
+	}
+}
@@ -0,0 +1,40 @@
+//go:build go1.18
+// +build go1.18
+
+package core
+
+import (
+	"io"
+	"time"
+)
+
+// Batch receives items from ch until maxSize items are received or maxWait elapses, whichever comes first,
+// and returns the received items. It returns io.EOF if ch is closed, with the items received before it was closed.
+// If maxSize <= 0, the number of items is not limited. If maxWait <= 0, Batch waits without a timeout.
+//
+// Batch returns Bailout with the items received so far when the current code execution is canceled.
+// Like ExitIfCtxDone, Batch regards the context as canceled if lgo does not execute any code blocks.
+func Batch[T any](ch <-chan T, maxSize int, maxWait time.Duration) ([]T, error) {
+	var timeout <-chan time.Time
+	if maxWait > 0 {
+		timer := time.NewTimer(maxWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	done := GetExecContext().Done()
+	var items []T
+	for maxSize <= 0 || len(items) < maxSize {
+		select {
+		case v, ok := <-ch:
+			if !ok {
+				return items, io.EOF
+			}
+			items = append(items, v)
+		case <-timeout:
+			return items, nil
+		case <-done:
+			return items, Bailout
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,76 @@
+//go:build go1.18
+// +build go1.18
+
+package core
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// runBatchTest runs f in a code execution because Batch outside executions is canceled.
+func runBatchTest(f func()) {
+	atomic.StoreUint32(&isRunning, 0)
+	finalizeExec(startExec(LgoContext{Context: context.Background()}, f))
+}
+
+func TestBatchSize(t *testing.T) {
+	runBatchTest(func() { testBatchSize(t) })
+}
+
+func testBatchSize(t *testing.T) {
+	ch := make(chan int, 5)
+	for i := 0; i < 5; i++ {
+		ch <- i
+	}
+	items, err := Batch(ch, 3, time.Hour)
+	if err != nil || !reflect.DeepEqual(items, []int{0, 1, 2}) {
+		t.Errorf("Got (%v, %v); want ([0 1 2], <nil>)", items, err)
+	}
+	close(ch)
+	items, err = Batch(ch, 3, time.Hour)
+	if err != io.EOF || !reflect.DeepEqual(items, []int{3, 4}) {
+		t.Errorf("Got (%v, %v); want ([3 4], EOF)", items, err)
+	}
+}
+
+func TestBatchTimeout(t *testing.T) {
+	runBatchTest(func() { testBatchTimeout(t) })
+}
+
+func testBatchTimeout(t *testing.T) {
+	ch := make(chan string, 1)
+	ch <- "a"
+	start := time.Now()
+	items, err := Batch(ch, 10, 20*time.Millisecond)
+	if err != nil || !reflect.DeepEqual(items, []string{"a"}) {
+		t.Errorf("Got (%v, %v); want ([a], <nil>)", items, err)
+	}
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Errorf("Batch returned before maxWait: %v", d)
+	}
+	if items, err := Batch(ch, 10, time.Millisecond); err != nil || len(items) != 0 {
+		t.Errorf("Got (%v, %v); want ([], <nil>)", items, err)
+	}
+}
+
+func TestBatchCancel(t *testing.T) {
+	ch := make(chan int, 1)
+	ch <- 1
+	var items []int
+	var err error
+	runBatchTest(func() {
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			Interrupt()
+		}()
+		items, err = Batch(ch, 2, 0)
+	})
+	if err != Bailout || !reflect.DeepEqual(items, []int{1}) {
+		t.Errorf("Got (%v, %v); want ([1], Bailout)", items, err)
+	}
+}
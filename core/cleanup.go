@@ -0,0 +1,102 @@
+package core
+
+import (
+	"time"
+)
+
+// RegisterCleanup registers f to be called when the current code execution finishes or is canceled.
+// Cleanups are called in the reverse order of registration. If lgo does not execute any code blocks,
+// f is called immediately.
+//
+// An execution is torn down in the following order:
+//  1. Timers and tickers created with AfterFunc and NewTicker are stopped.
+//  2. Cleanups are called in LIFO order.
+//  3. The context of the execution is canceled.
+//
+// Because the context is canceled after cleanups, cleanups must not wait for routines which
+// quit on the cancellation of the context.
+//
+// If RegisterCleanup is called while the execution is being torn down (e.g. from a goroutine racing
+// with the cancellation), f is called immediately instead of being queued so that f is not lost.
+func RegisterCleanup(f func()) {
+	e := getExecState()
+	if e == nil {
+		runCleanup(f)
+		return
+	}
+	e.cleanupMu.Lock()
+	tearingDown := e.tearingDown
+	if !tearingDown {
+		e.cleanups = append(e.cleanups, f)
+	}
+	e.cleanupMu.Unlock()
+	if tearingDown {
+		// Don't hold the lock while calling f because f may register other cleanups.
+		runCleanup(f)
+	}
+}
+
+// AfterFunc is time.AfterFunc whose timer is stopped when the current code execution is torn down.
+// See RegisterCleanup for the order of the teardown.
+func AfterFunc(d time.Duration, f func()) *time.Timer {
+	t := time.AfterFunc(d, guardCallback("AfterFunc", f))
+	if e := getExecState(); e != nil {
+		e.cleanupMu.Lock()
+		defer e.cleanupMu.Unlock()
+		if e.tearingDown {
+			t.Stop()
+		} else {
+			e.timers = append(e.timers, t)
+		}
+	}
+	return t
+}
+
+// NewTicker is time.NewTicker whose ticker is stopped when the current code execution is torn down.
+// See RegisterCleanup for the order of the teardown.
+func NewTicker(d time.Duration) *time.Ticker {
+	t := time.NewTicker(d)
+	if e := getExecState(); e != nil {
+		e.cleanupMu.Lock()
+		defer e.cleanupMu.Unlock()
+		if e.tearingDown {
+			t.Stop()
+		} else {
+			e.tickers = append(e.tickers, t)
+		}
+	}
+	return t
+}
+
+// teardown stops timers and calls cleanups of e. teardown does this only once and
+// other callers wait until the first call finishes. Cleanups, timers and tickers registered
+// after teardown starts are called or stopped immediately.
+func (e *ExecutionState) teardown() {
+	e.teardownOnce.Do(func() {
+		e.cleanupMu.Lock()
+		e.tearingDown = true
+		timers, tickers, cleanups := e.timers, e.tickers, e.cleanups
+		e.timers, e.tickers, e.cleanups = nil, nil, nil
+		e.cleanupMu.Unlock()
+
+		for _, t := range timers {
+			t.Stop()
+		}
+		for _, t := range tickers {
+			t.Stop()
+		}
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			runCleanup(cleanups[i])
+		}
+	})
+}
+
+// runCleanup calls f. A panic in f is reported with reportRecoveredPanic so that the other cleanups are called.
+func runCleanup(f func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			reportRecoveredPanic("in cleanup", r)
+		}
+	}()
+	f()
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTeardownOrder(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var events []string
+	var timer *time.Timer
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		ctx := GetExecContext()
+		timer = AfterFunc(time.Hour, func() {})
+		NewTicker(time.Hour)
+		RegisterCleanup(func() {
+			events = append(events, "cleanup1")
+		})
+		RegisterCleanup(func() {
+			// The timer is stopped already.
+			if timer.Stop() {
+				events = append(events, "timer is running")
+			}
+			if ctx.Err() != nil {
+				events = append(events, "ctx is canceled")
+			}
+			events = append(events, "cleanup2")
+		})
+		RegisterCleanup(func() {
+			panic("cleanup panics")
+		})
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"cleanup2", "cleanup1"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("Got %v; want %v", events, want)
+	}
+}
+
+func TestTeardownOnCancel(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var events []string
+	e := startExec(LgoContext{Context: context.Background()}, func() {
+		RegisterCleanup(func() {
+			events = append(events, "cleanup")
+		})
+		<-GetExecContext().Done()
+		panic(Bailout)
+	})
+	for i := 0; ; i++ {
+		e.cleanupMu.Lock()
+		n := len(e.cleanups)
+		e.cleanupMu.Unlock()
+		if n > 0 {
+			break
+		}
+		if i >= 100 {
+			t.Fatal("The cleanup is not registered")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	e.cancel()
+	// Cleanups are called before cancel returns.
+	if want := []string{"cleanup"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("Got %v; want %v", events, want)
+	}
+	if err := finalizeExec(e); err == nil || err.Error() != "main routine canceled" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestRegisterCleanupIdle(t *testing.T) {
+	setExecState(nil)
+	called := false
+	RegisterCleanup(func() { called = true })
+	if !called {
+		t.Error("The cleanup is not called when idle")
+	}
+}
+
+func TestRegisterCleanupDuringCancel(t *testing.T) {
+	// Run with go test -race
+	atomic.StoreUint32(&isRunning, 0)
+	var registered, called int64
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	e := startExec(LgoContext{Context: ctx}, func() {
+		for i := 0; i < 4; i++ {
+			state := InitGoroutine()
+			go func() {
+				defer FinalizeGoroutine(state)
+				for state.Context.Err() == nil {
+					RegisterCleanup(func() {
+						atomic.AddInt64(&called, 1)
+					})
+					atomic.AddInt64(&registered, 1)
+					runtime.Gosched()
+				}
+				// Cleanups registered after the cancellation are called immediately.
+				RegisterCleanup(func() {
+					atomic.AddInt64(&called, 1)
+				})
+				atomic.AddInt64(&registered, 1)
+			}()
+		}
+		close(started)
+	})
+	<-started
+	for atomic.LoadInt64(&registered) < 100 {
+		runtime.Gosched()
+	}
+	cancel()
+	finalizeExec(e)
+	if r, c := atomic.LoadInt64(&registered), atomic.LoadInt64(&called); r != c {
+		t.Errorf("%d cleanups are called for %d registrations", c, r)
+	}
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// Clearable is the interface implemented by objects which release their resources (e.g. caches)
+// when variables are cleared with ZeroClearAllVars. See RegisterClearable.
+type Clearable interface {
+	LgoClear()
+}
+
+// clearablesMu protects clearables.
+var clearablesMu sync.Mutex
+
+// clearables keeps Clearables registered with RegisterClearable.
+var clearables = make(map[Clearable]bool)
+
+// RegisterClearable registers c so that c.LgoClear is called when ZeroClearAllVars clears variables.
+// c must be comparable (e.g. a pointer). Registering c twice has no effect; LgoClear is called once per clear.
+func RegisterClearable(c Clearable) {
+	clearablesMu.Lock()
+	defer clearablesMu.Unlock()
+	clearables[c] = true
+}
+
+// UnregisterClearable removes c registered with RegisterClearable.
+func UnregisterClearable(c Clearable) {
+	clearablesMu.Lock()
+	defer clearablesMu.Unlock()
+	delete(clearables, c)
+}
+
+// clearClearables calls LgoClear of registered Clearables.
+// A panic in LgoClear is reported like panics of lgo routines so that the other Clearables and variables are cleared.
+func clearClearables() {
+	clearablesMu.Lock()
+	var cs []Clearable
+	for c := range clearables {
+		cs = append(cs, c)
+	}
+	clearablesMu.Unlock()
+	// Don't hold the lock while calling LgoClear.
+	for _, c := range cs {
+		callLgoClear(c)
+	}
+}
+
+func callLgoClear(c Clearable) {
+	defer func() {
+		if r := recover(); r != nil {
+			reportRecoveredPanic("in LgoClear", r)
+		}
+	}()
+	c.LgoClear()
+}
+
+// ClearOptions controls how ZeroClearAllVarsOpts reclaims memory of cleared variables.
+//
+// Forcing the garbage collection releases memory of cleared variables immediately but blocks
+// the kernel until it finishes, which can take seconds on large heaps. In the background,
+// the kernel responds immediately while the collection competes with the next executions for CPU.
+// Without forcing, memory is released by the regular garbage collection and may not be returned
+// to the OS for minutes.
+type ClearOptions struct {
+	// ForceGC runs the garbage collection with runtime.GC after clearing.
+	ForceGC bool
+	// FreeOSMemory returns memory to the OS with debug.FreeOSMemory, which also runs the garbage collection.
+	FreeOSMemory bool
+	// Background reclaims memory in a background goroutine and ZeroClearAllVarsOpts returns without waiting.
+	// If memory is being reclaimed in the background, another reclamation is not started.
+	Background bool
+}
+
+// DefaultClearOptions returns the options used by ZeroClearAllVars. Memory is reclaimed synchronously.
+func DefaultClearOptions() ClearOptions {
+	return ClearOptions{ForceGC: true, FreeOSMemory: true}
+}
+
+// reclaimPending is 1 while memory is reclaimed in the background. Use atomic.CompareAndSwapUint32.
+var reclaimPending uint32
+
+// reclaimWaitMu protects reclaimWait.
+var reclaimWaitMu sync.Mutex
+
+// reclaimWait tracks background reclamations so that tests can wait for them. nil by default.
+var reclaimWait *sync.WaitGroup
+
+// setReclaimWait sets reclaimWait to wg and returns the previous value.
+func setReclaimWait(wg *sync.WaitGroup) *sync.WaitGroup {
+	reclaimWaitMu.Lock()
+	defer reclaimWaitMu.Unlock()
+	prev := reclaimWait
+	reclaimWait = wg
+	return prev
+}
+
+// reclaimMemory runs the garbage collection and returns memory to the OS as opts specifies.
+func reclaimMemory(opts ClearOptions) {
+	if !opts.ForceGC && !opts.FreeOSMemory {
+		return
+	}
+	reclaim := func() {
+		if opts.FreeOSMemory {
+			// Return memory to OS.
+			debug.FreeOSMemory()
+		}
+		if opts.ForceGC {
+			runtime.GC()
+		}
+	}
+	if !opts.Background {
+		reclaim()
+		return
+	}
+	if !atomic.CompareAndSwapUint32(&reclaimPending, 0, 1) {
+		return
+	}
+	reclaimWaitMu.Lock()
+	wg := reclaimWait
+	if wg != nil {
+		wg.Add(1)
+	}
+	reclaimWaitMu.Unlock()
+	go func() {
+		if wg != nil {
+			defer wg.Done()
+		}
+		defer atomic.StoreUint32(&reclaimPending, 0)
+		reclaim()
+	}()
+}
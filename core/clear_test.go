@@ -0,0 +1,130 @@
+package core
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+type countClearable struct {
+	n int
+	// cleared is the value of the variable when LgoClear is called.
+	cleared int
+	v       *int
+}
+
+func (c *countClearable) LgoClear() {
+	c.n++
+	c.cleared = *c.v
+}
+
+type panicClearable struct{}
+
+func (panicClearable) LgoClear() { panic("LgoClear panics") }
+
+func TestClearable(t *testing.T) {
+	defer func() {
+		AllVars = make(map[string][]interface{})
+		varAccessed = make(map[string]bool)
+		clearables = make(map[Clearable]bool)
+	}()
+	setExecState(nil)
+	v := 10
+	LgoRegisterVar("v", &v)
+	c := &countClearable{v: &v}
+	RegisterClearable(c)
+	RegisterClearable(c)
+	RegisterClearable(panicClearable{})
+	if err := ZeroClearAllVars(); err != nil {
+		t.Fatal(err)
+	}
+	if c.n != 1 {
+		t.Errorf("Got %d; want 1", c.n)
+	}
+	// Clearables are cleared before variables.
+	if c.cleared != 10 {
+		t.Errorf("Got %d; want 10", c.cleared)
+	}
+	if v != 0 {
+		t.Errorf("v is not cleared: %d", v)
+	}
+	UnregisterClearable(c)
+	if err := ZeroClearAllVars(); err != nil {
+		t.Fatal(err)
+	}
+	if c.n != 1 {
+		t.Errorf("Got %d; want 1", c.n)
+	}
+}
+
+func TestZeroClearAllVarsOpts(t *testing.T) {
+	defer func() {
+		AllVars = make(map[string][]interface{})
+		varAccessed = make(map[string]bool)
+	}()
+	var reclaimWait sync.WaitGroup
+	defer setReclaimWait(setReclaimWait(&reclaimWait))
+	for _, opts := range []ClearOptions{
+		{},
+		{ForceGC: true},
+		{FreeOSMemory: true, Background: true},
+		{ForceGC: true, Background: true},
+	} {
+		b := make([]byte, 1<<20)
+		LgoRegisterVar("b", &b)
+		var before runtime.MemStats
+		runtime.ReadMemStats(&before)
+		if err := ZeroClearAllVarsOpts(opts); err != nil {
+			t.Fatal(err)
+		}
+		if b != nil {
+			t.Errorf("b is not cleared with %+v", opts)
+		}
+		reclaimWait.Wait()
+		var after runtime.MemStats
+		runtime.ReadMemStats(&after)
+		gc := after.NumGC > before.NumGC
+		if want := opts.ForceGC || opts.FreeOSMemory; want && !gc {
+			t.Errorf("GC did not run with %+v", opts)
+		}
+	}
+}
+
+func benchmarkZeroClearAllVars(b *testing.B, opts ClearOptions) {
+	defer func() {
+		AllVars = make(map[string][]interface{})
+		varAccessed = make(map[string]bool)
+	}()
+	var reclaimWait sync.WaitGroup
+	defer setReclaimWait(setReclaimWait(&reclaimWait))
+	// Keep a large heap to make the garbage collection slow.
+	heap := make([][]byte, 1024)
+	for i := range heap {
+		heap[i] = make([]byte, 64<<10)
+	}
+	var v []byte
+	LgoRegisterVar("v", &v)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := ZeroClearAllVarsOpts(opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	reclaimWait.Wait()
+	runtime.KeepAlive(heap)
+}
+
+func BenchmarkZeroClearAllVarsSync(b *testing.B) {
+	benchmarkZeroClearAllVars(b, DefaultClearOptions())
+}
+
+func BenchmarkZeroClearAllVarsBackground(b *testing.B) {
+	opts := DefaultClearOptions()
+	opts.Background = true
+	benchmarkZeroClearAllVars(b, opts)
+}
+
+func BenchmarkZeroClearAllVarsNoGC(b *testing.B) {
+	benchmarkZeroClearAllVars(b, ClearOptions{})
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"bytes"
+	"go/scanner"
+	"go/token"
+	"html"
+	"strings"
+)
+
+// codeTokenStyles is the CSS styles of Go tokens in DisplayCode.
+var codeTokenStyles = map[string]string{
+	"keyword": "color:#00f;font-weight:bold",
+	"string":  "color:#a31515",
+	"number":  "color:#098658",
+	"comment": "color:#008000;font-style:italic",
+}
+
+// codeTokenClass returns the key of codeTokenStyles of tok. It returns "" if tok is not highlighted.
+func codeTokenClass(tok token.Token) string {
+	switch {
+	case tok.IsKeyword():
+		return "keyword"
+	case tok == token.STRING || tok == token.CHAR:
+		return "string"
+	case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+		return "number"
+	case tok == token.COMMENT:
+		return "comment"
+	}
+	return ""
+}
+
+// highlightGo writes code to buf as HTML in which Go tokens are highlighted.
+// Invalid code is highlighted as far as it is tokenized.
+func highlightGo(buf *bytes.Buffer, code string) {
+	src := []byte(code)
+	fset := token.NewFileSet()
+	var s scanner.Scanner
+	// Ignore errors in incomplete code.
+	s.Init(fset.AddFile("", fset.Base(), len(src)), src, func(token.Position, string) {}, scanner.ScanComments)
+	last := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			// An automatically inserted semicolon.
+			continue
+		}
+		text := lit
+		if text == "" {
+			text = tok.String()
+		}
+		offset := fset.Position(pos).Offset
+		if offset < last || offset+len(text) > len(src) {
+			continue
+		}
+		// The text of tokens with literals can differ from src (e.g. "\r" in raw strings are removed).
+		text = code[offset : offset+len(text)]
+		buf.WriteString(html.EscapeString(code[last:offset]))
+		if class := codeTokenClass(tok); class != "" {
+			buf.WriteString(`<span style="` + codeTokenStyles[class] + `">` + html.EscapeString(text) + "</span>")
+		} else {
+			buf.WriteString(html.EscapeString(text))
+		}
+		last = offset + len(text)
+	}
+	buf.WriteString(html.EscapeString(code[last:]))
+}
+
+// DisplayCode displays code written in lang as HTML. Go code (lang is "go") is highlighted by tokens.
+// Code in other languages is displayed as preformatted text.
+func DisplayCode(d DataDisplayer, code, lang string, id *string) {
+	var buf bytes.Buffer
+	buf.WriteString(`<pre class="lgo-code">`)
+	if strings.EqualFold(lang, "go") {
+		highlightGo(&buf, code)
+	} else {
+		buf.WriteString(html.EscapeString(code))
+	}
+	buf.WriteString("</pre>")
+	d.HTML(buf.String(), id)
+}
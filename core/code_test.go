@@ -0,0 +1,49 @@
+package core
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestDisplayCode(t *testing.T) {
+	code := "package main\n\n// Comment <x>\nfunc f() string {\n\treturn \"a<b\" + `c` + string(1.5)\n}\n"
+	var d fakeDisplayer
+	DisplayCode(&d, code, "go", nil)
+	DisplayCode(&d, "x <- y", "haskell", nil)
+	if len(d.contents) != 2 {
+		t.Fatalf("Unexpected outputs: %v", d.contents)
+	}
+	got := d.contents[0].content.(string)
+	for _, want := range []string{
+		`<span style="color:#00f;font-weight:bold">func</span> f() string {`,
+		`<span style="color:#008000;font-style:italic">// Comment &lt;x&gt;</span>`,
+		`<span style="color:#a31515">&#34;a&lt;b&#34;</span>`,
+		`<span style="color:#098658">1.5</span>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%q does not contain %q", got, want)
+		}
+	}
+	// The text without tags must be the original code.
+	text := regexp.MustCompile(`<[^>]*>`).ReplaceAllString(got, "")
+	if text = html.UnescapeString(text); text != code {
+		t.Errorf("Got %q; want %q", text, code)
+	}
+	if got, want := d.contents[1].content, `<pre class="lgo-code">x &lt;- y</pre>`; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+}
+
+func TestDisplayCodeInvalid(t *testing.T) {
+	for _, code := range []string{"x := \"unterminated\n", "/* comment", "a @ b\r\n`raw\r\n`", ""} {
+		var d fakeDisplayer
+		DisplayCode(&d, code, "Go", nil)
+		got := d.contents[0].content.(string)
+		text := html.UnescapeString(regexp.MustCompile(`<[^>]*>`).ReplaceAllString(got, ""))
+		if text != code {
+			t.Errorf("Got %q; want %q", text, code)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"fmt"
+	"html"
+	"reflect"
+	"sync"
+)
+
+// Color is a color of values in colorized outputs.
+type Color struct {
+	// ANSI is the SGR parameter of ANSI escape sequences for terminals and stream outputs (e.g. "34" for blue).
+	ANSI string
+	// CSS is the CSS color for HTML outputs (e.g. "#00c").
+	CSS string
+}
+
+// ColorScheme is colors of values by types. Values with empty colors are not colorized.
+type ColorScheme struct {
+	// Number is the color of integers, floating-point numbers and complex numbers.
+	Number Color
+	// String is the color of strings.
+	String Color
+	// Bool is the color of booleans.
+	Bool Color
+	// Nil is the color of nil values.
+	Nil Color
+}
+
+// DefaultColorScheme returns the default ColorScheme.
+func DefaultColorScheme() ColorScheme {
+	return ColorScheme{
+		Number: Color{ANSI: "34", CSS: "#00c"},
+		String: Color{ANSI: "32", CSS: "#080"},
+		Bool:   Color{ANSI: "35", CSS: "#a0a"},
+		Nil:    Color{ANSI: "90", CSS: "#888"},
+	}
+}
+
+// colorMu protects colorOutput and colorScheme.
+var colorMu sync.Mutex
+
+// colorOutput indicates values printed with the default printer are colorized.
+var colorOutput bool
+
+var colorScheme = DefaultColorScheme()
+
+// SetColorOutput sets whether values printed with LgoPrintln in lgo are colorized by types with ANSI escape sequences.
+// If SetUnifiedOutputOrdering(true) is called, prints are displayed as HTML colorized with ColorizeHTML instead.
+// It is false by default.
+func SetColorOutput(enabled bool) {
+	colorMu.Lock()
+	defer colorMu.Unlock()
+	colorOutput = enabled
+}
+
+// SetColorScheme sets the ColorScheme used by FormatValue, ColorizeANSI and ColorizeHTML.
+func SetColorScheme(s ColorScheme) {
+	colorMu.Lock()
+	defer colorMu.Unlock()
+	colorScheme = s
+}
+
+func getColorSettings() (bool, ColorScheme) {
+	colorMu.Lock()
+	defer colorMu.Unlock()
+	return colorOutput, colorScheme
+}
+
+// FormatValue formats v printed with LgoPrintln. v is colorized with ANSI escape sequences
+// if SetColorOutput(true) is called. Otherwise, it is formatted with fmt.Sprint.
+// The depth and the number of elements are limited if SetMaxPrintDepth or SetMaxPrintElements is called.
+// time.Duration is formatted with HumanDuration (e.g. "4.2s").
+func FormatValue(v interface{}) string {
+	if enabled, _ := getColorSettings(); enabled {
+		return ColorizeANSI(v)
+	}
+	return formatLimited(v)
+}
+
+// ColorizeANSI formats v like FormatValue and colorizes it with ANSI escape sequences by its type.
+func ColorizeANSI(v interface{}) string {
+	_, s := getColorSettings()
+	return colorize(s, v, formatLimited, func(c Color, text string) string {
+		if c.ANSI == "" {
+			return text
+		}
+		return "\x1b[" + c.ANSI + "m" + text + "\x1b[0m"
+	})
+}
+
+// ColorizeHTML formats v like FormatValue and returns HTML in which v is colorized with a styled span by its type.
+func ColorizeHTML(v interface{}) string {
+	_, s := getColorSettings()
+	escape := func(v interface{}) string { return html.EscapeString(formatLimited(v)) }
+	return colorize(s, v, escape, func(c Color, text string) string {
+		if c.CSS == "" {
+			return text
+		}
+		return fmt.Sprintf(`<span style="color:%s">%s</span>`, html.EscapeString(c.CSS), text)
+	})
+}
+
+// colorize formats v with format and colorizes it with paint by the type of v.
+// Values in tuples printed with LgoPrintlnMulti are colorized separately.
+func colorize(s ColorScheme, v interface{}, format func(v interface{}) string, paint func(c Color, text string) string) string {
+	if t, ok := v.(tuple); ok {
+		return t.format(func(v interface{}) string { return colorize(s, v, format, paint) })
+	}
+	return paint(valueColor(s, v), format(v))
+}
+
+// valueColor returns the color of v in s.
+func valueColor(s ColorScheme, v interface{}) Color {
+	if v == nil {
+		return s.Nil
+	}
+	if _, ok := v.(fmt.Stringer); ok {
+		// The output is not related to the kind of v.
+		return Color{}
+	}
+	if _, ok := v.(error); ok {
+		return Color{}
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return s.Number
+	case reflect.String:
+		return s.String
+	case reflect.Bool:
+		return s.Bool
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		if rv.IsNil() {
+			return s.Nil
+		}
+	}
+	return Color{}
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestColorizeANSI(t *testing.T) {
+	var nilMap map[string]int
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{10, "\x1b[34m10\x1b[0m"},
+		{2.5, "\x1b[34m2.5\x1b[0m"},
+		{"a", "\x1b[32ma\x1b[0m"},
+		{true, "\x1b[35mtrue\x1b[0m"},
+		{nil, "\x1b[90m<nil>\x1b[0m"},
+		{nilMap, "\x1b[90mmap[]\x1b[0m"},
+		{[]int{1}, "[1]"},
+		{errors.New("err"), "err"},
+		{tuple{1, "a"}, "(\x1b[34m1\x1b[0m, \x1b[32ma\x1b[0m)"},
+	}
+	for _, tc := range tests {
+		if got := ColorizeANSI(tc.v); got != tc.want {
+			t.Errorf("Got %q; want %q", got, tc.want)
+		}
+	}
+}
+
+func TestColorizeHTML(t *testing.T) {
+	if got, want := ColorizeHTML("<b>"), `<span style="color:#080">&lt;b&gt;</span>`; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	if got, want := ColorizeHTML(struct{ X int }{1}), "{1}"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	if got, want := FormatValue(10), "10"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	if got, want := FormatValue(4213*time.Millisecond), "4.2s"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	SetColorOutput(true)
+	defer SetColorOutput(false)
+	SetColorScheme(ColorScheme{Number: Color{ANSI: "1"}})
+	defer SetColorScheme(DefaultColorScheme())
+	if got, want := FormatValue(10), "\x1b[1m10\x1b[0m"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	if got, want := FormatValue("a"), "a"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+}
+
+func TestColorOutputUnifiedOrdering(t *testing.T) {
+	SetColorOutput(true)
+	defer SetColorOutput(false)
+	SetUnifiedOutputOrdering(true)
+	defer SetUnifiedOutputOrdering(false)
+	atomic.StoreUint32(&isRunning, 0)
+	var d fakeDisplayer
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Display: &d}, func() {
+		LgoPrintln(10, "<a>")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.contents) != 1 {
+		t.Fatalf("Got %d contents; want 1", len(d.contents))
+	}
+	want := `<pre><span style="color:#00c">10</span> <span style="color:#080">&lt;a&gt;</span></pre>`
+	if c := d.contents[0]; c.contentType != "text/html" || c.content != want {
+		t.Errorf("Got %v; want %q", c, want)
+	}
+}
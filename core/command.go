@@ -0,0 +1,159 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"unicode/utf8"
+)
+
+// waitCommand waits cmd which is started. It kills the process group of cmd when ctx is done
+// unless cmd is already waited.
+// It returns the error of ctx if cmd fails after it is killed.
+func waitCommand(ctx context.Context, cmd *exec.Cmd) error {
+	var mu sync.Mutex
+	waited := false
+	done := make(chan struct{})
+	killed := make(chan bool, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+			mu.Lock()
+			defer mu.Unlock()
+			if waited {
+				killed <- false
+				return
+			}
+			killProcessGroup(cmd.Process)
+			killed <- true
+		case <-done:
+			killed <- false
+		}
+	}()
+	err := cmd.Wait()
+	mu.Lock()
+	waited = true
+	mu.Unlock()
+	close(done)
+	if <-killed && err != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// RunCommand runs the command with the arguments and returns its combined stdout and stderr.
+// The command and its child processes are killed when the current execution is canceled.
+// If the execution is canceled, it returns the error of the execution context.
+func RunCommand(name string, args ...string) ([]byte, error) {
+	ctx := GetExecContext()
+	cmd := exec.Command(name, args...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	err := waitCommand(ctx, cmd)
+	return out.Bytes(), err
+}
+
+// commandStreamLimit is the max bytes of outputs of commands displayed by RunCommandStream.
+var commandStreamLimit = 1 << 20
+
+// commandOmitted is prepended to outputs of commands whose head is dropped because of commandStreamLimit.
+const commandOmitted = "[earlier output is omitted]\n"
+
+// commandStream displays the tail of the output of a command.
+type commandStream struct {
+	d  DataDisplayer
+	th throttler
+	// id is the display ID of the output. It is accessed only in render.
+	id string
+
+	mu        sync.Mutex
+	out       []byte
+	truncated bool
+}
+
+func newCommandStream(d DataDisplayer) *commandStream {
+	s := &commandStream{d: d}
+	s.th.render = s.render
+	s.th.interval = defaultMetricsInterval
+	return s
+}
+
+func (s *commandStream) write(p []byte) {
+	s.mu.Lock()
+	s.out = append(s.out, p...)
+	if drop := len(s.out) - commandStreamLimit; drop > 0 {
+		// Don't split a UTF-8 sequence.
+		for drop < len(s.out) && !utf8.RuneStart(s.out[drop]) {
+			drop++
+		}
+		s.out = append(s.out[:0], s.out[drop:]...)
+		s.truncated = true
+	}
+	s.mu.Unlock()
+	s.th.update()
+}
+
+func (s *commandStream) render() {
+	s.mu.Lock()
+	text := string(s.out)
+	if s.truncated {
+		text = commandOmitted + text
+	}
+	s.mu.Unlock()
+	s.d.Text(text, &s.id)
+}
+
+// RunCommandStream runs the command with the arguments and displays its combined stdout and stderr as a text with d.
+// The text is updated while the command writes outputs. Updates are throttled and only the last 1MB of the outputs
+// is displayed.
+// The command and its child processes are killed when the current execution is canceled.
+func RunCommandStream(d DataDisplayer, name string, args ...string) error {
+	// The command is killed only by waitCommand so that it is not killed after it is waited.
+	ctx, cancel := context.WithCancel(GetExecContext())
+	defer cancel()
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = w
+	cmd.Stderr = w
+	setProcessGroup(cmd)
+	err = cmd.Start()
+	// Close the writer in this process so that the reader gets EOF when the command exits.
+	w.Close()
+	if err != nil {
+		return err
+	}
+	waitDone := make(chan error, 1)
+	go func() {
+		waitDone <- waitCommand(ctx, cmd)
+	}()
+	out := newCommandStream(d)
+	defer out.th.flush()
+	var buf [4096]byte
+	for {
+		n, rerr := r.Read(buf[:])
+		if n > 0 {
+			out.write(buf[:n])
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			cancel()
+			<-waitDone
+			return rerr
+		}
+	}
+	return <-waitDone
+}
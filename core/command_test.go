@@ -0,0 +1,129 @@
+package core
+
+import (
+	"context"
+	"os/exec"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunCommand(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var out []byte
+	var err error
+	if execErr := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		out, err = RunCommand("sh", "-c", "echo hello; echo world 1>&2")
+	}); execErr != nil {
+		t.Fatal(execErr)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "hello\nworld\n" {
+		t.Errorf("Got %q; want %q", out, "hello\nworld\n")
+	}
+}
+
+func TestRunCommandCancel(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	var err error
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+	start := time.Now()
+	if execErr := ExecLgoEntryPoint(LgoContext{Context: ctx}, func() {
+		// The child process of sh keeps the output open unless the process group is killed.
+		_, err = RunCommand("sh", "-c", "sleep 30 & sleep 30")
+	}); execErr != nil {
+		t.Fatal(execErr)
+	}
+	if err != context.Canceled {
+		t.Errorf("Got %v; want %v", err, context.Canceled)
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Errorf("RunCommand took too long: %v", d)
+	}
+}
+
+func TestRunCommandStream(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var d fakeDisplayer
+	var err error
+	if execErr := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		err = RunCommandStream(&d, "sh", "-c", "echo a; sleep 0.1; echo b 1>&2")
+	}); execErr != nil {
+		t.Fatal(execErr)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := d.contents[len(d.contents)-1]
+	if last.content != "a\nb\n" {
+		t.Errorf("Got %q; want %q", last.content, "a\nb\n")
+	}
+	for _, c := range d.contents {
+		if c.id != "id1" {
+			t.Errorf("Got %q; want \"id1\"", c.id)
+		}
+	}
+}
+
+func TestRunCommandStreamThrottle(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var d fakeDisplayer
+	var err error
+	if execErr := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		err = RunCommandStream(&d, "sh", "-c", "for i in 1 2 3 4 5 6 7 8 9 10; do echo $i; sleep 0.001; done")
+	}); execErr != nil {
+		t.Fatal(execErr)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.contents) >= 10 {
+		t.Errorf("Outputs are not throttled: %d", len(d.contents))
+	}
+	want := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	if last := d.contents[len(d.contents)-1]; last.content != want {
+		t.Errorf("Got %q; want %q", last.content, want)
+	}
+}
+
+func TestRunCommandStreamLimit(t *testing.T) {
+	defer func(n int) { commandStreamLimit = n }(commandStreamLimit)
+	commandStreamLimit = 3
+	atomic.StoreUint32(&isRunning, 0)
+	var d fakeDisplayer
+	var err error
+	if execErr := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		err = RunCommandStream(&d, "printf", "abcdéf\n")
+	}); execErr != nil {
+		t.Fatal(execErr)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The second byte of "é" is dropped with its first byte.
+	want := commandOmitted + "f\n"
+	if last := d.contents[len(d.contents)-1]; last.content != want {
+		t.Errorf("Got %q; want %q", last.content, want)
+	}
+}
+
+func TestWaitCommandFinished(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := exec.Command("true")
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	// The command finishes before the context is canceled.
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	if err := waitCommand(ctx, cmd); err != nil {
+		t.Errorf("Got %v; want nil", err)
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+// +build !windows
+
+package core
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup lets cmd run in a new process group so that its child processes can be killed together.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup kills the process group of p started with setProcessGroup.
+// It does nothing if p is already waited because the ID of the process group may be reused.
+func killProcessGroup(p *os.Process) {
+	// os.Process.Signal fails with os.ErrProcessDone after p is waited.
+	if err := p.Signal(syscall.Signal(0)); err != nil {
+		return
+	}
+	// The negative pid means the process group.
+	syscall.Kill(-p.Pid, syscall.SIGKILL)
+}
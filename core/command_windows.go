@@ -0,0 +1,16 @@
+//go:build windows
+// +build windows
+
+package core
+
+import (
+	"os"
+	"os/exec"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills only p because process groups are not supported on Windows.
+func killProcessGroup(p *os.Process) {
+	p.Kill()
+}
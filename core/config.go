@@ -0,0 +1,93 @@
+package core
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Config is a snapshot of the package-level settings of lgo configured with SetX functions.
+// Handlers (e.g. SetPanicDisplayer, SetWarningHandler and SetDisplayTransform) and writers
+// (e.g. SetDefaultPrinterWriter) are not included.
+type Config struct {
+	HangReportDelay          time.Duration
+	GoroutineLimit           int
+	LingeringPolicy          OnLingering
+	AggregateGoroutinePanics PanicAggregation
+	SuppressStderrPanics     bool
+	UnifiedOutputOrdering    bool
+	ProfileExecution         bool
+	AllocTracking            bool
+	ExecSeed                 int64
+	RestartMax               int
+	RestartBackoff           time.Duration
+	JavaScriptEnabled        bool
+	TranscriptEnabled        bool
+	TranscriptLimit          int
+	ColorOutput              bool
+	ColorScheme              ColorScheme
+	MaxPrintDepth            int
+	MaxPrintElements         int
+	ExecTimeout              time.Duration
+	RedefineMode             RedefineMode
+	DefaultPrinterDisabled   bool
+}
+
+// SaveConfig returns the current settings of lgo so that they can be restored with RestoreConfig
+// (e.g. to isolate test cases or to switch configurations per notebook).
+func SaveConfig() Config {
+	c := Config{
+		HangReportDelay:          time.Duration(atomic.LoadInt64(&hangReportDelay)),
+		GoroutineLimit:           int(atomic.LoadInt64(&goroutineLimit)),
+		LingeringPolicy:          getLingeringPolicy(),
+		AggregateGoroutinePanics: getAggregateGoroutinePanics(),
+		SuppressStderrPanics:     isStderrPanicSuppressed(),
+		UnifiedOutputOrdering:    isUnifiedOutputOrdering(),
+		ExecSeed:                 atomic.LoadInt64(&execSeed),
+		JavaScriptEnabled:        isJavaScriptEnabled(),
+		ExecTimeout:              time.Duration(atomic.LoadInt64(&execTimeout)),
+		RedefineMode:             getRedefineMode(),
+	}
+	profileMu.Lock()
+	c.ProfileExecution = profileExecution
+	profileMu.Unlock()
+	allocMu.Lock()
+	c.AllocTracking = allocTracking
+	allocMu.Unlock()
+	c.RestartMax, c.RestartBackoff = getRestartPolicy()
+	transcriptMu.Lock()
+	c.TranscriptEnabled, c.TranscriptLimit = transcriptEnabled, transcriptLimit
+	transcriptMu.Unlock()
+	c.ColorOutput, c.ColorScheme = getColorSettings()
+	c.MaxPrintDepth, c.MaxPrintElements = getPrintLimits()
+	defaultPrinterMu.Lock()
+	c.DefaultPrinterDisabled = defaultPrinterDisabled
+	defaultPrinterMu.Unlock()
+	return c
+}
+
+// RestoreConfig applies the settings in c saved with SaveConfig.
+func RestoreConfig(c Config) {
+	SetHangReportDelay(c.HangReportDelay)
+	SetGoroutineExplosionGuard(c.GoroutineLimit)
+	SetLingeringPolicy(c.LingeringPolicy)
+	SetAggregateGoroutinePanics(c.AggregateGoroutinePanics)
+	SetSuppressStderrPanics(c.SuppressStderrPanics)
+	SetUnifiedOutputOrdering(c.UnifiedOutputOrdering)
+	SetProfileExecution(c.ProfileExecution)
+	SetAllocTracking(c.AllocTracking)
+	SetExecSeed(c.ExecSeed)
+	SetRestartPolicy(c.RestartMax, c.RestartBackoff)
+	SetJavaScriptEnabled(c.JavaScriptEnabled)
+	SetTranscriptEnabled(c.TranscriptEnabled)
+	SetTranscriptLimit(c.TranscriptLimit)
+	SetColorOutput(c.ColorOutput)
+	SetColorScheme(c.ColorScheme)
+	SetMaxPrintDepth(c.MaxPrintDepth)
+	SetMaxPrintElements(c.MaxPrintElements)
+	SetExecTimeout(c.ExecTimeout)
+	SetRedefineMode(c.RedefineMode)
+	// Don't use SetDefaultPrinterWriter, which resets the writer.
+	defaultPrinterMu.Lock()
+	defaultPrinterDisabled = c.DefaultPrinterDisabled
+	defaultPrinterMu.Unlock()
+}
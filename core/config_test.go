@@ -0,0 +1,67 @@
+package core
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSaveRestoreConfig(t *testing.T) {
+	orig := SaveConfig()
+	defer RestoreConfig(orig)
+	c := Config{
+		HangReportDelay:          time.Second,
+		GoroutineLimit:           100,
+		LingeringPolicy:          LingeringDetach,
+		AggregateGoroutinePanics: PanicReportAll,
+		SuppressStderrPanics:     true,
+		UnifiedOutputOrdering:    true,
+		ProfileExecution:         true,
+		AllocTracking:            true,
+		ExecSeed:                 123,
+		RestartMax:               3,
+		RestartBackoff:           time.Millisecond,
+		JavaScriptEnabled:        true,
+		TranscriptEnabled:        true,
+		TranscriptLimit:          10,
+		ColorOutput:              true,
+		ColorScheme:              ColorScheme{Number: Color{ANSI: "1", CSS: "red"}},
+		MaxPrintDepth:            5,
+		MaxPrintElements:         20,
+		ExecTimeout:              time.Minute,
+		RedefineMode:             RedefineStrictType,
+		DefaultPrinterDisabled:   true,
+	}
+	// Every field must be set to a non-zero value to check all settings are restored.
+	v := reflect.ValueOf(c)
+	for i := 0; i < v.NumField(); i++ {
+		if reflect.DeepEqual(v.Field(i).Interface(), reflect.Zero(v.Field(i).Type()).Interface()) {
+			t.Fatalf("%s is not set", v.Type().Field(i).Name)
+		}
+	}
+	RestoreConfig(c)
+	if got := SaveConfig(); !reflect.DeepEqual(got, c) {
+		t.Errorf("Got %+v; want %+v", got, c)
+	}
+	RestoreConfig(orig)
+	if got := SaveConfig(); !reflect.DeepEqual(got, orig) {
+		t.Errorf("Got %+v; want %+v", got, orig)
+	}
+	if orig.ExecSeed != 1 || orig.TranscriptLimit != defaultTranscriptLimit || !reflect.DeepEqual(orig.ColorScheme, DefaultColorScheme()) {
+		t.Errorf("Unexpected default config: %+v", orig)
+	}
+}
+
+func TestRestoreConfigKeepsDefaultPrinterWriter(t *testing.T) {
+	defer SetDefaultPrinterWriter(nil)
+	var buf bytes.Buffer
+	SetDefaultPrinterWriter(&buf)
+	c := SaveConfig()
+	DisableDefaultPrinter()
+	RestoreConfig(c)
+	printDefault([]interface{}{1})
+	if got, want := buf.String(), "1\n"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+}
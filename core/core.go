@@ -1,13 +1,16 @@
 package core
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
+	"math/rand"
 	"reflect"
 	"runtime"
 	"runtime/debug"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -18,7 +21,29 @@ import (
 const SelfPkgPath = "github.com/yunabe/lgo/core"
 
 // How long time we should wait for goroutines after a cancel operation.
-var execWaitDuration = time.Second
+// To access this var, use getExecWaitDuration and setExecWaitDuration.
+var execWaitDuration = int64(time.Second)
+
+func getExecWaitDuration() time.Duration {
+	return time.Duration(atomic.LoadInt64(&execWaitDuration))
+}
+
+// setExecWaitDuration sets execWaitDuration to d and returns the previous value.
+func setExecWaitDuration(d time.Duration) time.Duration {
+	return time.Duration(atomic.SwapInt64(&execWaitDuration, int64(d)))
+}
+
+// hangReportDelay is the duration set by SetHangReportDelay.
+// To access this var, use atomic.Store/LoadInt64.
+var hangReportDelay int64
+
+// SetHangReportDelay sets how long routines can be active after the cancellation of an execution
+// without being reported as hanging. When an execution is canceled, lgo waits for its routines
+// for d or the default wait (1s), whichever is longer, and reports the routines still active as hanging.
+// The default is 0, which uses the default wait.
+func SetHangReportDelay(d time.Duration) {
+	atomic.StoreInt64(&hangReportDelay, int64(d))
+}
 
 // isRunning indicates lgo execution is running.
 // This var is used to improve the performance of ExitIfCtxDone.
@@ -31,11 +56,16 @@ type LgoContext struct {
 	context.Context
 	// Display displays non-text content in Jupyter Notebook.
 	Display DataDisplayer
+	// Input requests inputs from users. nil if the front-end does not support inputs. See Input.
+	Input InputRequester
+	// Progress reports the progress of executions to the front-end. nil if the front-end does not
+	// support it. See SetProgress.
+	Progress ProgressReporter
 }
 
 func lgoCtxWithCancel(ctx LgoContext) (LgoContext, context.CancelFunc) {
 	goctx, cancel := context.WithCancel(ctx.Context)
-	return LgoContext{goctx, ctx.Display}, cancel
+	return LgoContext{goctx, ctx.Display, ctx.Input, ctx.Progress}, cancel
 }
 
 // DataDisplayer is the interface that wraps Jupyter Notebook display_data protocol.
@@ -45,11 +75,24 @@ func lgoCtxWithCancel(ctx LgoContext) (LgoContext, context.CancelFunc) {
 // If id is not nil and it points a non-empty string, the method overwrites a content with the same ID in Jupyter Notebooks.
 //
 // Please note that JavaScript output is disabled in JupyterLab[3].
+// Display helpers in this package which rely on JavaScript (e.g. DisplayInteractiveTable) fall back to
+// static outputs unless SetJavaScriptEnabled(true) is called.
+//
+// Widget displays a view of the ipywidgets model whose ID is modelID[4]. It returns an error if modelID is empty.
+//
+// SupportedMIMETypes returns the MIME types which the front-end can render so that libraries can pick
+// the best representation. It may be approximate because kernels do not always know the front-end.
+// DataDisplayers which do not know the front-end return DefaultSupportedMIMETypes().
+//
+// UpdateDisplay updates the output whose display ID is id with bundle, which maps MIME types to contents,
+// by sending update_display_data[5] explicitly. It returns an error if id is empty.
 //
 // References:
 // [1] http://jupyter-client.readthedocs.io/en/latest/messaging.html#display-data
 // [2] https://github.com/jupyter/notebook/blob/master/notebook/static/notebook/js/outputarea.js
 // [3] https://github.com/jupyterlab/jupyterlab/issues/3748
+// [4] https://github.com/jupyter-widgets/ipywidgets/blob/master/packages/schema/messages.md
+// [5] http://jupyter-client.readthedocs.io/en/latest/messaging.html#update-display-data
 type DataDisplayer interface {
 	JavaScript(s string, id *string)
 	HTML(s string, id *string)
@@ -62,23 +105,101 @@ type DataDisplayer interface {
 	PDF(b []byte, id *string)
 	Text(s string, id *string)
 	Raw(contentType string, v interface{}, id *string) error
+	Widget(modelID string, id *string) error
+	UpdateDisplay(id string, bundle map[string]interface{}) error
+	SupportedMIMETypes() []string
+}
+
+// DefaultSupportedMIMETypes returns the conservative set of MIME types rendered by Jupyter front-ends.
+// application/javascript is included only if SetJavaScriptEnabled(true) is called.
+func DefaultSupportedMIMETypes() []string {
+	types := []string{
+		"text/plain",
+		"text/html",
+		"text/markdown",
+		"text/latex",
+		"image/svg+xml",
+		"image/png",
+		"image/jpeg",
+		"image/gif",
+		"application/pdf",
+		WidgetViewMIMEType,
+	}
+	if isJavaScriptEnabled() {
+		types = append(types, "application/javascript")
+	}
+	return types
+}
+
+// CheckDisplayUpdate returns an error if the arguments of DataDisplayer.UpdateDisplay are invalid.
+func CheckDisplayUpdate(id string, bundle map[string]interface{}) error {
+	if id == "" {
+		return errors.New("display id is empty")
+	}
+	_, err := json.Marshal(bundle)
+	return err
+}
+
+// WidgetViewMIMEType is the MIME type to display a view of ipywidgets model.
+const WidgetViewMIMEType = "application/vnd.jupyter.widget-view+json"
+
+// WidgetView returns the content of WidgetViewMIMEType which refers the widget model with modelID.
+func WidgetView(modelID string) (map[string]interface{}, error) {
+	if modelID == "" {
+		return nil, errors.New("widget model ID is empty")
+	}
+	return map[string]interface{}{
+		"model_id":      modelID,
+		"version_major": 2,
+		"version_minor": 0,
+	}, nil
 }
 
 type resultCounter struct {
 	active uint
 	fail   uint
 	cancel uint
-	mu     sync.Mutex
+	// total is the number of routines started.
+	total uint
+	// depth is the nesting depth of the execution. See ExecDepth.
+	depth int
+	// panics keeps the panics of failed routines.
+	panics []recordedPanic
+	// restarts keeps the panics of routines restarted by SafeGo up to maxRecordedPanics.
+	restarts []recordedPanic
+	mu       sync.Mutex
+}
+
+// recordedPanic is a panic recovered in a routine.
+type recordedPanic struct {
+	msg   string
+	stack []byte
 }
 
 func (c *resultCounter) add() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.active++
+	c.total++
 }
 
 // recordResult records a result of a routine based on the value of recover().
 func (c *resultCounter) recordResult(r interface{}) {
+	var msg string
+	var stack []byte
+	if r != nil && r != Bailout {
+		msg, stack = formatPanic(r), debug.Stack()
+	}
+	if !c.countResult(r, msg, stack) {
+		return
+	}
+	// Don't hold c.mu while reporting the panic because the panic displayer may block.
+	reportPanic(msg, c.depth, stack)
+}
+
+// countResult counts a result of a routine and returns true if the routine panicked.
+// msg and stack are the formatted value and the stack trace of the panic.
+func (c *resultCounter) countResult(r interface{}, msg string, stack []byte) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.active--
@@ -86,14 +207,15 @@ func (c *resultCounter) recordResult(r interface{}) {
 		panic("active is negative")
 	}
 	if r == nil {
-		return
+		return false
 	}
 	if r == Bailout {
 		c.cancel++
-		return
+		return false
 	}
-	fmt.Fprintf(os.Stderr, "panic: %v\n\n%s", r, debug.Stack())
 	c.fail++
+	c.panics = append(c.panics, recordedPanic{msg, stack})
+	return true
 }
 
 func (c *resultCounter) recordResultInDefer() {
@@ -105,43 +227,193 @@ type ExecutionState struct {
 	Context   LgoContext
 	cancelCtx func()
 	canceled  bool
-	cancelMu  sync.Mutex
+	// cancelStart is the time when cancel was called first.
+	cancelStart time.Time
+	// routinesDone is the time when all routines finished. Zero if routines are running.
+	routinesDone time.Time
+	cancelMu     sync.Mutex
 
 	mainCounter resultCounter
 	subCounter  resultCounter
 	routineWait sync.WaitGroup
+
+	// profile is the buffer of the running CPU profile. nil if this execution is not profiled.
+	profile *bytes.Buffer
+	// allocStart is the memory statistics at the start of this execution. nil if allocations are not tracked.
+	allocStart *runtime.MemStats
+
+	// seed, randOnce and rand are the random source returned from ExecRand.
+	seed     int64
+	randOnce sync.Once
+	rand     *rand.Rand
+
+	// goroutines keeps goroutines started with Go keyed by IDs.
+	goMu       sync.Mutex
+	goSeq      int
+	goroutines map[int]*trackedGoroutine
+
+	// transcript records outputs of this execution. nil if transcripts are disabled.
+	transcript *transcript
+	// outputBytes is the number of bytes of outputs. To access this field, use atomic.AddInt64/LoadInt64.
+	outputBytes int64
+
+	// start is the time when this execution started.
+	start time.Time
+	// cancelReason describes why this execution is canceled. Empty if the execution is not canceled.
+	cancelReason string
+	// partial is the value passed to SetPartialResult. Protected by cancelMu.
+	partial interface{}
+	// parentDone is closed when the parent context is done.
+	parentDone <-chan struct{}
+
+	// batch buffers outputs between BeginBatch and EndBatch.
+	batch batch
+	// output serializes outputs if unified output ordering is enabled.
+	output serializer
+
+	// mainDone is closed when the main routine finishes.
+	mainDone chan struct{}
+	// mainGoroutine is the ID of the goroutine of the main routine. Use atomic.Load/StoreUint64.
+	mainGoroutine uint64
+	// detached indicates this execution finished with LingeringDetach. Protected by cancelMu.
+	detached bool
+
+	// outer is the execution which was running when this execution started. nil if this execution is not nested.
+	outer *ExecutionState
+	// depth is the nesting depth of this execution. See ExecDepth.
+	depth int
+
+	// cleanups, timers and tickers are torn down when this execution finishes. See RegisterCleanup.
+	cleanupMu    sync.Mutex
+	cleanups     []func()
+	timers       []*time.Timer
+	tickers      []*time.Ticker
+	teardownOnce sync.Once
+	// tearingDown indicates teardown started. Protected by cleanupMu.
+	tearingDown bool
+
+	// tempDir is the directory created by ExecTempDir. Empty until ExecTempDir is called.
+	tempMu  sync.Mutex
+	tempDir string
+
+	// afterFns are called with the report of this execution when it finishes. See AfterExecution.
+	afterMu  sync.Mutex
+	afterFns []func(report *ExecReport)
+
+	// goPos keeps the results of goroutines started with InitGoroutineAt keyed by positions.
+	goPosMu sync.Mutex
+	goPos   map[string]*posResult
+
+	// warnings keeps messages emitted with Warn.
+	warnMu   sync.Mutex
+	warnings []string
+	// recovered keeps panics recovered from callbacks run by lgo. See ExecReport.RecoveredPanics.
+	// Protected by warnMu.
+	recovered []string
+
+	// guardStop and guardDone control the sampler of SetGoroutineExplosionGuard. nil if the guard is disabled.
+	guardStop chan struct{}
+	guardDone chan struct{}
+
+	// tags are the tags of the code block. See ExecLgoEntryPointWithTags.
+	tags execTags
+	// timeoutTimer cancels this execution when the timeout is exceeded. nil if the timeout is disabled.
+	timeoutTimer *time.Timer
+
+	// cancelNotify are the channels registered with NotifyOnCancel. Protected by cancelMu.
+	cancelNotify []chan<- struct{}
+	// notifyDone is closed when this execution is finalized to stop pending notifications.
+	// notifyClosed indicates notifyDone is closed. Protected by cancelMu.
+	notifyDone   chan struct{}
+	notifyClosed bool
+
+	// progress is the latest fraction passed to SetProgress and progressTh throttles reports of it.
+	// progressTh is nil until SetProgress is called. Protected by progressMu.
+	progressMu sync.Mutex
+	progress   float64
+	progressTh *throttler
 }
 
 func newExecutionState(parent LgoContext) *ExecutionState {
 	ctx, cancel := lgoCtxWithCancel(parent)
 	e := &ExecutionState{
-		Context:   ctx,
-		cancelCtx: cancel,
+		Context:    ctx,
+		cancelCtx:  cancel,
+		seed:       atomic.LoadInt64(&execSeed),
+		start:      time.Now(),
+		parentDone: parent.Done(),
+		mainDone:   make(chan struct{}),
+		notifyDone: make(chan struct{}),
+		depth:      1,
+	}
+	// Executions left after cancellations are not outer executions.
+	if outer := getExecState(); outer != nil && outer.Context.Err() == nil {
+		e.outer = outer
+		e.depth = outer.depth + 1
 	}
+	e.mainCounter.depth = e.depth
+	e.subCounter.depth = e.depth
 	go func() {
 		<-parent.Done()
-		e.cancel()
+		e.cancelWithReason(interruptedReason)
 	}()
 	return e
 }
 
+// canceledReason is the cancel reason of executions canceled by lgo (e.g. CancelDetachedGoroutines).
+const canceledReason = "canceled"
+
 func (e *ExecutionState) cancel() {
+	e.cancelWithReason(canceledReason)
+}
+
+// cancelWithReason cancels e. reason is recorded to the report of e if e is not canceled yet.
+// An empty reason means e is not canceled actually but the context is released after all routines finished.
+func (e *ExecutionState) cancelWithReason(reason string) {
 	e.cancelMu.Lock()
 	if e.canceled {
 		e.cancelMu.Unlock()
 		return
 	}
 	e.canceled = true
+	e.cancelStart = time.Now()
+	select {
+	case <-e.parentDone:
+		// The cancellation of the parent is propagated to the context before the reason is recorded.
+		reason = interruptedReason
+	default:
+	}
+	e.cancelReason = reason
+	var notify []chan<- struct{}
+	if reason != "" {
+		notify, e.cancelNotify = e.cancelNotify, nil
+	}
 	e.cancelMu.Unlock()
+	for _, ch := range notify {
+		e.notifyCancel(ch)
+	}
 
 	if getExecState() == e {
 		atomic.StoreUint32(&isRunning, 0)
 	}
+	// Stop timers and call cleanups before the context is canceled.
+	e.teardown()
 	e.cancelCtx()
 }
 
+// cancelWait returns how long waitRoutines waits for routines after the cancellation. See SetHangReportDelay.
+func cancelWait() time.Duration {
+	wait := getExecWaitDuration()
+	if delay := time.Duration(atomic.LoadInt64(&hangReportDelay)); delay > wait {
+		return delay
+	}
+	return wait
+}
+
 func (e *ExecutionState) counterMessage() string {
 	var msgs []string
+	detached := e.isDetached()
+	failedAt, hangingAt := e.goroutinePositions()
 	func() {
 		e.mainCounter.mu.Lock()
 		defer e.mainCounter.mu.Unlock()
@@ -157,37 +429,70 @@ func (e *ExecutionState) counterMessage() string {
 		e.subCounter.mu.Lock()
 		defer e.subCounter.mu.Unlock()
 		if c := e.subCounter.fail; c > 1 {
-			msgs = append(msgs, fmt.Sprintf("%d goroutines failed", c))
+			msgs = append(msgs, fmt.Sprintf("%d goroutines failed%s", c, failedAt))
 		} else if c == 1 {
-			msgs = append(msgs, fmt.Sprintf("%d goroutine failed", c))
+			msgs = append(msgs, fmt.Sprintf("%d goroutine failed%s", c, failedAt))
 		}
 		if c := e.subCounter.cancel; c > 1 {
 			msgs = append(msgs, fmt.Sprintf("%d goroutines canceled", c))
 		} else if c == 1 {
 			msgs = append(msgs, fmt.Sprintf("%d goroutine canceled", c))
 		}
+		if detached {
+			return
+		}
 		if c := e.subCounter.active; c > 1 {
-			msgs = append(msgs, fmt.Sprintf("%d goroutines are hanging", c))
+			msgs = append(msgs, fmt.Sprintf("%d goroutines are hanging%s", c, hangingAt))
 		} else if c == 1 {
-			msgs = append(msgs, fmt.Sprintf("%d goroutine is hanging", c))
+			msgs = append(msgs, fmt.Sprintf("%d goroutine is hanging%s", c, hangingAt))
 		}
 	}()
 	return strings.Join(msgs, ", ")
 }
 
+// waitRoutines waits for the main routine and goroutines of e to finish.
+// Finishing the main routine does not cancel e.Context. With LingeringWait, goroutines which outlive
+// the main routine are waited for until they finish, or until cancelWait passes after e is canceled.
+// With LingeringCancel, e is canceled when the main routine finishes, and with LingeringDetach,
+// waitRoutines returns without waiting for the goroutines.
 func (e *ExecutionState) waitRoutines() {
 	ctx, done := context.WithCancel(context.Background())
 	go func() {
 		e.routineWait.Wait()
+		e.cancelMu.Lock()
+		e.routinesDone = time.Now()
+		e.cancelMu.Unlock()
 		done()
+		removeDetached(e)
 		// Don't forget to cancel the current ctx to avoid ctx leak.
-		e.cancel()
+		e.cancelWithReason("")
 	}()
 	go func() {
 		<-e.Context.Done()
-		time.Sleep(execWaitDuration)
+		time.Sleep(cancelWait())
 		done()
 	}()
+	if policy := e.lingeringPolicy(); policy != LingeringWait {
+		go func() {
+			select {
+			case <-e.mainDone:
+			case <-ctx.Done():
+				return
+			}
+			if !e.hasActiveGoroutines() {
+				// routineWait will be done soon.
+				return
+			}
+			// detach rechecks the goroutines because they may finish here.
+			switch policy {
+			case LingeringCancel:
+				e.cancelWithReason("main routine finished")
+			case LingeringDetach:
+				e.detach()
+				done()
+			}
+		}()
+	}
 	// Wait done is called.
 	<-ctx.Done()
 }
@@ -205,15 +510,20 @@ var canceledCtx LgoContext
 func init() {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
-	canceledCtx = LgoContext{Context: ctx}
+	canceledCtx = LgoContext{Context: ctx, Display: nopDisplayer{}}
 }
 
 // GetExecContext returns the context of the current code execution.
 // It returns a canceled context when lgo does not execute any code blocks.
+// Display of the returned context is never nil. It discards outputs if the execution does not have a DataDisplayer.
 // _ctx in lgo is converted to this function internally.
 func GetExecContext() LgoContext {
 	if e := getExecState(); e != nil {
-		return e.Context
+		ctx := e.Context
+		if ctx.Display == nil {
+			ctx.Display = nopDisplayer{}
+		}
+		return ctx
 	}
 	return canceledCtx
 }
@@ -230,41 +540,94 @@ func setExecState(e *ExecutionState) {
 	execState = e
 }
 
+// resetExecState resets execState if it is e. The outer execution of e is restored if e is nested.
 func resetExecState(e *ExecutionState) {
 	execStateMu.Lock()
 	defer execStateMu.Unlock()
-	if execState == e {
-		execState = nil
+	if execState != e {
+		return
+	}
+	execState = e.outer
+	if e.outer != nil && e.outer.Context.Err() == nil {
+		atomic.StoreUint32(&isRunning, 1)
 	}
 }
 
+// ExecDepth returns the nesting depth of the current code execution.
+// It returns 1 in a code block executed by lgo, 2 in a code block executed with ExecLgoEntryPoint
+// from a code block and so on. It returns 0 when lgo does not execute any code blocks.
+func ExecDepth() int {
+	if e := getExecState(); e != nil {
+		return e.depth
+	}
+	return 0
+}
+
 // ExecLgoEntryPoint executes main under a new code execution context which is derived from parent.
 func ExecLgoEntryPoint(parent LgoContext, main func()) error {
 	return finalizeExec(startExec(parent, main))
 }
 
 func startExec(parent LgoContext, main func()) *ExecutionState {
+	return startExecWithTags(parent, main, execTags{})
+}
+
+func startExecWithTags(parent LgoContext, main func(), tags execTags) *ExecutionState {
 	atomic.StoreUint32(&isRunning, 1)
 	e := newExecutionState(parent)
+	e.tags = tags
+	e.startOutputRecording()
+	e.startDisplayTransform()
 	setExecState(e)
+	e.startProfile()
+	e.startAllocTracking()
+	e.startGoroutineGuard()
+	e.startTimeout()
 
 	e.routineWait.Add(1)
 	e.mainCounter.add()
 	go func() {
 		defer e.routineWait.Done()
+		defer close(e.mainDone)
 		defer e.mainCounter.recordResultInDefer()
+		atomic.StoreUint64(&e.mainGoroutine, goroutineID())
+		if isProcessPanicGuardInstalled() {
+			debug.SetPanicOnFault(true)
+		}
 		main()
 	}()
 	return e
 }
 
 func finalizeExec(e *ExecutionState) error {
+	_, err := finalizeExecReport(e)
+	return err
+}
+
+func finalizeExecReport(e *ExecutionState) (*ExecReport, error) {
 	e.waitRoutines()
+	e.stopGoroutineGuard()
+	e.stopTimeout()
+	e.stopCancelNotify()
+	e.stopProgress()
+	if !e.isDetached() {
+		// Wait for the teardown started by the cancellation.
+		e.teardown()
+	}
+	// Display outputs left in a batch if EndBatch is not called.
+	e.endBatch(true)
+	e.finishOutputRecording()
+	e.stopProfile()
+	e.stopAllocTracking()
+	e.storePartialResult()
 	resetExecState(e)
-	if msg := e.counterMessage(); msg != "" {
-		return errors.New(msg)
+	r := e.report()
+	atomic.StoreInt64(&lastCancelLatency, int64(r.CancelLatency))
+	e.runAfterExecution(r)
+	if r.Summary != "" {
+		return r, errors.New(r.Summary + e.goroutinePanicDetails())
 	}
-	return nil
+	return r, nil
 }
 
 // InitGoroutine is called internally before lgo starts a new goroutine
@@ -274,21 +637,32 @@ func InitGoroutine() *ExecutionState {
 	if e == nil {
 		return nil
 	}
+	e.initGoroutine(goroutineLabel{})
+	return e
+}
+
+// initGoroutine counts a goroutine started in e. l labels the goroutine in GoroutineEvents.
+func (e *ExecutionState) initGoroutine(l goroutineLabel) {
 	e.routineWait.Add(1)
 	e.subCounter.add()
-	return e
+	notifyGoroutine(GoroutineSpawned, l)
 }
 
 // FinalizeGoroutine is called when a goroutine invoked in lgo quits.
 func FinalizeGoroutine(e *ExecutionState) {
-	r := recover()
+	e.finalizeGoroutine(recover(), goroutineLabel{})
+}
+
+// finalizeGoroutine records the result of a goroutine labeled l. r is the value of recover().
+func (e *ExecutionState) finalizeGoroutine(r interface{}, l goroutineLabel) {
 	e.subCounter.recordResult(r)
+	// Notify the observer before the execution can finish.
+	notifyGoroutine(finishKind(r), l)
 	e.routineWait.Done()
 	if r != nil {
 		// paniced, cancel other routines.
-		e.cancel()
+		e.cancelWithReason("goroutine panicked")
 	}
-	return
 }
 
 // LgoPrinter is the interface that prints the result of the last lgo expression.
@@ -298,6 +672,9 @@ type LgoPrinter interface {
 
 var lgoPrinters = make(map[LgoPrinter]bool)
 
+// lgoPrintersMu protects lgoPrinters.
+var lgoPrintersMu sync.Mutex
+
 // Bailout is thrown to cancel lgo code execution internally.
 // Bailout is exported to be used from converted code (See converter/autoexit.go).
 var Bailout = errors.New("canceled")
@@ -310,6 +687,11 @@ func ExitIfCtxDone() {
 		// If running, do nothing.
 		return
 	}
+	exitIfCtxDoneSlow()
+}
+
+// exitIfCtxDoneSlow throws Bailout if the context of the current code execution is done.
+func exitIfCtxDoneSlow() {
 	// Slow operation
 	select {
 	case <-GetExecContext().Done():
@@ -318,46 +700,277 @@ func ExitIfCtxDone() {
 	}
 }
 
+// ExecDone returns the channel which is closed when the current code execution is canceled
+// so that user code can wait for cancellation in select statements (e.g. to return from a blocking API).
+// It returns a closed channel if lgo does not execute any code blocks.
+//
+// While ExitIfCtxDone exits the execution by throwing Bailout, receiving from ExecDone does not exit
+// the execution by itself. Return from your code or call ExitIfCtxDone after the channel is closed.
+func ExecDone() <-chan struct{} {
+	return GetExecContext().Done()
+}
+
 // RegisterLgoPrinter registers a LgoPrinter to print the result of the last lgo expression.
 func RegisterLgoPrinter(p LgoPrinter) {
+	lgoPrintersMu.Lock()
+	defer lgoPrintersMu.Unlock()
 	lgoPrinters[p] = true
 }
 
 // UnregisterLgoPrinter removes a registered LgoPrinter.
 func UnregisterLgoPrinter(p LgoPrinter) {
+	lgoPrintersMu.Lock()
+	defer lgoPrintersMu.Unlock()
 	delete(lgoPrinters, p)
 }
 
 // LgoPrintln prints args with registered LgoPrinters.
+// If no printers are registered, args are printed with the default printer (See SetDefaultPrinterWriter).
+// Nothing is printed in executions tagged with TagQuiet.
+// Printers can call LgoPrintln, RegisterLgoPrinter and UnregisterLgoPrinter in Println.
+// Printers registered while LgoPrintln is printing args do not print args.
 func LgoPrintln(args ...interface{}) {
+	if isQuiet() {
+		return
+	}
+	if e := getExecState(); e != nil && isUnifiedOutputOrdering() {
+		if d := e.Context.Display; d != nil {
+			// The displayer records and orders the print.
+			if enabled, _ := getColorSettings(); enabled {
+				d.HTML(formatPrintHTML(args), nil)
+			} else {
+				d.Text(formatPrint(args), nil)
+			}
+			return
+		}
+		e.do(func() {
+			e.recordPrint(args)
+			printLgoPrinters(args)
+		})
+		return
+	}
+	recordPrint(args)
+	printLgoPrinters(args)
+}
+
+// LgoPrintlnMulti prints vals as a tuple (e.g. "(10, 2.1)") with registered LgoPrinters.
+// This is used to print multiple return values of the last expression of a code block.
+func LgoPrintlnMulti(vals ...interface{}) {
+	LgoPrintln(tuple(vals))
+}
+
+// tuple is multiple values printed with LgoPrintlnMulti.
+type tuple []interface{}
+
+func (t tuple) String() string {
+	return t.format(func(v interface{}) string { return fmt.Sprint(v) })
+}
+
+// format formats t with f which formats each value.
+func (t tuple) format(f func(v interface{}) string) string {
+	strs := make([]string, len(t))
+	for i, v := range t {
+		strs[i] = f(v)
+	}
+	return "(" + strings.Join(strs, ", ") + ")"
+}
+
+// formatPrint formats args printed with LgoPrintln to a line without a newline.
+func formatPrint(args []interface{}) string {
+	strs := make([]string, len(args))
+	for i, arg := range args {
+		strs[i] = FormatValue(arg)
+	}
+	return strings.Join(strs, " ")
+}
+
+// formatPrintHTML is like formatPrint but returns HTML in which args are colorized with ColorizeHTML.
+func formatPrintHTML(args []interface{}) string {
+	strs := make([]string, len(args))
+	for i, arg := range args {
+		strs[i] = ColorizeHTML(arg)
+	}
+	return "<pre>" + strings.Join(strs, " ") + "</pre>"
+}
+
+// printLgoPrinters prints args with registered LgoPrinters or the default printer if no printers are registered.
+func printLgoPrinters(args []interface{}) {
+	lgoPrintersMu.Lock()
+	printers := make([]LgoPrinter, 0, len(lgoPrinters))
 	for p := range lgoPrinters {
+		printers = append(printers, p)
+	}
+	lgoPrintersMu.Unlock()
+	if len(printers) == 0 {
+		printDefault(args)
+		return
+	}
+	// Don't hold the lock while printing.
+	for _, p := range printers {
 		p.Println(args...)
 	}
 }
 
 // AllVars keeps pointers to all variables defined in the current lgo process.
 // AllVars is keyed by variable names.
+// AllVars is protected by allVarsMu in this package.
 var AllVars = make(map[string][]interface{})
 
+// allVarsMu protects AllVars and varAccessed.
+var allVarsMu sync.Mutex
+
+// varAccessed records whether registered variables are accessed since they are declared. See MarkVarAccessed.
+var varAccessed = make(map[string]bool)
+
+// errClearWhileRunning is returned from ZeroClearAllVars when goroutines which may access variables are running.
+var errClearWhileRunning = errors.New("cannot clear variables while goroutines are running")
+
+// checkClearVars checks whether the variables of names can be cleared without races.
+// It returns an error listing names if goroutines which may access the variables are running.
+// If the current execution is running, it emits a warning listing names because the variables may have
+// escaped to code still running (e.g. closures called later in the execution). This is best-effort
+// and not a race detector.
+func checkClearVars(names []string) error {
+	if e := getExecState(); (e != nil && e.hasActiveGoroutines()) || DetachedGoroutines() > 0 {
+		return fmt.Errorf("%w: %s", errClearWhileRunning, strings.Join(names, ", "))
+	}
+	if atomic.LoadUint32(&isRunning) == 1 && len(names) > 0 {
+		Warn("clearing variables during the execution may race with code using them: %s", strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// allVarNames returns the sorted names of all variables.
+func allVarNames() []string {
+	allVarsMu.Lock()
+	defer allVarsMu.Unlock()
+	names := make([]string, 0, len(AllVars))
+	for name := range AllVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// zeroClear sets the zero value to the variable p points.
+func zeroClear(p interface{}) {
+	v := reflect.ValueOf(p)
+	v.Elem().Set(reflect.New(v.Type().Elem()).Elem())
+}
+
 // ZeroClearAllVars clear all existing variables defined in lgo with zero-values.
 // You can release memory holded from old variables easily with this function.
-func ZeroClearAllVars() {
+//
+// Clearing variables races with goroutines which access them (e.g. a goroutine ranging a map).
+// ZeroClearAllVars does nothing and returns an error listing the variables if goroutines started
+// in the current execution or detached goroutines (See LingeringDetach) are running.
+// If ZeroClearAllVars is called in an execution, it emits a warning listing the variables with Warn.
+//
+// Clearables registered with RegisterClearable are cleared before variables.
+// After clearing, ZeroClearAllVars runs the garbage collection and returns memory to the OS synchronously,
+// which can pause for seconds on large heaps. Use ZeroClearAllVarsOpts to reclaim memory in the background.
+func ZeroClearAllVars() error {
+	return ZeroClearAllVarsOpts(DefaultClearOptions())
+}
+
+// ZeroClearAllVarsOpts is ZeroClearAllVars which reclaims memory of cleared variables as opts specifies.
+func ZeroClearAllVarsOpts(opts ClearOptions) error {
+	if err := checkClearVars(allVarNames()); err != nil {
+		return err
+	}
+	clearClearables()
+	allVarsMu.Lock()
 	for _, vars := range AllVars {
 		for _, p := range vars {
-			v := reflect.ValueOf(p)
-			v.Elem().Set(reflect.New(v.Type().Elem()).Elem())
+			zeroClear(p)
 		}
 	}
-	// Return memory to OS.
-	debug.FreeOSMemory()
-	runtime.GC()
+	// Don't hold the lock while collecting garbage.
+	allVarsMu.Unlock()
+	reclaimMemory(opts)
+	return nil
 }
 
-// LgoRegisterVar is used to register a variable to AllVars internally.
+// ZeroClearVars clears the variables of names defined in lgo with zero-values.
+// Redeclared variables of the same name are cleared too. ZeroClearVars returns an error and clears nothing
+// if a name is unknown or if goroutines are running like ZeroClearAllVars. It emits a warning listing
+// the variables if it is called in an execution. Unlike ZeroClearAllVars, clearables are not cleared
+// and memory is not reclaimed explicitly.
+func ZeroClearVars(names ...string) error {
+	allVarsMu.Lock()
+	for _, name := range names {
+		if _, ok := AllVars[name]; !ok {
+			allVarsMu.Unlock()
+			return fmt.Errorf("unknown variable: %s", name)
+		}
+	}
+	allVarsMu.Unlock()
+	if err := checkClearVars(names); err != nil {
+		return err
+	}
+	allVarsMu.Lock()
+	defer allVarsMu.Unlock()
+	for _, name := range names {
+		for _, p := range AllVars[name] {
+			zeroClear(p)
+		}
+	}
+	return nil
+}
+
+// LgoRegisterVar registers the pointer p to the variable named name to AllVars.
+// How variables redefined with the same name are kept depends on the mode set with SetRedefineMode.
 func LgoRegisterVar(name string, p interface{}) {
 	v := reflect.ValueOf(p)
 	if v.Kind() != reflect.Ptr {
 		panic("cannot register a non-pointer")
 	}
-	AllVars[name] = append(AllVars[name], p)
+	var typeChanged reflect.Type
+	allVarsMu.Lock()
+	vars := AllVars[name]
+	switch getRedefineMode() {
+	case RedefineReplace:
+		vars = nil
+	case RedefineStrictType:
+		if len(vars) > 0 {
+			if prev := reflect.TypeOf(vars[len(vars)-1]); prev != v.Type() {
+				typeChanged = prev.Elem()
+			}
+		}
+	}
+	AllVars[name] = append(vars, p)
+	varAccessed[name] = false
+	allVarsMu.Unlock()
+	if typeChanged != nil {
+		// Don't hold the lock while displaying the warning.
+		Warn("variable %s is redefined with type %v; it was %v", name, v.Type().Elem(), typeChanged)
+	}
+}
+
+// MarkVarAccessed records the variable registered with name is accessed.
+// The converter injects calls of this function to code blocks which refer variables defined in previous code blocks.
+func MarkVarAccessed(name string) {
+	allVarsMu.Lock()
+	defer allVarsMu.Unlock()
+	if _, ok := varAccessed[name]; ok {
+		varAccessed[name] = true
+	}
+}
+
+// UnusedVars returns the sorted names of registered variables which are not accessed
+// from other code blocks since they are declared.
+// This is a coarse approximation. The accuracy depends on the instrumentation by the converter:
+// variables are marked as accessed only when later code blocks refer them and reads in functions
+// declared in the same code block or through pointers are not counted.
+func UnusedVars() []string {
+	allVarsMu.Lock()
+	defer allVarsMu.Unlock()
+	var names []string
+	for name, accessed := range varAccessed {
+		if !accessed {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
 }
@@ -2,11 +2,79 @@ package core
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 )
 
+// displayed is a content passed to fakeDisplayer.
+type displayed struct {
+	contentType string
+	content     interface{}
+	id          string
+}
+
+// fakeDisplayer is a DataDisplayer which records displayed contents.
+type fakeDisplayer struct {
+	contents []displayed
+	nextID   int
+	// mimeTypes is returned from SupportedMIMETypes if it is not nil.
+	mimeTypes []string
+}
+
+func (d *fakeDisplayer) display(contentType string, content interface{}, id *string) {
+	var i string
+	if id != nil {
+		if *id == "" {
+			d.nextID++
+			*id = fmt.Sprintf("id%d", d.nextID)
+		}
+		i = *id
+	}
+	d.contents = append(d.contents, displayed{contentType, content, i})
+}
+
+func (d *fakeDisplayer) JavaScript(s string, id *string) { d.display("application/javascript", s, id) }
+func (d *fakeDisplayer) HTML(s string, id *string)       { d.display("text/html", s, id) }
+func (d *fakeDisplayer) Markdown(s string, id *string)   { d.display("text/markdown", s, id) }
+func (d *fakeDisplayer) Latex(s string, id *string)      { d.display("text/latex", s, id) }
+func (d *fakeDisplayer) SVG(s string, id *string)        { d.display("image/svg+xml", s, id) }
+func (d *fakeDisplayer) PNG(b []byte, id *string)        { d.display("image/png", b, id) }
+func (d *fakeDisplayer) JPEG(b []byte, id *string)       { d.display("image/jpeg", b, id) }
+func (d *fakeDisplayer) GIF(b []byte, id *string)        { d.display("image/gif", b, id) }
+func (d *fakeDisplayer) PDF(b []byte, id *string)        { d.display("application/pdf", b, id) }
+func (d *fakeDisplayer) Text(s string, id *string)       { d.display("text/plain", s, id) }
+func (d *fakeDisplayer) Raw(contentType string, v interface{}, id *string) error {
+	d.display(contentType, v, id)
+	return nil
+}
+func (d *fakeDisplayer) Widget(modelID string, id *string) error {
+	v, err := WidgetView(modelID)
+	if err != nil {
+		return err
+	}
+	d.display(WidgetViewMIMEType, v, id)
+	return nil
+}
+func (d *fakeDisplayer) SupportedMIMETypes() []string {
+	if d.mimeTypes != nil {
+		return d.mimeTypes
+	}
+	return DefaultSupportedMIMETypes()
+}
+func (d *fakeDisplayer) UpdateDisplay(id string, bundle map[string]interface{}) error {
+	if err := CheckDisplayUpdate(id, bundle); err != nil {
+		return err
+	}
+	d.display("update", bundle, &id)
+	return nil
+}
+
 func TestExecutionContextCancel(t *testing.T) {
 	atomic.StoreUint32(&isRunning, 0)
 	startExec(LgoContext{Context: context.Background()}, func() {})
@@ -43,6 +111,38 @@ func TestExecutionContextCancel(t *testing.T) {
 	ExitIfCtxDone()
 }
 
+func TestExecDone(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	started := make(chan struct{})
+	canceled := make(chan bool)
+	state := startExec(LgoContext{Context: context.Background()}, func() {
+		done := ExecDone()
+		close(started)
+		select {
+		case <-done:
+			canceled <- true
+		case <-time.After(10 * time.Second):
+			canceled <- false
+		}
+	})
+	<-started
+	select {
+	case <-ExecDone():
+		t.Error("ExecDone is closed unexpectedly")
+	default:
+	}
+	state.cancel()
+	if !<-canceled {
+		t.Error("ExecDone was not closed on cancel")
+	}
+	finalizeExec(state)
+	select {
+	case <-ExecDone():
+	default:
+		t.Error("ExecDone must be closed when lgo is idle")
+	}
+}
+
 func TestMainCounters(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -152,7 +252,7 @@ func TestMainCounters(t *testing.T) {
 }
 
 func TestFinalizeExecTimeout(t *testing.T) {
-	execWaitDuration = 10 * time.Millisecond
+	setExecWaitDuration(10 * time.Millisecond)
 
 	atomic.StoreUint32(&isRunning, 0)
 	state := startExec(LgoContext{Context: context.Background()}, func() {
@@ -172,3 +272,267 @@ func TestFinalizeExecTimeout(t *testing.T) {
 		t.Errorf("Unexpected err: %v", err)
 	}
 }
+
+func TestWidgetView(t *testing.T) {
+	if _, err := WidgetView(""); err == nil {
+		t.Error("WidgetView with an empty ID succeeded unexpectedly")
+	}
+	v, err := WidgetView("abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v["model_id"] != "abc" || v["version_major"] != 2 {
+		t.Errorf("Unexpected view: %v", v)
+	}
+}
+
+func TestHangReportDelay(t *testing.T) {
+	defer setExecWaitDuration(setExecWaitDuration(10 * time.Millisecond))
+	SetHangReportDelay(time.Minute)
+	defer SetHangReportDelay(0)
+
+	atomic.StoreUint32(&isRunning, 0)
+	state := startExec(LgoContext{Context: context.Background()}, func() {
+		time.Sleep(100 * time.Millisecond)
+	})
+	state.cancel()
+	if err := finalizeExec(state); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestHangReportDelayHanging(t *testing.T) {
+	defer setExecWaitDuration(setExecWaitDuration(10 * time.Millisecond))
+	const delay = 200 * time.Millisecond
+	SetHangReportDelay(delay)
+	defer SetHangReportDelay(0)
+
+	atomic.StoreUint32(&isRunning, 0)
+	release := make(chan struct{})
+	defer close(release)
+	state := startExec(LgoContext{Context: context.Background()}, func() {
+		e := InitGoroutine()
+		go func() {
+			defer FinalizeGoroutine(e)
+			// Never exits until the test finishes.
+			<-release
+		}()
+	})
+	start := time.Now()
+	state.cancel()
+	err := finalizeExec(state)
+	if want := "1 goroutine is hanging"; err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("Got %v; want %q", err, want)
+	}
+	if d := time.Since(start); d < delay {
+		t.Errorf("finalizeExec returned in %v before the delay", d)
+	}
+}
+
+func TestUnusedVars(t *testing.T) {
+	defer func() {
+		AllVars = make(map[string][]interface{})
+		varAccessed = make(map[string]bool)
+	}()
+	var a, b, c int
+	LgoRegisterVar("a", &a)
+	LgoRegisterVar("b", &b)
+	LgoRegisterVar("c", &c)
+	MarkVarAccessed("b")
+	// Unknown variables are ignored.
+	MarkVarAccessed("d")
+	if got, want := UnusedVars(), []string{"a", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v; want %v", got, want)
+	}
+	// Redeclared variables are not accessed.
+	LgoRegisterVar("b", &b)
+	if got, want := UnusedVars(), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v; want %v", got, want)
+	}
+}
+
+func TestZeroClearAllVars(t *testing.T) {
+	defer func() {
+		AllVars = make(map[string][]interface{})
+		varAccessed = make(map[string]bool)
+	}()
+	atomic.StoreUint32(&isRunning, 0)
+	m := map[string]int{"a": 1, "b": 2}
+	LgoRegisterVar("m", &m)
+	var errRunning error
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		state := InitGoroutine()
+		go func() {
+			defer FinalizeGoroutine(state)
+			close(started)
+			for range m {
+				<-release
+			}
+		}()
+		<-started
+		// This must not race with the goroutine ranging m (go test -race).
+		errRunning = ZeroClearAllVars()
+		close(release)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if errRunning == nil {
+		t.Error("ZeroClearAllVars must fail while goroutines are running")
+	} else if got, want := errRunning.Error(), "cannot clear variables while goroutines are running: m"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	} else if !errors.Is(errRunning, errClearWhileRunning) {
+		t.Errorf("%v does not wrap errClearWhileRunning", errRunning)
+	}
+	if m == nil {
+		t.Error("m is cleared while goroutines are running")
+	}
+	if err := ZeroClearAllVars(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if m != nil {
+		t.Errorf("m is not cleared: %v", m)
+	}
+}
+
+func TestZeroClearVarsWarning(t *testing.T) {
+	defer func() {
+		AllVars = make(map[string][]interface{})
+		varAccessed = make(map[string]bool)
+	}()
+	defer SetWarningHandler(nil)
+	var warnings []string
+	SetWarningHandler(func(msg string) { warnings = append(warnings, msg) })
+	atomic.StoreUint32(&isRunning, 0)
+	a, b := 1, "b"
+	LgoRegisterVar("a", &a)
+	LgoRegisterVar("b", &b)
+	if err := ZeroClearVars("a", "c"); err == nil || err.Error() != "unknown variable: c" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if a != 1 {
+		t.Errorf("a is cleared with an unknown variable: %d", a)
+	}
+	var errA, errAll error
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		errA = ZeroClearVars("a")
+		b = "c"
+		errAll = ZeroClearAllVars()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if errA != nil || errAll != nil {
+		t.Errorf("Unexpected errors: %v, %v", errA, errAll)
+	}
+	if a != 0 || b != "" {
+		t.Errorf("Variables are not cleared: %d, %q", a, b)
+	}
+	want := []string{
+		"clearing variables during the execution may race with code using them: a",
+		"clearing variables during the execution may race with code using them: a, b",
+	}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("Got %q; want %q", warnings, want)
+	}
+	// No warnings outside executions.
+	warnings = nil
+	b = "d"
+	if err := ZeroClearVars("b"); err != nil {
+		t.Error(err)
+	}
+	if b != "" || warnings != nil {
+		t.Errorf("Unexpected b or warnings: %q, %q", b, warnings)
+	}
+}
+
+func TestDefaultSupportedMIMETypes(t *testing.T) {
+	defer SetJavaScriptEnabled(false)
+	for _, enabled := range []bool{false, true} {
+		SetJavaScriptEnabled(enabled)
+		if got := containsString(DefaultSupportedMIMETypes(), "application/javascript"); got != enabled {
+			t.Errorf("Got %v; want %v", got, enabled)
+		}
+	}
+}
+
+// teePrinter prints to another printer by calling LgoPrintln and registers the printer on the first call.
+type teePrinter struct {
+	mu    sync.Mutex
+	other *countPrinter
+	lines []string
+}
+
+func (p *teePrinter) Println(args ...interface{}) {
+	p.mu.Lock()
+	line := fmt.Sprint(args...)
+	p.lines = append(p.lines, line)
+	p.mu.Unlock()
+	if line == "tee" {
+		// Avoid an infinite recursion.
+		return
+	}
+	RegisterLgoPrinter(p.other)
+	LgoPrintln("tee")
+}
+
+type countPrinter struct {
+	n int32
+}
+
+func (p *countPrinter) Println(args ...interface{}) {
+	atomic.AddInt32(&p.n, 1)
+}
+
+func TestLgoPrintlnReentrant(t *testing.T) {
+	// Run with go test -race
+	p := &teePrinter{other: &countPrinter{}}
+	RegisterLgoPrinter(p)
+	defer UnregisterLgoPrinter(p)
+	defer UnregisterLgoPrinter(p.other)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			LgoPrintln("hello")
+		}()
+	}
+	wg.Wait()
+	var hello, tee int
+	for _, l := range p.lines {
+		switch l {
+		case "hello":
+			hello++
+		case "tee":
+			tee++
+		}
+	}
+	if hello != 4 || tee != 4 {
+		t.Errorf("Got (%d, %d); want (4, 4)", hello, tee)
+	}
+	if n := atomic.LoadInt32(&p.other.n); n < 4 || n > 8 {
+		t.Errorf("Unexpected count: %d", n)
+	}
+}
+
+// linesPrinter records printed lines.
+type linesPrinter struct {
+	lines []string
+}
+
+func (p *linesPrinter) Println(args ...interface{}) {
+	p.lines = append(p.lines, fmt.Sprint(args...))
+}
+
+func TestLgoPrintlnMulti(t *testing.T) {
+	p := &linesPrinter{}
+	RegisterLgoPrinter(p)
+	defer UnregisterLgoPrinter(p)
+	LgoPrintlnMulti(10, float32(2.1))
+	LgoPrintlnMulti(1, "a", nil)
+	want := []string{"(10, 2.1)", "(1, a, <nil>)"}
+	if !reflect.DeepEqual(p.lines, want) {
+		t.Errorf("Got %q; want %q", p.lines, want)
+	}
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// defaultPrinterMu protects defaultPrinterWriter and defaultPrinterDisabled.
+var defaultPrinterMu sync.Mutex
+
+// defaultPrinterWriter is the writer of the default printer. nil means os.Stdout.
+var defaultPrinterWriter io.Writer
+
+// defaultPrinterDisabled is set by DisableDefaultPrinter.
+var defaultPrinterDisabled bool
+
+// SetDefaultPrinterWriter enables the default printer and sets w as its output.
+// The default printer prints the results of lgo expressions formatted with FormatValue only while
+// no LgoPrinters are registered, so that results do not vanish when lgo runs code outside Jupyter
+// (e.g. as a script runner). The default printer writes to os.Stdout by default. If w is nil, os.Stdout is used.
+func SetDefaultPrinterWriter(w io.Writer) {
+	defaultPrinterMu.Lock()
+	defer defaultPrinterMu.Unlock()
+	defaultPrinterWriter = w
+	defaultPrinterDisabled = false
+}
+
+// DisableDefaultPrinter disables the default printer. Results of lgo expressions are not printed
+// while no LgoPrinters are registered. Call SetDefaultPrinterWriter to enable it again.
+func DisableDefaultPrinter() {
+	defaultPrinterMu.Lock()
+	defer defaultPrinterMu.Unlock()
+	defaultPrinterDisabled = true
+}
+
+// defaultPrinterWriteMu serializes writes of the default printer so that lines are not interleaved.
+var defaultPrinterWriteMu sync.Mutex
+
+// printDefault prints args with the default printer if it is enabled.
+func printDefault(args []interface{}) {
+	defaultPrinterMu.Lock()
+	w, disabled := defaultPrinterWriter, defaultPrinterDisabled
+	defaultPrinterMu.Unlock()
+	if disabled {
+		return
+	}
+	if w == nil {
+		w = os.Stdout
+	}
+	// Don't hold the locks while formatting because String methods may print values.
+	line := formatPrint(args)
+	defaultPrinterWriteMu.Lock()
+	defer defaultPrinterWriteMu.Unlock()
+	io.WriteString(w, line+"\n")
+}
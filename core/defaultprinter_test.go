@@ -0,0 +1,31 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDefaultPrinter(t *testing.T) {
+	defer SetDefaultPrinterWriter(nil)
+	var buf bytes.Buffer
+	SetDefaultPrinterWriter(&buf)
+	LgoPrintln("hello", 10)
+	LgoPrintlnMulti(1, "a")
+
+	p := &linesPrinter{}
+	RegisterLgoPrinter(p)
+	LgoPrintln("registered")
+	UnregisterLgoPrinter(p)
+
+	DisableDefaultPrinter()
+	LgoPrintln("disabled")
+	SetDefaultPrinterWriter(&buf)
+	LgoPrintln("enabled")
+
+	if got, want := buf.String(), "hello 10\n(1, a)\nenabled\n"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	if got, want := len(p.lines), 1; got != want {
+		t.Errorf("Got %d; want %d", got, want)
+	}
+}
@@ -0,0 +1,325 @@
+package core
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+// recordingDisplayer is the DataDisplayer of an execution which wraps the DataDisplayer passed to the execution.
+// It records outputs to the execution and buffers outputs between BeginBatch and EndBatch.
+type recordingDisplayer struct {
+	d DataDisplayer
+	e *ExecutionState
+}
+
+// batch buffers outputs between BeginBatch and EndBatch.
+type batch struct {
+	mu    sync.Mutex
+	depth int
+	calls []func()
+}
+
+// BeginBatch starts buffering outputs displayed with the DataDisplayer of the current execution.
+// Buffered outputs are displayed at once in the order of calls when EndBatch is called
+// to reduce flickers when a composite output with several displays is updated.
+// BeginBatch and EndBatch can be nested. Outputs are displayed when the outermost EndBatch is called.
+// Buffered outputs are also displayed when the execution finishes without calling EndBatch.
+//
+// Note that display IDs of buffered outputs are assigned when they are displayed.
+func BeginBatch() {
+	if e := getExecState(); e != nil {
+		e.batch.mu.Lock()
+		defer e.batch.mu.Unlock()
+		e.batch.depth++
+	}
+}
+
+// EndBatch ends buffering started with BeginBatch and displays buffered outputs.
+func EndBatch() {
+	if e := getExecState(); e != nil {
+		e.endBatch(false)
+	}
+}
+
+// endBatch displays buffered outputs if the outermost batch ends. If force is true, all batches end.
+func (e *ExecutionState) endBatch(force bool) {
+	e.batch.mu.Lock()
+	if e.batch.depth > 0 {
+		e.batch.depth--
+	}
+	if force {
+		e.batch.depth = 0
+	}
+	var calls []func()
+	if e.batch.depth == 0 {
+		calls = e.batch.calls
+		e.batch.calls = nil
+	}
+	e.batch.mu.Unlock()
+	// Don't hold the lock while calling the DataDisplayer.
+	e.emit(func() {
+		for _, c := range calls {
+			c()
+		}
+	})
+}
+
+// do calls f immediately or buffers f if the execution is in a batch.
+func (r *recordingDisplayer) do(f func()) {
+	r.e.do(f)
+}
+
+// doID is like do but f is called with a copy of *id if f is buffered because callers may reuse id
+// after the call. The display ID assigned to the copy is stored to id when f is called.
+func (r *recordingDisplayer) doID(id *string, f func(id *string)) {
+	r.doBytes(nil, id, func(_ []byte, id *string) { f(id) })
+}
+
+// doBytes is like doID but b is also copied if f is buffered.
+func (r *recordingDisplayer) doBytes(b []byte, id *string, f func(b []byte, id *string)) {
+	r.e.doCopy(func() { f(b, id) }, func() func() {
+		b := append([]byte(nil), b...)
+		if id == nil {
+			return func() { f(b, nil) }
+		}
+		c := *id
+		return func() {
+			f(b, &c)
+			*id = c
+		}
+	})
+}
+
+// do emits an output with f immediately or buffers f if the execution is in a batch.
+func (e *ExecutionState) do(f func()) {
+	e.doCopy(f, func() func() { return f })
+}
+
+// doCopy emits an output with f immediately or buffers the function returned by copy if the execution is in a batch.
+// copy returns a function which emits the output with copies of the arguments of f.
+func (e *ExecutionState) doCopy(f func(), copy func() func()) {
+	b := &e.batch
+	b.mu.Lock()
+	if b.depth > 0 {
+		b.calls = append(b.calls, copy())
+		b.mu.Unlock()
+		return
+	}
+	b.mu.Unlock()
+	e.emit(f)
+}
+
+func (r *recordingDisplayer) JavaScript(s string, id *string) {
+	r.doID(id, func(id *string) {
+		r.d.JavaScript(s, id)
+		r.e.recordOutput("application/javascript", s, id)
+	})
+}
+
+func (r *recordingDisplayer) HTML(s string, id *string) {
+	r.doID(id, func(id *string) {
+		r.d.HTML(s, id)
+		r.e.recordOutput("text/html", s, id)
+	})
+}
+
+func (r *recordingDisplayer) Markdown(s string, id *string) {
+	r.doID(id, func(id *string) {
+		r.d.Markdown(s, id)
+		r.e.recordOutput("text/markdown", s, id)
+	})
+}
+
+func (r *recordingDisplayer) Latex(s string, id *string) {
+	r.doID(id, func(id *string) {
+		r.d.Latex(s, id)
+		r.e.recordOutput("text/latex", s, id)
+	})
+}
+
+func (r *recordingDisplayer) SVG(s string, id *string) {
+	r.doID(id, func(id *string) {
+		r.d.SVG(s, id)
+		r.e.recordOutput("image/svg+xml", s, id)
+	})
+}
+
+func (r *recordingDisplayer) PNG(b []byte, id *string) {
+	r.doBytes(b, id, func(b []byte, id *string) {
+		r.d.PNG(b, id)
+		r.e.recordOutput("image/png", b, id)
+	})
+}
+
+func (r *recordingDisplayer) JPEG(b []byte, id *string) {
+	r.doBytes(b, id, func(b []byte, id *string) {
+		r.d.JPEG(b, id)
+		r.e.recordOutput("image/jpeg", b, id)
+	})
+}
+
+func (r *recordingDisplayer) GIF(b []byte, id *string) {
+	r.doBytes(b, id, func(b []byte, id *string) {
+		r.d.GIF(b, id)
+		r.e.recordOutput("image/gif", b, id)
+	})
+}
+
+func (r *recordingDisplayer) PDF(b []byte, id *string) {
+	r.doBytes(b, id, func(b []byte, id *string) {
+		r.d.PDF(b, id)
+		r.e.recordOutput("application/pdf", b, id)
+	})
+}
+
+func (r *recordingDisplayer) Text(s string, id *string) {
+	r.doID(id, func(id *string) {
+		r.d.Text(s, id)
+		r.e.recordOutput("text/plain", s, id)
+	})
+}
+
+func (r *recordingDisplayer) Raw(contentType string, v interface{}, id *string) error {
+	// Validate v beforehand because errors of buffered outputs can not be returned.
+	if _, err := json.Marshal(v); err != nil {
+		return err
+	}
+	r.doID(id, func(id *string) {
+		if err := r.d.Raw(contentType, v, id); err == nil {
+			r.e.recordOutput(contentType, v, id)
+		}
+	})
+	return nil
+}
+
+func (r *recordingDisplayer) Widget(modelID string, id *string) error {
+	v, err := WidgetView(modelID)
+	if err != nil {
+		return err
+	}
+	r.doID(id, func(id *string) {
+		if err := r.d.Widget(modelID, id); err == nil {
+			r.e.recordOutput(WidgetViewMIMEType, v, id)
+		}
+	})
+	return nil
+}
+
+func (r *recordingDisplayer) UpdateDisplay(id string, bundle map[string]interface{}) error {
+	if err := CheckDisplayUpdate(id, bundle); err != nil {
+		return err
+	}
+	// Copy bundle because callers may modify it before buffered outputs are displayed.
+	b := make(map[string]interface{}, len(bundle))
+	for k, v := range bundle {
+		b[k] = v
+	}
+	bundle = b
+	r.do(func() {
+		if err := r.d.UpdateDisplay(id, bundle); err != nil {
+			return
+		}
+		var types []string
+		for t := range bundle {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		for _, t := range types {
+			r.e.recordOutput(t, bundle[t], &id)
+		}
+	})
+	return nil
+}
+
+func (r *recordingDisplayer) SupportedMIMETypes() []string {
+	return r.d.SupportedMIMETypes()
+}
+
+// DisplayBundle implements BundleDisplayer if the wrapped DataDisplayer implements BundleDisplayer.
+func (r *recordingDisplayer) DisplayBundle(bundle map[string]interface{}, metadata map[string]interface{}, id *string) error {
+	bd, ok := r.d.(BundleDisplayer)
+	if !ok {
+		return errBundleUnsupported
+	}
+	if _, err := json.Marshal(bundle); err != nil {
+		return err
+	}
+	r.doID(id, func(id *string) {
+		if err := bd.DisplayBundle(bundle, metadata, id); err != nil {
+			return
+		}
+		var types []string
+		for t := range bundle {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		for _, t := range types {
+			r.e.recordOutput(t, bundle[t], id)
+		}
+	})
+	return nil
+}
+
+// DisplayLazy implements LazyDisplayer if the wrapped DataDisplayer implements LazyDisplayer.
+// The content is recorded when fn is called.
+func (r *recordingDisplayer) DisplayLazy(fn func() (mime string, payload interface{}, err error), id *string) error {
+	ld, ok := r.d.(LazyDisplayer)
+	if !ok {
+		return errLazyUnsupported
+	}
+	// Fall back in batches so that the content is not displayed before buffered outputs.
+	r.e.batch.mu.Lock()
+	inBatch := r.e.batch.depth > 0
+	r.e.batch.mu.Unlock()
+	if inBatch {
+		return errLazyUnsupported
+	}
+	return ld.DisplayLazy(func() (string, interface{}, error) {
+		mime, payload, err := fn()
+		if err == nil {
+			r.e.recordOutput(mime, payload, id)
+		}
+		return mime, payload, err
+	}, id)
+}
+
+// nopDisplayer is a DataDisplayer which discards all outputs.
+type nopDisplayer struct{}
+
+func (nopDisplayer) JavaScript(s string, id *string) {}
+func (nopDisplayer) HTML(s string, id *string)       {}
+func (nopDisplayer) Markdown(s string, id *string)   {}
+func (nopDisplayer) Latex(s string, id *string)      {}
+func (nopDisplayer) SVG(s string, id *string)        {}
+func (nopDisplayer) PNG(b []byte, id *string)        {}
+func (nopDisplayer) JPEG(b []byte, id *string)       {}
+func (nopDisplayer) GIF(b []byte, id *string)        {}
+func (nopDisplayer) PDF(b []byte, id *string)        {}
+func (nopDisplayer) Text(s string, id *string)       {}
+
+func (nopDisplayer) Raw(contentType string, v interface{}, id *string) error { return nil }
+func (nopDisplayer) Widget(modelID string, id *string) error                 { return nil }
+func (nopDisplayer) UpdateDisplay(id string, bundle map[string]interface{}) error {
+	return nil
+}
+func (nopDisplayer) SupportedMIMETypes() []string { return DefaultSupportedMIMETypes() }
+
+// NopDisplayer returns a DataDisplayer which discards all outputs (e.g. to run code which displays
+// outputs in tests or scripts).
+func NopDisplayer() DataDisplayer {
+	return nopDisplayer{}
+}
+
+// CurrentDisplay returns the DataDisplayer of the current code execution so that libraries
+// which do not receive LgoContext can display outputs.
+// It returns a DataDisplayer which discards outputs when lgo does not execute any code blocks.
+//
+// Note that CurrentDisplay returns the displayer of the code block executed currently,
+// not the code block in which the caller was started (e.g. a goroutine left from a previous execution).
+func CurrentDisplay() DataDisplayer {
+	if e := getExecState(); e != nil && e.Context.Display != nil {
+		return e.Context.Display
+	}
+	return nopDisplayer{}
+}
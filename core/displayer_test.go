@@ -0,0 +1,180 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var d fakeDisplayer
+	var inBatch int
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Display: &d}, func() {
+		disp := GetExecContext().Display
+		BeginBatch()
+		disp.HTML("a", nil)
+		BeginBatch()
+		disp.Text("b", nil)
+		EndBatch()
+		disp.Markdown("c", nil)
+		inBatch = len(d.contents)
+		EndBatch()
+		disp.HTML("d", nil)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if inBatch != 0 {
+		t.Errorf("%d contents are displayed in a batch", inBatch)
+	}
+	var got []string
+	for _, c := range d.contents {
+		got = append(got, c.content.(string))
+	}
+	if len(got) != 4 || got[0] != "a" || got[1] != "b" || got[2] != "c" || got[3] != "d" {
+		t.Errorf("Got %v; want [a b c d]", got)
+	}
+}
+
+func TestBatchCopyArgs(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var d fakeDisplayer
+	var id1, id2 string
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Display: &d}, func() {
+		disp := GetExecContext().Display
+		BeginBatch()
+		b := []byte("a")
+		id := "x"
+		disp.PNG(b, &id)
+		// Reuse the buffer and the ID before the outputs are displayed.
+		b[0] = 'b'
+		id = ""
+		disp.PNG(b, &id)
+		id1 = id
+		EndBatch()
+		id2 = id
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.contents) != 2 {
+		t.Fatalf("Got %d contents; want 2", len(d.contents))
+	}
+	if c := d.contents[0]; string(c.content.([]byte)) != "a" || c.id != "x" {
+		t.Errorf("Unexpected content: %v", c)
+	}
+	if c := d.contents[1]; string(c.content.([]byte)) != "b" || c.id != "id1" {
+		t.Errorf("Unexpected content: %v", c)
+	}
+	// IDs are assigned when buffered outputs are displayed.
+	if id1 != "" || id2 != "id1" {
+		t.Errorf("Got %q and %q; want \"\" and \"id1\"", id1, id2)
+	}
+}
+
+func TestBatchFlushOnPanic(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var d fakeDisplayer
+	err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Display: &d}, func() {
+		BeginBatch()
+		GetExecContext().Display.HTML("a", nil)
+		panic("fail")
+	})
+	if err == nil {
+		t.Error("Unexpected success")
+	}
+	if len(d.contents) != 1 {
+		t.Errorf("Buffered outputs are not displayed: %v", d.contents)
+	}
+}
+
+func TestCurrentDisplay(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	// Outputs are discarded without panics while idle.
+	CurrentDisplay().HTML("idle", nil)
+
+	var d fakeDisplayer
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Display: &d}, func() {
+		CurrentDisplay().Text("hello", nil)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.contents) != 1 || d.contents[0].content != "hello" {
+		t.Errorf("Unexpected contents: %v", d.contents)
+	}
+}
+
+func TestNopDisplayer(t *testing.T) {
+	d := NopDisplayer()
+	id := ""
+	d.JavaScript("a", &id)
+	d.HTML("a", &id)
+	d.Markdown("a", &id)
+	d.Latex("a", &id)
+	d.SVG("a", &id)
+	d.PNG([]byte("a"), &id)
+	d.JPEG([]byte("a"), &id)
+	d.GIF([]byte("a"), &id)
+	d.PDF([]byte("a"), &id)
+	d.Text("a", nil)
+	if err := d.Raw("application/json", 1, &id); err != nil {
+		t.Error(err)
+	}
+	if err := d.Widget("model", nil); err != nil {
+		t.Error(err)
+	}
+	if err := d.UpdateDisplay("id", map[string]interface{}{"text/plain": "a"}); err != nil {
+		t.Error(err)
+	}
+	if types := d.SupportedMIMETypes(); len(types) == 0 {
+		t.Error("No MIME types are supported")
+	}
+}
+
+func TestGetExecContextNilDisplay(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	if GetExecContext().Display == nil {
+		t.Error("Display is nil while idle")
+	}
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		// This must not panic.
+		GetExecContext().Display.HTML("a", nil)
+		CurrentDisplay().HTML("a", nil)
+	}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpdateDisplay(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	defer SetTranscriptEnabled(false)
+	SetTranscriptEnabled(true)
+	var d fakeDisplayer
+	var errEmpty error
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Display: &d}, func() {
+		disp := GetExecContext().Display
+		id := ""
+		disp.Text("a", &id)
+		errEmpty = disp.UpdateDisplay("", map[string]interface{}{"text/plain": "b"})
+		disp.UpdateDisplay(id, map[string]interface{}{"text/plain": "c", "text/html": "<b>c</b>"})
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if errEmpty == nil {
+		t.Error("UpdateDisplay with an empty id must fail")
+	}
+	if len(d.contents) != 2 {
+		t.Fatalf("Got %d contents; want 2", len(d.contents))
+	}
+	if c := d.contents[1]; c.contentType != "update" || c.id != "id1" {
+		t.Errorf("Unexpected content: %v", c)
+	}
+	var got []string
+	for _, ev := range LastExecutionTranscript() {
+		got = append(got, ev.MIMEType+":"+ev.ID)
+	}
+	want := []string{"text/plain:id1", "text/html:id1", "text/plain:id1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v; want %v", got, want)
+	}
+}
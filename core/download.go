@@ -0,0 +1,37 @@
+package core
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"mime"
+)
+
+// maxDownloadSize is the maximum size of files displayed with DisplayDownload.
+const maxDownloadSize = 10 << 20
+
+// DisplayDownload displays a link to download the content read from r as a file named filename.
+// The content is embedded in the link as a data URI, so its size is limited to 10MB.
+// If contentType is empty, "application/octet-stream" is used.
+func DisplayDownload(d DataDisplayer, filename string, r io.Reader, contentType string, id *string) error {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("invalid content type %q: %v", contentType, err)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(r, maxDownloadSize+1))
+	if err != nil {
+		return err
+	}
+	if len(b) > maxDownloadSize {
+		return fmt.Errorf("%s is too large to download: the limit is %d bytes", filename, maxDownloadSize)
+	}
+	href := "data:" + mime.FormatMediaType(mediaType, params) + ";base64," + base64.StdEncoding.EncodeToString(b)
+	name := html.EscapeString(filename)
+	d.HTML(fmt.Sprintf(`<a class="lgo-download" download="%s" href="%s">Download %s</a>`, name, html.EscapeString(href), name), id)
+	return nil
+}
@@ -0,0 +1,38 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDisplayDownload(t *testing.T) {
+	var d fakeDisplayer
+	if err := DisplayDownload(&d, `a"<b>.csv`, strings.NewReader("x,y\n1,2\n"), "text/csv", nil); err != nil {
+		t.Fatal(err)
+	}
+	want := `<a class="lgo-download" download="a&#34;&lt;b&gt;.csv" href="data:text/csv;base64,eCx5CjEsMgo=">Download a&#34;&lt;b&gt;.csv</a>`
+	if len(d.contents) != 1 || d.contents[0].content != want {
+		t.Errorf("Got %v; want %q", d.contents, want)
+	}
+	if err := DisplayDownload(&d, "a.bin", strings.NewReader(""), "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := d.contents[1].content.(string); !strings.Contains(got, `href="data:application/octet-stream;base64,"`) {
+		t.Errorf("Unexpected output: %s", got)
+	}
+}
+
+func TestDisplayDownloadError(t *testing.T) {
+	var d fakeDisplayer
+	if err := DisplayDownload(&d, "a", strings.NewReader(""), "text/html\"><script>", nil); err == nil {
+		t.Error("DisplayDownload with an invalid content type succeeded unexpectedly")
+	}
+	large := bytes.NewReader(make([]byte, maxDownloadSize+1))
+	if err := DisplayDownload(&d, "a", large, "", nil); err == nil {
+		t.Error("DisplayDownload with a large content succeeded unexpectedly")
+	}
+	if len(d.contents) != 0 {
+		t.Errorf("Unexpected outputs: %v", d.contents)
+	}
+}
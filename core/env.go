@@ -0,0 +1,43 @@
+package core
+
+import (
+	"os"
+	"sync"
+)
+
+// envMu serializes WithEnv.
+var envMu sync.Mutex
+
+// WithEnv sets the environment variables in vars, calls fn and restores the environment variables
+// after fn returns or panics. Variables which were not set before are unset.
+//
+// Note that environment variables are global to the process. Calls of WithEnv in concurrent
+// code executions (or goroutines) are serialized, so WithEnv blocks while another WithEnv is running.
+// WithEnv must not be nested. Code which does not use WithEnv still sees the variables set in vars while fn is running.
+func WithEnv(vars map[string]string, fn func()) error {
+	envMu.Lock()
+	defer envMu.Unlock()
+	type saved struct {
+		value string
+		ok    bool
+	}
+	prev := make(map[string]saved, len(vars))
+	defer func() {
+		for k, s := range prev {
+			if s.ok {
+				os.Setenv(k, s.value)
+			} else {
+				os.Unsetenv(k)
+			}
+		}
+	}()
+	for k, v := range vars {
+		value, ok := os.LookupEnv(k)
+		prev[k] = saved{value, ok}
+		if err := os.Setenv(k, v); err != nil {
+			return err
+		}
+	}
+	fn()
+	return nil
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"os"
+	"testing"
+)
+
+func TestWithEnv(t *testing.T) {
+	os.Setenv("LGO_TEST_ENV_SET", "old")
+	defer os.Unsetenv("LGO_TEST_ENV_SET")
+	os.Unsetenv("LGO_TEST_ENV_UNSET")
+	check := func(key, want string, wantOK bool) {
+		t.Helper()
+		if got, ok := os.LookupEnv(key); got != want || ok != wantOK {
+			t.Errorf("Got (%q, %v); want (%q, %v)", got, ok, want, wantOK)
+		}
+	}
+	vars := map[string]string{
+		"LGO_TEST_ENV_SET":   "new",
+		"LGO_TEST_ENV_UNSET": "value",
+	}
+	called := false
+	if err := WithEnv(vars, func() {
+		called = true
+		check("LGO_TEST_ENV_SET", "new", true)
+		check("LGO_TEST_ENV_UNSET", "value", true)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Error("fn is not called")
+	}
+	check("LGO_TEST_ENV_SET", "old", true)
+	check("LGO_TEST_ENV_UNSET", "", false)
+
+	// Variables are restored even if fn panics.
+	func() {
+		defer func() {
+			if r := recover(); r != "fail" {
+				t.Errorf("Got %v; want %q", r, "fail")
+			}
+		}()
+		WithEnv(vars, func() { panic("fail") })
+	}()
+	check("LGO_TEST_ENV_SET", "old", true)
+	check("LGO_TEST_ENV_UNSET", "", false)
+
+	// Invalid names are rejected.
+	if err := WithEnv(map[string]string{"": "x"}, func() { t.Error("fn must not be called") }); err == nil {
+		t.Error("WithEnv with an empty name succeeded unexpectedly")
+	}
+}
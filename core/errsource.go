@@ -0,0 +1,75 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// errorContextLines is the number of lines displayed before and after the line of an error.
+const errorContextLines = 2
+
+// fileLineRe matches locations like "/path/to/file.go:42" in error messages and stack traces.
+var fileLineRe = regexp.MustCompile(`([^\s:()]+\.go):(\d+)`)
+
+// findErrorLocation returns the first location in msg whose file is found in sources.
+// Files are matched by the full path first and then by the base name.
+func findErrorLocation(msg string, sources map[string]string) (file string, line int, ok bool) {
+	for _, m := range fileLineRe.FindAllStringSubmatch(msg, -1) {
+		n, err := strconv.Atoi(m[2])
+		if err != nil || n <= 0 {
+			continue
+		}
+		if _, found := sources[m[1]]; found {
+			return m[1], n, true
+		}
+		base := path.Base(m[1])
+		for name := range sources {
+			if path.Base(name) == base {
+				return name, n, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// DisplayErrorWithSource displays err with the source code around the location where err occurred.
+// The location is the first "file.go:line" in the message of err (e.g. a stack trace of a panic) whose file is
+// in sources, which maps file names to the source code. It displays only the message of err as a text if
+// no location is found in sources.
+func DisplayErrorWithSource(d DataDisplayer, err error, sources map[string]string, id *string) {
+	msg := err.Error()
+	file, line, ok := findErrorLocation(msg, sources)
+	var lines []string
+	if ok {
+		lines = strings.Split(sources[file], "\n")
+	}
+	if !ok || line > len(lines) {
+		d.Text(msg, id)
+		return
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<pre style="color:#c00">%s</pre>`, html.EscapeString(msg))
+	fmt.Fprintf(&buf, "<div>%s:%d</div><pre>", html.EscapeString(file), line)
+	start, end := line-errorContextLines, line+errorContextLines
+	if start < 1 {
+		start = 1
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	for i := start; i <= end; i++ {
+		text := fmt.Sprintf("%4d  %s", i, html.EscapeString(lines[i-1]))
+		if i == line {
+			fmt.Fprintf(&buf, `<span style="background-color:#fdd;font-weight:bold">%s</span>`+"\n", text)
+		} else {
+			buf.WriteString(text + "\n")
+		}
+	}
+	buf.WriteString("</pre>")
+	d.HTML(buf.String(), id)
+}
@@ -0,0 +1,35 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDisplayErrorWithSource(t *testing.T) {
+	sources := map[string]string{
+		"cell1.go": "package lgo\n\nfunc f() {\n\tpanic(\"<oops>\")\n}\n",
+	}
+	err := errors.New("panic: <oops>\n\ngoroutine 1 [running]:\nlgo.f()\n\t/tmp/lgo/sess/cell1.go:4 +0x39\n")
+	var d fakeDisplayer
+	DisplayErrorWithSource(&d, err, sources, nil)
+	s := d.contents[0].content.(string)
+	for _, want := range []string{
+		"panic: &lt;oops&gt;",
+		"<div>cell1.go:4</div>",
+		`<span style="background-color:#fdd;font-weight:bold">   4  	panic(&#34;&lt;oops&gt;&#34;)</span>`,
+		"   2  \n",
+		"   5  }\n",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("%q is not found in %s", want, s)
+		}
+	}
+
+	// Fall back to a text
+	d = fakeDisplayer{}
+	DisplayErrorWithSource(&d, errors.New("error at other.go:3"), sources, nil)
+	if c := d.contents[0]; c.contentType != "text/plain" || c.content != "error at other.go:3" {
+		t.Errorf("Unexpected content: %v", c)
+	}
+}
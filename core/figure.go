@@ -0,0 +1,49 @@
+package core
+
+import (
+	"fmt"
+	"mime"
+)
+
+// Figure is the interface that plotting libraries implement to display figures with DisplayFigure.
+// Render returns the MIME type of the rendered figure (e.g. "image/png" or "image/svg+xml") and its data.
+type Figure interface {
+	Render() (mime string, data []byte, err error)
+}
+
+// figureTextTypes are the MIME types of figures displayed as texts.
+var figureTextTypes = map[string]bool{
+	"image/svg+xml": true,
+	"text/html":     true,
+	"text/plain":    true,
+}
+
+// figureBinaryTypes are the MIME types of figures displayed as binaries.
+var figureBinaryTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"application/pdf": true,
+}
+
+// DisplayFigure renders f and displays the figure with the method of d for the MIME type returned
+// from Render (e.g. PNG for "image/png" and SVG for "image/svg+xml"). Parameters of the MIME type
+// (e.g. "; charset=utf-8") are ignored. Supported types are PNG, JPEG, GIF, SVG, PDF, HTML and plain texts.
+// It returns the error of Render or an error if the MIME type is not supported.
+func DisplayFigure(d DataDisplayer, f Figure, id *string) error {
+	typ, data, err := f.Render()
+	if err != nil {
+		return err
+	}
+	mediaType, _, err := mime.ParseMediaType(typ)
+	if err != nil {
+		return fmt.Errorf("invalid MIME type of figure %q: %v", typ, err)
+	}
+	switch {
+	case figureTextTypes[mediaType]:
+		return displayPayload(d, mediaType, string(data), id)
+	case figureBinaryTypes[mediaType]:
+		return displayPayload(d, mediaType, data, id)
+	}
+	return fmt.Errorf("unsupported MIME type of figure: %s", mediaType)
+}
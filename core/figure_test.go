@@ -0,0 +1,68 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+type fakeFigure struct {
+	mime string
+	data []byte
+	err  error
+}
+
+func (f *fakeFigure) Render() (string, []byte, error) {
+	return f.mime, f.data, f.err
+}
+
+func TestDisplayFigure(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n")
+	svg := `<svg xmlns="http://www.w3.org/2000/svg"></svg>`
+	var d fakeDisplayer
+	id := ""
+	if err := DisplayFigure(&d, &fakeFigure{mime: "image/png", data: png}, &id); err != nil {
+		t.Fatal(err)
+	}
+	if err := DisplayFigure(&d, &fakeFigure{mime: "Image/SVG+XML; charset=utf-8", data: []byte(svg)}, &id); err != nil {
+		t.Fatal(err)
+	}
+	want := []displayed{
+		{"image/png", png, "id1"},
+		{"image/svg+xml", svg, "id1"},
+	}
+	if len(d.contents) != len(want) {
+		t.Fatalf("Got %d contents; want %d", len(d.contents), len(want))
+	}
+	for i, c := range d.contents {
+		if c.contentType != want[i].contentType || c.id != want[i].id {
+			t.Errorf("Got %s (id: %q); want %s (id: %q)", c.contentType, c.id, want[i].contentType, want[i].id)
+		}
+	}
+	if got, ok := d.contents[0].content.([]byte); !ok || string(got) != string(png) {
+		t.Errorf("Got %q; want %q", d.contents[0].content, png)
+	}
+	if got := d.contents[1].content; got != svg {
+		t.Errorf("Got %q; want %q", got, svg)
+	}
+}
+
+func TestDisplayFigureError(t *testing.T) {
+	renderErr := errors.New("render failed")
+	tests := []struct {
+		f    *fakeFigure
+		want string
+	}{
+		{&fakeFigure{err: renderErr}, "render failed"},
+		{&fakeFigure{mime: "video/mp4"}, "unsupported MIME type of figure: video/mp4"},
+		{&fakeFigure{mime: ""}, `invalid MIME type of figure "": mime: no media type`},
+	}
+	var d fakeDisplayer
+	for _, tc := range tests {
+		if err := DisplayFigure(&d, tc.f, nil); err == nil || err.Error() != tc.want {
+			t.Errorf("Got %v; want %q", err, tc.want)
+		}
+	}
+	if len(d.contents) != 0 {
+		t.Errorf("Unexpected contents: %v", d.contents)
+	}
+}
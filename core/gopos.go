@@ -0,0 +1,76 @@
+package core
+
+import (
+	"sort"
+	"strings"
+)
+
+// posResult is the results of goroutines started at a position.
+type posResult struct {
+	active int
+	fail   int
+}
+
+// InitGoroutineAt is InitGoroutine which also records pos, the position of the go statement
+// (e.g. "cell1.go:42"), so that failed and hanging goroutines are reported with their positions.
+// Goroutines started with InitGoroutineAt must be finalized with FinalizeGoroutineAt with the same pos.
+func InitGoroutineAt(pos string) *ExecutionState {
+	e := getExecState()
+	if e == nil {
+		return nil
+	}
+	e.initGoroutine(goroutineLabel{name: pos})
+	e.goPosMu.Lock()
+	defer e.goPosMu.Unlock()
+	if e.goPos == nil {
+		e.goPos = make(map[string]*posResult)
+	}
+	r := e.goPos[pos]
+	if r == nil {
+		r = &posResult{}
+		e.goPos[pos] = r
+	}
+	r.active++
+	return e
+}
+
+// FinalizeGoroutineAt is called when a goroutine started with InitGoroutineAt quits.
+func FinalizeGoroutineAt(e *ExecutionState, pos string) {
+	r := recover()
+	func() {
+		e.goPosMu.Lock()
+		defer e.goPosMu.Unlock()
+		if res := e.goPos[pos]; res != nil {
+			res.active--
+			if r != nil && r != Bailout {
+				res.fail++
+			}
+		}
+	}()
+	e.finalizeGoroutine(r, goroutineLabel{name: pos})
+}
+
+// goroutinePositions returns the suffixes of the messages of failed and hanging goroutines
+// which list the positions where the goroutines started (e.g. " (started at cell1.go:42)").
+func (e *ExecutionState) goroutinePositions() (failed, hanging string) {
+	e.goPosMu.Lock()
+	defer e.goPosMu.Unlock()
+	var fails, actives []string
+	for pos, r := range e.goPos {
+		if r.fail > 0 {
+			fails = append(fails, pos)
+		}
+		if r.active > 0 {
+			actives = append(actives, pos)
+		}
+	}
+	return positionsSuffix(fails), positionsSuffix(actives)
+}
+
+func positionsSuffix(positions []string) string {
+	if len(positions) == 0 {
+		return ""
+	}
+	sort.Strings(positions)
+	return " (started at " + strings.Join(positions, ", ") + ")"
+}
@@ -0,0 +1,38 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestInitGoroutineAt(t *testing.T) {
+	defer setExecWaitDuration(setExecWaitDuration(10 * time.Millisecond))
+	atomic.StoreUint32(&isRunning, 0)
+	release := make(chan struct{})
+	defer close(release)
+	e := startExec(LgoContext{Context: context.Background()}, func() {
+		s0 := InitGoroutineAt("cell1.go:3")
+		go func() {
+			defer FinalizeGoroutineAt(s0, "cell1.go:3")
+			panic("fail")
+		}()
+		for i := 0; i < 2; i++ {
+			s := InitGoroutineAt("cell1.go:42")
+			go func() {
+				defer FinalizeGoroutineAt(s, "cell1.go:42")
+				<-release
+			}()
+		}
+		s1 := InitGoroutineAt("cell1.go:50")
+		go func() {
+			defer FinalizeGoroutineAt(s1, "cell1.go:50")
+		}()
+	})
+	err := finalizeExec(e)
+	want := "1 goroutine failed (started at cell1.go:3), 2 goroutines are hanging (started at cell1.go:42)"
+	if err == nil || err.Error() != want {
+		t.Errorf("Got %v; want %q", err, want)
+	}
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// GoroutineInfo describes a goroutine started with Go.
+type GoroutineInfo struct {
+	ID      int
+	Name    string
+	Started time.Time
+}
+
+type trackedGoroutine struct {
+	info   GoroutineInfo
+	cancel context.CancelFunc
+}
+
+// Go starts fn in a new goroutine managed by lgo and returns the ID of the goroutine.
+// fn receives a context derived from the current execution context which is canceled when the execution
+// is canceled or the goroutine is killed by KillGoroutine. fn should exit when ctx is done.
+// Go does not start fn and returns 0 if lgo does not execute any code blocks.
+func Go(name string, fn func(ctx LgoContext)) int {
+	e := getExecState()
+	if e == nil {
+		return 0
+	}
+	ctx, cancel := lgoCtxWithCancel(e.Context)
+	id, started := e.addGoroutine(name, cancel)
+	l := goroutineLabel{id: id, name: name, start: started}
+	e.initGoroutine(l)
+	go func() {
+		defer func() {
+			e.finalizeGoroutine(recover(), l)
+		}()
+		defer e.removeGoroutine(id)
+		fn(ctx)
+	}()
+	return id
+}
+
+// addGoroutine tracks a goroutine and returns its ID and the time when it started.
+func (e *ExecutionState) addGoroutine(name string, cancel context.CancelFunc) (int, time.Time) {
+	e.goMu.Lock()
+	defer e.goMu.Unlock()
+	if e.goroutines == nil {
+		e.goroutines = make(map[int]*trackedGoroutine)
+	}
+	e.goSeq++
+	g := &trackedGoroutine{
+		info: GoroutineInfo{
+			ID:      e.goSeq,
+			Name:    name,
+			Started: time.Now(),
+		},
+		cancel: cancel,
+	}
+	e.goroutines[e.goSeq] = g
+	return e.goSeq, g.info.Started
+}
+
+func (e *ExecutionState) removeGoroutine(id int) {
+	e.goMu.Lock()
+	defer e.goMu.Unlock()
+	if g := e.goroutines[id]; g != nil {
+		// Release the resources of the context.
+		g.cancel()
+		delete(e.goroutines, id)
+	}
+}
+
+// Goroutines returns goroutines started with Go in the current execution which are still running.
+// The result is sorted by ID.
+func Goroutines() []GoroutineInfo {
+	e := getExecState()
+	if e == nil {
+		return nil
+	}
+	e.goMu.Lock()
+	defer e.goMu.Unlock()
+	var infos []GoroutineInfo
+	for _, g := range e.goroutines {
+		infos = append(infos, g.info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+// KillGoroutine cancels the context of the goroutine started with Go in the current execution.
+// It returns an error if the goroutine with id is unknown or already finished.
+func KillGoroutine(id int) error {
+	e := getExecState()
+	if e == nil {
+		return fmt.Errorf("goroutine %d is not found: lgo does not execute any code blocks", id)
+	}
+	e.goMu.Lock()
+	defer e.goMu.Unlock()
+	g := e.goroutines[id]
+	if g == nil {
+		return fmt.Errorf("goroutine %d is not found or already finished", id)
+	}
+	g.cancel()
+	return nil
+}
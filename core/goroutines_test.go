@@ -0,0 +1,59 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestKillGoroutine(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	started := make(chan struct{})
+	killed := make(chan int)
+	var ids []int
+	var infos []GoroutineInfo
+	var remaining []GoroutineInfo
+	var killErr, unknownErr error
+	mainDone := make(chan struct{})
+	state := startExec(LgoContext{Context: context.Background()}, func() {
+		for _, name := range []string{"a", "b", "c"} {
+			id := Go(name, func(ctx LgoContext) {
+				started <- struct{}{}
+				<-ctx.Done()
+				killed <- 0
+			})
+			ids = append(ids, id)
+		}
+		for range ids {
+			<-started
+		}
+		infos = Goroutines()
+		killErr = KillGoroutine(ids[1])
+		<-killed
+		// Wait until the state is updated.
+		for len(Goroutines()) != 2 {
+		}
+		remaining = Goroutines()
+		unknownErr = KillGoroutine(ids[1])
+		close(mainDone)
+	})
+	<-mainDone
+	if len(infos) != 3 || infos[0].Name != "a" || infos[1].Name != "b" || infos[2].Name != "c" {
+		t.Errorf("Unexpected goroutines: %v", infos)
+	}
+	if killErr != nil {
+		t.Errorf("Failed to kill: %v", killErr)
+	}
+	if len(remaining) != 2 || remaining[0].ID != ids[0] || remaining[1].ID != ids[2] {
+		t.Errorf("Unexpected remaining goroutines: %v", remaining)
+	}
+	if unknownErr == nil {
+		t.Error("KillGoroutine for a finished goroutine succeeded unexpectedly")
+	}
+	state.cancel()
+	<-killed
+	<-killed
+	if err := finalizeExec(state); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// goroutineGuardInterval is the interval to sample the number of goroutines.
+const goroutineGuardInterval = 10 * time.Millisecond
+
+// goroutineLimitReason is the cancel reason of executions canceled by the goroutine explosion guard.
+const goroutineLimitReason = "goroutine limit exceeded"
+
+// goroutineLimit is the limit set by SetGoroutineExplosionGuard.
+// To access this var, use atomic.Store/LoadInt64.
+var goroutineLimit int64
+
+// SetGoroutineExplosionGuard cancels executions which create more than limit goroutines,
+// including the main routine, to protect the kernel from cells which start goroutines endlessly.
+// The number of goroutines is sampled periodically with runtime.NumGoroutine, so an execution may
+// exceed limit briefly before it is canceled. The guard is disabled if limit is not positive (the default).
+func SetGoroutineExplosionGuard(limit int) {
+	atomic.StoreInt64(&goroutineLimit, int64(limit))
+}
+
+// startGoroutineGuard starts the sampler of the goroutine explosion guard for e if the guard is enabled.
+// This must be called before the main routine of e starts.
+func (e *ExecutionState) startGoroutineGuard() {
+	limit := int(atomic.LoadInt64(&goroutineLimit))
+	if limit <= 0 {
+		return
+	}
+	base := runtime.NumGoroutine()
+	e.guardStop = make(chan struct{})
+	e.guardDone = make(chan struct{})
+	go func() {
+		defer close(e.guardDone)
+		ticker := time.NewTicker(goroutineGuardInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.guardStop:
+				return
+			case <-ticker.C:
+			}
+			// Don't count the sampler itself.
+			if runtime.NumGoroutine()-base-1 > limit {
+				e.cancelWithReason(goroutineLimitReason)
+				return
+			}
+		}
+	}()
+}
+
+// stopGoroutineGuard stops the sampler started by startGoroutineGuard.
+func (e *ExecutionState) stopGoroutineGuard() {
+	if e.guardStop == nil {
+		return
+	}
+	close(e.guardStop)
+	<-e.guardDone
+	e.guardStop = nil
+}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGoroutineExplosionGuard(t *testing.T) {
+	defer SetGoroutineExplosionGuard(0)
+	SetGoroutineExplosionGuard(10)
+	atomic.StoreUint32(&isRunning, 0)
+	r, err := ExecLgoEntryPointReport(LgoContext{Context: context.Background()}, func() {
+		for i := 0; ; i++ {
+			state := InitGoroutine()
+			go func() {
+				defer FinalizeGoroutine(state)
+				<-state.Context.Done()
+				panic(Bailout)
+			}()
+			if i == 20 {
+				<-GetExecContext().Done()
+				panic(Bailout)
+			}
+		}
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "canceled: goroutine limit exceeded, main routine canceled") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if r.CancelReason != "goroutine limit exceeded" {
+		t.Errorf("Got %q; want %q", r.CancelReason, "goroutine limit exceeded")
+	}
+}
+
+func TestGoroutineExplosionGuardUnderLimit(t *testing.T) {
+	defer SetGoroutineExplosionGuard(0)
+	SetGoroutineExplosionGuard(10)
+	atomic.StoreUint32(&isRunning, 0)
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		for i := 0; i < 5; i++ {
+			state := InitGoroutine()
+			go func() {
+				defer FinalizeGoroutine(state)
+			}()
+		}
+	}); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,205 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"sync/atomic"
+)
+
+const (
+	// heatmapMaxWidth is the maximum width of the cells of heatmaps.
+	heatmapMaxWidth = 600
+	heatmapMaxCell  = 40
+	heatmapMinCell  = 8
+	// heatmapLegendWidth is the space for the legend on the right of cells.
+	heatmapLegendWidth = 70
+	heatmapMinLegend   = 60
+	// heatmapCharWidth is the estimated width of characters of labels.
+	heatmapCharWidth = 6
+)
+
+// heatmapStops is the color scale of heatmaps (viridis) from the minimum to the maximum.
+var heatmapStops = [][3]float64{
+	{0x44, 0x01, 0x54},
+	{0x3b, 0x52, 0x8b},
+	{0x21, 0x91, 0x8c},
+	{0x5e, 0xc9, 0x62},
+	{0xfd, 0xe7, 0x25},
+}
+
+// heatmapSeq makes IDs of gradients in heatmaps unique in a notebook. Use atomic.AddInt64.
+var heatmapSeq int64
+
+// heatmapRGB returns the color of t in [0, 1] on the color scale.
+func heatmapRGB(t float64) [3]float64 {
+	t = math.Max(0, math.Min(1, t))
+	pos := t * float64(len(heatmapStops)-1)
+	i := int(pos)
+	if i >= len(heatmapStops)-1 {
+		return heatmapStops[len(heatmapStops)-1]
+	}
+	frac := pos - float64(i)
+	var c [3]float64
+	for k := range c {
+		c[k] = heatmapStops[i][k] + (heatmapStops[i+1][k]-heatmapStops[i][k])*frac
+	}
+	return c
+}
+
+// heatmapColor returns the CSS color of t in [0, 1] on the color scale.
+func heatmapColor(t float64) string {
+	c := heatmapRGB(t)
+	return fmt.Sprintf("#%02x%02x%02x", int(math.Round(c[0])), int(math.Round(c[1])), int(math.Round(c[2])))
+}
+
+// heatmapTextColor returns the color of texts readable on the color of t.
+func heatmapTextColor(t float64) string {
+	c := heatmapRGB(t)
+	// The relative luminance of sRGB approximated without gamma correction.
+	if 0.2126*c[0]+0.7152*c[1]+0.0722*c[2] > 140 {
+		return "#000"
+	}
+	return "#fff"
+}
+
+// checkHeatmap validates the dimensions of matrix and labels.
+func checkHeatmap(matrix [][]float64, rowLabels, colLabels []string) error {
+	if len(matrix) == 0 || len(matrix[0]) == 0 {
+		return errors.New("matrix is empty")
+	}
+	cols := len(matrix[0])
+	for i, row := range matrix {
+		if len(row) != cols {
+			return fmt.Errorf("matrix is not rectangular: row %d has %d columns; want %d", i, len(row), cols)
+		}
+	}
+	if rowLabels != nil && len(rowLabels) != len(matrix) {
+		return fmt.Errorf("got %d row labels for %d rows", len(rowLabels), len(matrix))
+	}
+	if colLabels != nil && len(colLabels) != cols {
+		return fmt.Errorf("got %d column labels for %d columns", len(colLabels), cols)
+	}
+	return nil
+}
+
+// maxLabelLen returns the length of the longest label in runes.
+func maxLabelLen(labels []string) int {
+	n := 0
+	for _, l := range labels {
+		if c := len([]rune(l)); c > n {
+			n = c
+		}
+	}
+	return n
+}
+
+// DisplayHeatmap displays matrix (e.g. a confusion matrix) as a heatmap in SVG with a legend of the color scale.
+// matrix must be rectangular. rowLabels and colLabels label the rows and the columns. They can be nil
+// to omit labels. Otherwise, their lengths must match the dimensions of matrix.
+// NaN and infinite values are drawn in gray and excluded from the color scale.
+func DisplayHeatmap(d DataDisplayer, matrix [][]float64, rowLabels, colLabels []string, id *string) error {
+	if err := checkHeatmap(matrix, rowLabels, colLabels); err != nil {
+		return err
+	}
+	d.SVG(heatmapSVG(matrix, rowLabels, colLabels), id)
+	return nil
+}
+
+// heatmapSVG renders the heatmap of the valid matrix.
+func heatmapSVG(matrix [][]float64, rowLabels, colLabels []string) string {
+	rows, cols := len(matrix), len(matrix[0])
+	minV, maxV := math.Inf(1), math.Inf(-1)
+	for _, row := range matrix {
+		for _, v := range row {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			minV, maxV = math.Min(minV, v), math.Max(maxV, v)
+		}
+	}
+	valid := minV <= maxV
+	scale := func(v float64) float64 {
+		if maxV == minV {
+			return 0.5
+		}
+		return (v - minV) / (maxV - minV)
+	}
+
+	cell := heatmapMaxWidth / cols
+	if cell > heatmapMaxCell {
+		cell = heatmapMaxCell
+	}
+	if cell < heatmapMinCell {
+		cell = heatmapMinCell
+	}
+	left := 4
+	if rowLabels != nil {
+		left += maxLabelLen(rowLabels)*heatmapCharWidth + 4
+	}
+	top := 4
+	if colLabels != nil {
+		// Column labels are rotated by 45 degrees.
+		top += int(float64(maxLabelLen(colLabels)*heatmapCharWidth)*math.Sqrt2/2) + 10
+	}
+	width := left + cols*cell + heatmapLegendWidth
+	height := top + rows*cell + 4
+	if h := top + heatmapMinLegend + 4; height < h {
+		height = h
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg width="%d" height="%d" xmlns="http://www.w3.org/2000/svg" font-size="10" font-family="sans-serif">`, width, height)
+	for r, row := range matrix {
+		for c, v := range row {
+			x, y := left+c*cell, top+r*cell
+			fill, text := "#ddd", "#000"
+			if !math.IsNaN(v) && !math.IsInf(v, 0) {
+				fill, text = heatmapColor(scale(v)), heatmapTextColor(scale(v))
+			}
+			title := fmt.Sprintf("[%d, %d]", r, c)
+			if rowLabels != nil && colLabels != nil {
+				title = rowLabels[r] + ", " + colLabels[c]
+			}
+			fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"><title>%s: %g</title></rect>`,
+				x, y, cell, cell, fill, html.EscapeString(title), v)
+			if cell >= 30 {
+				fmt.Fprintf(&buf, `<text x="%d" y="%d" text-anchor="middle" dominant-baseline="central" fill="%s">%.3g</text>`,
+					x+cell/2, y+cell/2, text, v)
+			}
+		}
+	}
+	for r, l := range rowLabels {
+		fmt.Fprintf(&buf, `<text x="%d" y="%d" text-anchor="end" dominant-baseline="central">%s</text>`,
+			left-4, top+r*cell+cell/2, html.EscapeString(l))
+	}
+	for c, l := range colLabels {
+		x, y := left+c*cell+cell/2, top-4
+		fmt.Fprintf(&buf, `<text x="%d" y="%d" transform="rotate(-45 %d %d)">%s</text>`, x, y, x, y, html.EscapeString(l))
+	}
+	if valid {
+		heatmapLegend(&buf, left+cols*cell+10, top, rows*cell, minV, maxV)
+	}
+	buf.WriteString("</svg>")
+	return buf.String()
+}
+
+// heatmapLegend renders the legend of the color scale from minV to maxV at (x, y).
+func heatmapLegend(buf *bytes.Buffer, x, y, height int, minV, maxV float64) {
+	if height < heatmapMinLegend {
+		height = heatmapMinLegend
+	}
+	gid := fmt.Sprintf("lgo-heatmap-%d", atomic.AddInt64(&heatmapSeq, 1))
+	// The gradient goes from the maximum at the top to the minimum at the bottom.
+	fmt.Fprintf(buf, `<defs><linearGradient id="%s" x1="0" y1="1" x2="0" y2="0">`, gid)
+	for i := range heatmapStops {
+		t := float64(i) / float64(len(heatmapStops)-1)
+		fmt.Fprintf(buf, `<stop offset="%g" stop-color="%s"/>`, t, heatmapColor(t))
+	}
+	buf.WriteString(`</linearGradient></defs>`)
+	fmt.Fprintf(buf, `<rect x="%d" y="%d" width="12" height="%d" fill="url(#%s)" stroke="#ccc"/>`, x, y, height, gid)
+	fmt.Fprintf(buf, `<text x="%d" y="%d" dominant-baseline="hanging">%.4g</text>`, x+16, y, maxV)
+	fmt.Fprintf(buf, `<text x="%d" y="%d">%.4g</text>`, x+16, y+height, minV)
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestHeatmapColor(t *testing.T) {
+	for _, tc := range []struct {
+		t    float64
+		want string
+	}{
+		{0, "#440154"},
+		{1, "#fde725"},
+		{0.5, "#21918c"},
+		{-1, "#440154"},
+		{2, "#fde725"},
+		{0.125, "#402a70"},
+	} {
+		if got := heatmapColor(tc.t); got != tc.want {
+			t.Errorf("Got %q; want %q for %v", got, tc.want, tc.t)
+		}
+	}
+	if got := heatmapTextColor(0); got != "#fff" {
+		t.Errorf("Got %q; want #fff", got)
+	}
+	if got := heatmapTextColor(1); got != "#000" {
+		t.Errorf("Got %q; want #000", got)
+	}
+}
+
+func TestDisplayHeatmap(t *testing.T) {
+	var d fakeDisplayer
+	id := ""
+	matrix := [][]float64{{5, 1}, {0, math.NaN()}}
+	if err := DisplayHeatmap(&d, matrix, []string{"cat", "<dog>"}, []string{"pred cat", "pred dog"}, &id); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.contents) != 1 || d.contents[0].contentType != "image/svg+xml" || id != "id1" {
+		t.Fatalf("Unexpected outputs: %v (id = %q)", d.contents, id)
+	}
+	svg := d.contents[0].content.(string)
+	for _, want := range []string{
+		`fill="#fde725"><title>cat, pred cat: 5</title>`,
+		`fill="#440154"><title>&lt;dog&gt;, pred cat: 0</title>`,
+		`fill="#ddd"><title>&lt;dog&gt;, pred dog: NaN</title>`,
+		`>&lt;dog&gt;</text>`,
+		`transform="rotate(-45`,
+		`<linearGradient id="lgo-heatmap-`,
+		`>5</text>`,
+		`>0</text>`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("%q is not in %s", want, svg)
+		}
+	}
+}
+
+func TestDisplayHeatmapNoLabels(t *testing.T) {
+	var d fakeDisplayer
+	if err := DisplayHeatmap(&d, [][]float64{{1, 1, 1}}, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	svg := d.contents[0].content.(string)
+	// Constant matrices are drawn in the middle color.
+	if !strings.Contains(svg, `fill="#21918c"><title>[0, 2]: 1</title>`) || strings.Contains(svg, "rotate") {
+		t.Errorf("Unexpected output: %s", svg)
+	}
+}
+
+func TestDisplayHeatmapError(t *testing.T) {
+	var d fakeDisplayer
+	for _, tc := range []struct {
+		matrix [][]float64
+		rows   []string
+		cols   []string
+		msg    string
+	}{
+		{nil, nil, nil, "matrix is empty"},
+		{[][]float64{{}}, nil, nil, "matrix is empty"},
+		{[][]float64{{1, 2}, {3}}, nil, nil, "matrix is not rectangular: row 1 has 1 columns; want 2"},
+		{[][]float64{{1, 2}}, []string{"a", "b"}, nil, "got 2 row labels for 1 rows"},
+		{[][]float64{{1, 2}}, nil, []string{"a"}, "got 1 column labels for 2 columns"},
+	} {
+		err := DisplayHeatmap(&d, tc.matrix, tc.rows, tc.cols, nil)
+		if err == nil || err.Error() != tc.msg {
+			t.Errorf("Got %v; want %q", err, tc.msg)
+		}
+	}
+	if len(d.contents) != 0 {
+		t.Errorf("Unexpected outputs: %v", d.contents)
+	}
+}
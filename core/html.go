@@ -0,0 +1,9 @@
+package core
+
+import "html"
+
+// DisplayPre displays s as preformatted text in an HTML <pre> element.
+// Unlike HTML, s is escaped so that whitespaces in s are preserved but HTML tags in s are not interpreted.
+func DisplayPre(d DataDisplayer, s string, id *string) {
+	d.HTML("<pre>"+html.EscapeString(s)+"</pre>", id)
+}
@@ -0,0 +1,24 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestDisplayPre(t *testing.T) {
+	var d fakeDisplayer
+	id := ""
+	DisplayPre(&d, "if a < b && c > d {\n\treturn\n}", &id)
+	DisplayPre(&d, "<script>", &id)
+	want := []displayed{
+		{"text/html", "<pre>if a &lt; b &amp;&amp; c &gt; d {\n\treturn\n}</pre>", "id1"},
+		{"text/html", "<pre>&lt;script&gt;</pre>", "id1"},
+	}
+	if len(d.contents) != len(want) {
+		t.Fatalf("Got %d contents; want %d", len(d.contents), len(want))
+	}
+	for i, c := range d.contents {
+		if c != want[i] {
+			t.Errorf("Got %v; want %v", c, want[i])
+		}
+	}
+}
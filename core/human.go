@@ -0,0 +1,78 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// byteUnits are the units of HumanBytes. The size of byteUnits[i] is 1000^(i+1) bytes.
+var byteUnits = []string{"KB", "MB", "GB", "TB", "PB", "EB"}
+
+// HumanBytes formats n bytes with decimal (SI) units rounded to one decimal place (e.g. "512 B" and "1.2 GB").
+func HumanBytes(n uint64) string {
+	if n < 1000 {
+		return fmt.Sprintf("%d B", n)
+	}
+	size := uint64(1)
+	for i, unit := range byteUnits {
+		size *= 1000
+		last := i == len(byteUnits)-1
+		if !last && n >= size*1000 {
+			continue
+		}
+		tenths := n/(size/10) + (n%(size/10)+size/20)/(size/10)
+		if tenths >= 10000 && !last {
+			// Rounded up to 1000 (e.g. 999.96 KB). Use the next unit.
+			continue
+		}
+		return fmt.Sprintf("%d.%d %s", tenths/10, tenths%10, unit)
+	}
+	panic("unreachable")
+}
+
+// durationUnits are the units of HumanDuration shorter than a minute. limit is the duration from which
+// the next unit is used.
+var durationUnits = []struct {
+	size, limit time.Duration
+	suffix      string
+}{
+	{time.Microsecond, time.Millisecond, "µs"},
+	{time.Millisecond, time.Second, "ms"},
+	{time.Second, time.Minute, "s"},
+}
+
+// HumanDuration formats d rounded for humans: nanoseconds as integers, microseconds to seconds
+// with one decimal place (e.g. "4.2s"), minutes with seconds (e.g. "4m12s") and hours with minutes
+// (e.g. "2h5m"). Negative durations are prefixed with "-".
+func HumanDuration(d time.Duration) string {
+	if d < 0 {
+		// uint64(-d) is correct even if d is math.MinInt64.
+		return "-" + humanDuration(uint64(-d))
+	}
+	return humanDuration(uint64(d))
+}
+
+// humanDuration formats the duration of n nanoseconds for HumanDuration.
+func humanDuration(n uint64) string {
+	if n < uint64(time.Microsecond) {
+		return fmt.Sprintf("%dns", n)
+	}
+	for _, u := range durationUnits {
+		size, limit := uint64(u.size), uint64(u.limit)
+		if n >= limit {
+			continue
+		}
+		tenths := (n + size/20) / (size / 10)
+		if tenths >= limit/size*10 {
+			// Rounded up to the next unit (e.g. 999.96ms).
+			continue
+		}
+		return fmt.Sprintf("%d.%d%s", tenths/10, tenths%10, u.suffix)
+	}
+	sec := n/uint64(time.Second) + (n%uint64(time.Second)+uint64(time.Second)/2)/uint64(time.Second)
+	if sec < 3600 {
+		return fmt.Sprintf("%dm%ds", sec/60, sec%60)
+	}
+	min := (sec + 30) / 60
+	return fmt.Sprintf("%dh%dm", min/60, min%60)
+}
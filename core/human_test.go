@@ -0,0 +1,63 @@
+package core
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHumanBytes(t *testing.T) {
+	tests := []struct {
+		n    uint64
+		want string
+	}{
+		{0, "0 B"},
+		{999, "999 B"},
+		{1000, "1.0 KB"},
+		{1049, "1.0 KB"},
+		{1050, "1.1 KB"},
+		{999949, "999.9 KB"},
+		{999950, "1.0 MB"},
+		{1234567, "1.2 MB"},
+		{1200000000, "1.2 GB"},
+		{5e12, "5.0 TB"},
+		{999999999999999, "1.0 PB"},
+		{math.MaxUint64, "18.4 EB"},
+	}
+	for _, tc := range tests {
+		if got := HumanBytes(tc.n); got != tc.want {
+			t.Errorf("HumanBytes(%d): Got %q; want %q", tc.n, got, tc.want)
+		}
+	}
+}
+
+func TestHumanDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0ns"},
+		{999, "999ns"},
+		{1000, "1.0µs"},
+		{1550, "1.6µs"},
+		{999949, "999.9µs"},
+		{999950, "1.0ms"},
+		{42 * time.Millisecond, "42.0ms"},
+		{4200 * time.Millisecond, "4.2s"},
+		{4249 * time.Millisecond, "4.2s"},
+		{59960 * time.Millisecond, "1m0s"},
+		{4*time.Minute + 12*time.Second + 400*time.Millisecond, "4m12s"},
+		{59*time.Minute + 59*time.Second + 600*time.Millisecond, "1h0m"},
+		{2*time.Hour + 5*time.Minute + 10*time.Second, "2h5m"},
+		{100 * time.Hour, "100h0m"},
+		{-4200 * time.Millisecond, "-4.2s"},
+		{-999, "-999ns"},
+		{math.MinInt64, "-2562047h47m"},
+		{math.MaxInt64, "2562047h47m"},
+	}
+	for _, tc := range tests {
+		if got := HumanDuration(tc.d); got != tc.want {
+			t.Errorf("HumanDuration(%d): Got %q; want %q", int64(tc.d), got, tc.want)
+		}
+	}
+}
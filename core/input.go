@@ -0,0 +1,59 @@
+package core
+
+import "errors"
+
+// InputRequester is the interface that requests inputs from users with the input_request message
+// of Jupyter[1]. RequestInput shows prompt and returns the text entered by the user.
+// If password is true, the text is not echoed.
+//
+// References:
+// [1] http://jupyter-client.readthedocs.io/en/latest/messaging.html#messages-on-the-stdin-router-dealer-channel
+type InputRequester interface {
+	RequestInput(prompt string, password bool) (string, error)
+}
+
+// errInputUnsupported is returned from Input when the execution has no InputRequester.
+var errInputUnsupported = errors.New("input is not supported by the front-end")
+
+// Input shows prompt and returns the text entered by the user with the InputRequester of the current execution.
+// It returns Bailout when the current code execution is canceled and an error if the front-end
+// does not support inputs.
+//
+// Note that requests can not be withdrawn. When the execution is canceled, Input returns without
+// waiting for the reply and the reply is discarded.
+func Input(prompt string) (string, error) {
+	return requestInput(prompt, false)
+}
+
+// InputPassword is Input whose text is not echoed (e.g. passwords).
+func InputPassword(prompt string) (string, error) {
+	return requestInput(prompt, true)
+}
+
+func requestInput(prompt string, password bool) (string, error) {
+	ctx := GetExecContext()
+	select {
+	case <-ctx.Done():
+		return "", Bailout
+	default:
+	}
+	if ctx.Input == nil {
+		return "", errInputUnsupported
+	}
+	type reply struct {
+		s   string
+		err error
+	}
+	// Buffered not to leak the goroutine after the cancellation.
+	c := make(chan reply, 1)
+	go func() {
+		s, err := ctx.Input.RequestInput(prompt, password)
+		c <- reply{s, err}
+	}()
+	select {
+	case r := <-c:
+		return r.s, r.err
+	case <-ctx.Done():
+		return "", Bailout
+	}
+}
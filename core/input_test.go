@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+type fakeRequest struct {
+	prompt   string
+	password bool
+}
+
+// fakeRequester replies to requests with replies in order. If block is not nil, it waits for block to be closed.
+type fakeRequester struct {
+	requests []fakeRequest
+	replies  []string
+	block    chan struct{}
+}
+
+func (r *fakeRequester) RequestInput(prompt string, password bool) (string, error) {
+	r.requests = append(r.requests, fakeRequest{prompt, password})
+	if r.block != nil {
+		<-r.block
+	}
+	if len(r.replies) == 0 {
+		return "", errors.New("no reply")
+	}
+	s := r.replies[0]
+	r.replies = r.replies[1:]
+	return s, nil
+}
+
+func TestInput(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	req := &fakeRequester{replies: []string{"gopher", "secret"}}
+	var name, pass string
+	var nameErr, passErr, lastErr error
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Input: req}, func() {
+		name, nameErr = Input("name: ")
+		pass, passErr = InputPassword("password: ")
+		_, lastErr = Input("more: ")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if name != "gopher" || nameErr != nil || pass != "secret" || passErr != nil {
+		t.Errorf("Got (%q, %v), (%q, %v)", name, nameErr, pass, passErr)
+	}
+	if lastErr == nil || lastErr.Error() != "no reply" {
+		t.Errorf("Got %v; want no reply", lastErr)
+	}
+	want := []fakeRequest{{"name: ", false}, {"password: ", true}, {"more: ", false}}
+	if len(req.requests) != len(want) {
+		t.Fatalf("Got %v; want %v", req.requests, want)
+	}
+	for i := range want {
+		if req.requests[i] != want[i] {
+			t.Errorf("Got %v; want %v", req.requests[i], want[i])
+		}
+	}
+}
+
+func TestInputCancel(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	req := &fakeRequester{block: make(chan struct{})}
+	defer close(req.block)
+	var err error
+	ExecLgoEntryPoint(LgoContext{Context: context.Background(), Input: req}, func() {
+		Go("interrupter", func(ctx LgoContext) {
+			Interrupt()
+		})
+		_, err = Input("name: ")
+	})
+	if err != Bailout {
+		t.Errorf("Got %v; want Bailout", err)
+	}
+	// Canceled executions do not request inputs.
+	if _, err := Input("idle: "); err != Bailout {
+		t.Errorf("Got %v; want Bailout", err)
+	}
+}
+
+func TestInputUnsupported(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var err error
+	ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		_, err = Input("name: ")
+	})
+	if err != errInputUnsupported {
+		t.Errorf("Got %v; want %v", err, errInputUnsupported)
+	}
+}
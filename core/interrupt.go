@@ -0,0 +1,13 @@
+package core
+
+// interruptedReason is the cancel reason of executions interrupted by users.
+const interruptedReason = "interrupted"
+
+// Interrupt cancels the code execution which is running currently like an interruption from users (e.g. Ctrl-C).
+// If the execution is nested, the outer executions are also canceled. Interrupt does nothing if lgo does
+// not execute any code blocks. Interrupt can be called from any goroutines (e.g. a handler of control messages).
+func Interrupt() {
+	for e := getExecState(); e != nil; e = e.outer {
+		e.cancelWithReason(interruptedReason)
+	}
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestInterrupt(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	// Nothing happens.
+	Interrupt()
+
+	started := make(chan struct{})
+	go func() {
+		<-started
+		Interrupt()
+	}()
+	start := time.Now()
+	r, err := ExecLgoEntryPointReport(LgoContext{Context: context.Background()}, func() {
+		close(started)
+		for {
+			ExitIfCtxDone()
+			time.Sleep(time.Millisecond)
+		}
+	})
+	if want := "main routine canceled"; err == nil || err.Error() != want {
+		t.Errorf("Got %v; want %q", err, want)
+	}
+	if r.CancelReason != "interrupted" {
+		t.Errorf("Got %q; want %q", r.CancelReason, "interrupted")
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("The interruption took %v", d)
+	}
+}
+
+func TestInterruptNested(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var inner *ExecReport
+	outer, _ := ExecLgoEntryPointReport(LgoContext{Context: context.Background()}, func() {
+		inner, _ = ExecLgoEntryPointReport(LgoContext{Context: context.Background()}, func() {
+			Interrupt()
+			ExitIfCtxDone()
+		})
+		ExitIfCtxDone()
+		t.Error("The outer execution is not canceled")
+	})
+	if inner.CancelReason != "interrupted" || outer.CancelReason != "interrupted" {
+		t.Errorf("Got (%q, %q); want (interrupted, interrupted)", inner.CancelReason, outer.CancelReason)
+	}
+}
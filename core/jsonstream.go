@@ -0,0 +1,99 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// errJSONStreamClosed is returned from JSONStreamWriter.Append after Close.
+var errJSONStreamClosed = errors.New("JSONStreamWriter is closed")
+
+// JSONStreamWriter displays a JSON array as application/json and updates the array in place
+// when values are appended so that results accumulating in a cell are shown live.
+// Renderings are throttled like MetricsPanel. JSONStreamWriter is created with NewJSONStreamWriter.
+// The methods of JSONStreamWriter are safe for concurrent use.
+type JSONStreamWriter struct {
+	d  DataDisplayer
+	th throttler
+	// id is the display ID of the array. It is accessed only in render after NewJSONStreamWriter returns.
+	id string
+
+	mu sync.Mutex
+	// items are the marshaled values joined with commas.
+	items  bytes.Buffer
+	closed bool
+	// err is the error of the latest rendering.
+	err error
+}
+
+// NewJSONStreamWriter returns a new JSONStreamWriter which displays an empty JSON array with d.
+// id follows the semantics of DataDisplayer: if id points an empty string, the reserved ID of
+// the array is stored to id, and if id points a display ID, the display is overwritten with the array.
+func NewJSONStreamWriter(d DataDisplayer, id *string) *JSONStreamWriter {
+	w := &JSONStreamWriter{d: d}
+	if id != nil {
+		w.id = *id
+	}
+	w.th.render = w.render
+	w.th.interval = defaultMetricsInterval
+	w.th.update()
+	if id != nil {
+		*id = w.id
+	}
+	return w
+}
+
+// SetInterval sets the minimum interval between renderings. The default is 100ms.
+// If interval is not positive, the array is rendered on every update.
+func (w *JSONStreamWriter) SetInterval(interval time.Duration) {
+	w.th.setInterval(interval)
+}
+
+// Append appends v marshaled with encoding/json to the array. If v can not be marshaled,
+// Append returns the error and the array is not changed. Append returns an error after Close.
+func (w *JSONStreamWriter) Append(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return errJSONStreamClosed
+	}
+	if w.items.Len() > 0 {
+		w.items.WriteByte(',')
+	}
+	w.items.Write(b)
+	w.mu.Unlock()
+	w.th.update()
+	return nil
+}
+
+// Close renders the latest array immediately if a rendering is postponed by the throttling
+// and disallows further appends. It returns the error of the latest rendering.
+func (w *JSONStreamWriter) Close() error {
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+	w.th.flush()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+func (w *JSONStreamWriter) render() {
+	w.mu.Lock()
+	b := make([]byte, 0, w.items.Len()+2)
+	b = append(b, '[')
+	b = append(b, w.items.Bytes()...)
+	b = append(b, ']')
+	w.mu.Unlock()
+	err := w.d.Raw("application/json", json.RawMessage(b), &w.id)
+	w.mu.Lock()
+	w.err = err
+	w.mu.Unlock()
+}
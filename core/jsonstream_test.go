@@ -0,0 +1,64 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestJSONStreamWriter(t *testing.T) {
+	var d fakeDisplayer
+	id := ""
+	w := NewJSONStreamWriter(&d, &id)
+	if id != "id1" {
+		t.Errorf("Got %q; want %q", id, "id1")
+	}
+	w.SetInterval(0)
+	if err := w.Append(1); err != nil {
+		t.Error(err)
+	}
+	if err := w.Append(map[string]string{"a": "b"}); err != nil {
+		t.Error(err)
+	}
+	if err := w.Append(func() {}); err == nil {
+		t.Error("Append must fail for values which can not be marshaled")
+	}
+	if err := w.Append([]int{2, 3}); err != nil {
+		t.Error(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Error(err)
+	}
+	if err := w.Append(4); err != errJSONStreamClosed {
+		t.Errorf("Got %v; want %v", err, errJSONStreamClosed)
+	}
+	want := []string{`[]`, `[1]`, `[1,{"a":"b"}]`, `[1,{"a":"b"},[2,3]]`}
+	if len(d.contents) != len(want) {
+		t.Fatalf("Got %d contents; want %d", len(d.contents), len(want))
+	}
+	for i, c := range d.contents {
+		b, ok := c.content.(json.RawMessage)
+		if c.contentType != "application/json" || !ok || string(b) != want[i] || c.id != "id1" {
+			t.Errorf("Got %s %v (id: %q); want application/json %s", c.contentType, c.content, c.id, want[i])
+		}
+	}
+}
+
+func TestJSONStreamWriterThrottle(t *testing.T) {
+	var d fakeDisplayer
+	w := NewJSONStreamWriter(&d, nil)
+	w.SetInterval(time.Hour)
+	for i := 0; i < 3; i++ {
+		w.Append(i)
+	}
+	if len(d.contents) != 1 {
+		t.Fatalf("Got %d contents; want 1", len(d.contents))
+	}
+	w.Close()
+	if len(d.contents) != 2 {
+		t.Fatalf("Got %d contents; want 2", len(d.contents))
+	}
+	if got, want := string(d.contents[1].content.(json.RawMessage)), "[0,1,2]"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,28 @@
+package core
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// lastCancelLatency is the CancelLatency of the last finished execution. Use atomic.Load/StoreInt64.
+var lastCancelLatency int64
+
+// LastCancellationLatency returns how long routines of the last finished code execution took to finish
+// after the execution was canceled (e.g. by an interrupt), so that operators can check how responsive
+// code is to interrupts and tune checks of cancellation (e.g. LoopGuard).
+// It is measured from the first cancellation of the execution to the end of the last routine.
+// It returns 0 if the last execution was not canceled or its routines did not finish before
+// the execution finished (e.g. hanging goroutines).
+func LastCancellationLatency() time.Duration {
+	return time.Duration(atomic.LoadInt64(&lastCancelLatency))
+}
+
+// cancelLatency returns the latency of the cancellation of e. e.cancelMu must be held.
+func (e *ExecutionState) cancelLatency() time.Duration {
+	if !e.canceled || e.routinesDone.IsZero() || e.routinesDone.Before(e.cancelStart) {
+		// Not canceled, routines are hanging, or the context was released after routines finished.
+		return 0
+	}
+	return e.routinesDone.Sub(e.cancelStart)
+}
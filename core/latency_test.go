@@ -0,0 +1,52 @@
+package core
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLastCancellationLatency(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	go func() {
+		<-started
+		cancel()
+	}()
+	r, err := ExecLgoEntryPointReport(LgoContext{Context: ctx}, func() {
+		state := InitGoroutine()
+		go func() {
+			defer FinalizeGoroutine(state)
+			for {
+				ExitIfCtxDone()
+				// Yield so that the cancellation is processed on machines with a single CPU.
+				runtime.Gosched()
+			}
+		}()
+		close(started)
+		for {
+			ExitIfCtxDone()
+			time.Sleep(time.Millisecond)
+		}
+	})
+	if err == nil {
+		t.Fatal("The execution was not canceled")
+	}
+	if r.CancelLatency <= 0 || r.CancelLatency > time.Second {
+		t.Errorf("Implausible latency: %v", r.CancelLatency)
+	}
+	if got := LastCancellationLatency(); got != r.CancelLatency {
+		t.Errorf("Got %v; want %v", got, r.CancelLatency)
+	}
+
+	// The latency is not recorded if the execution is not canceled.
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {}); err != nil {
+		t.Fatal(err)
+	}
+	if got := LastCancellationLatency(); got != 0 {
+		t.Errorf("Got %v; want 0", got)
+	}
+}
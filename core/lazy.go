@@ -0,0 +1,30 @@
+package core
+
+import "errors"
+
+// LazyDisplayer is the interface implemented by DataDisplayers of front-ends which request
+// the content of a display when the content is actually shown (lazy MIME bundles).
+// fn is called at most once when the front-end requests the content.
+type LazyDisplayer interface {
+	DisplayLazy(fn func() (mime string, payload interface{}, err error), id *string) error
+}
+
+// errLazyUnsupported is returned from DisplayLazy of DataDisplayers which can not display contents lazily.
+var errLazyUnsupported = errors.New("lazy display is not supported")
+
+// DisplayLazy displays the content computed by fn. If d implements LazyDisplayer, fn is called
+// when the front-end requests the content so that expensive contents are not computed unless they are shown.
+// Otherwise, fn is called immediately and the content is displayed with d.Raw (the fallback).
+// Errors from fn are returned only in the fallback because fn is called after DisplayLazy returns otherwise.
+func DisplayLazy(d DataDisplayer, fn func() (mime string, payload interface{}, err error), id *string) error {
+	if ld, ok := d.(LazyDisplayer); ok {
+		if err := ld.DisplayLazy(fn, id); err != errLazyUnsupported {
+			return err
+		}
+	}
+	mime, payload, err := fn()
+	if err != nil {
+		return err
+	}
+	return d.Raw(mime, payload, id)
+}
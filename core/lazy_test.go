@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+// lazyDisplayer is a fakeDisplayer which keeps functions passed to DisplayLazy.
+type lazyDisplayer struct {
+	fakeDisplayer
+	fns []func() (string, interface{}, error)
+}
+
+func (d *lazyDisplayer) DisplayLazy(fn func() (string, interface{}, error), id *string) error {
+	d.fns = append(d.fns, fn)
+	return nil
+}
+
+func TestDisplayLazy(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var d lazyDisplayer
+	called := 0
+	fn := func() (string, interface{}, error) {
+		called++
+		return "text/html", "<b>x</b>", nil
+	}
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Display: &d}, func() {
+		if err := DisplayLazy(GetExecContext().Display, fn, nil); err != nil {
+			t.Error(err)
+		}
+		// Contents are not displayed lazily in batches.
+		BeginBatch()
+		if err := DisplayLazy(GetExecContext().Display, fn, nil); err != nil {
+			t.Error(err)
+		}
+		EndBatch()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if called != 1 || len(d.fns) != 1 {
+		t.Fatalf("Got (%d, %d); want (1, 1)", called, len(d.fns))
+	}
+	if mime, payload, err := d.fns[0](); mime != "text/html" || payload != "<b>x</b>" || err != nil {
+		t.Errorf("Unexpected result: %q, %v, %v", mime, payload, err)
+	}
+	want := []displayed{{"text/html", "<b>x</b>", ""}}
+	if !reflect.DeepEqual(d.contents, want) {
+		t.Errorf("Got %v; want %v", d.contents, want)
+	}
+}
+
+func TestDisplayLazyFallback(t *testing.T) {
+	var d fakeDisplayer
+	if err := DisplayLazy(&d, func() (string, interface{}, error) {
+		return "text/plain", "a", nil
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := []displayed{{"text/plain", "a", ""}}
+	if !reflect.DeepEqual(d.contents, want) {
+		t.Errorf("Got %v; want %v", d.contents, want)
+	}
+	errFn := errors.New("failed")
+	if err := DisplayLazy(&d, func() (string, interface{}, error) {
+		return "", nil, errFn
+	}, nil); err != errFn {
+		t.Errorf("Got %v; want %v", err, errFn)
+	}
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// OnLingering is the policy for goroutines which are still running when the main routine of an execution finishes.
+type OnLingering int32
+
+const (
+	// LingeringWait waits for the goroutines until they finish or until the execution is canceled.
+	// This is the default.
+	LingeringWait OnLingering = iota
+	// LingeringCancel cancels the execution as soon as the main routine finishes.
+	LingeringCancel
+	// LingeringDetach finishes the execution without waiting for the goroutines. They keep running
+	// in background with the context of the execution and are tracked by DetachedGoroutines.
+	//
+	// Note that ExitIfCtxDone checks the context of the current execution. Detached goroutines
+	// may be stopped at ExitIfCtxDone after a following execution finishes.
+	LingeringDetach
+)
+
+// lingeringPolicy is the policy set by SetLingeringPolicy.
+// To access this var, use atomic.Store/LoadInt32.
+var lingeringPolicy int32
+
+// SetLingeringPolicy sets what lgo does with goroutines which are still running when the main routine
+// of an execution finishes. The policy is applied to executions finalized after this call.
+func SetLingeringPolicy(p OnLingering) {
+	atomic.StoreInt32(&lingeringPolicy, int32(p))
+}
+
+// SetWaitForSubsOnMainExit sets whether executions wait for their goroutines to complete when the main
+// routine finishes normally. If wait is false, the executions are canceled as soon as the main routine
+// finishes and goroutines still running are reported as canceled. This is a shorthand of
+// SetLingeringPolicy(LingeringWait) and SetLingeringPolicy(LingeringCancel), so it replaces the lingering
+// policy set before, including LingeringDetach.
+func SetWaitForSubsOnMainExit(wait bool) {
+	if wait {
+		SetLingeringPolicy(LingeringWait)
+	} else {
+		SetLingeringPolicy(LingeringCancel)
+	}
+}
+
+func getLingeringPolicy() OnLingering {
+	return OnLingering(atomic.LoadInt32(&lingeringPolicy))
+}
+
+// detachedMu protects detached.
+var detachedMu sync.Mutex
+
+// detached keeps executions which finished with LingeringDetach and have running goroutines.
+var detached = make(map[*ExecutionState]bool)
+
+// detach moves e to the background tracking if goroutines of e are running.
+// The goroutines are checked with detachedMu held so that e is not registered after removeDetached
+// is called for the last goroutine.
+func (e *ExecutionState) detach() {
+	detachedMu.Lock()
+	defer detachedMu.Unlock()
+	if !e.hasActiveGoroutines() {
+		return
+	}
+	e.cancelMu.Lock()
+	e.detached = true
+	e.cancelMu.Unlock()
+	detached[e] = true
+}
+
+// isDetached returns whether e finished with LingeringDetach.
+func (e *ExecutionState) isDetached() bool {
+	e.cancelMu.Lock()
+	defer e.cancelMu.Unlock()
+	return e.detached
+}
+
+// removeDetached removes e from the background tracking after all goroutines of e finish.
+func removeDetached(e *ExecutionState) {
+	detachedMu.Lock()
+	defer detachedMu.Unlock()
+	delete(detached, e)
+}
+
+// DetachedGoroutines returns the number of running goroutines of executions finished with LingeringDetach.
+func DetachedGoroutines() int {
+	detachedMu.Lock()
+	defer detachedMu.Unlock()
+	var n int
+	for e := range detached {
+		e.subCounter.mu.Lock()
+		n += int(e.subCounter.active)
+		e.subCounter.mu.Unlock()
+	}
+	return n
+}
+
+// CancelDetachedGoroutines cancels the contexts of executions finished with LingeringDetach.
+func CancelDetachedGoroutines() {
+	detachedMu.Lock()
+	var es []*ExecutionState
+	for e := range detached {
+		es = append(es, e)
+	}
+	detachedMu.Unlock()
+	for _, e := range es {
+		e.cancel()
+	}
+}
+
+// hasActiveGoroutines returns whether goroutines started in e are running.
+func (e *ExecutionState) hasActiveGoroutines() bool {
+	e.subCounter.mu.Lock()
+	defer e.subCounter.mu.Unlock()
+	return e.subCounter.active > 0
+}
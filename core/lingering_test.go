@@ -0,0 +1,178 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLingeringPolicy(t *testing.T) {
+	defer SetLingeringPolicy(LingeringWait)
+
+	tests := []struct {
+		policy   OnLingering
+		message  string
+		reason   string
+		finished bool
+		detached int
+	}{
+		{policy: LingeringWait, finished: true},
+		{policy: LingeringCancel, message: "1 goroutine canceled", reason: "main routine finished", finished: true},
+		{policy: LingeringDetach, detached: 1},
+	}
+	for _, tc := range tests {
+		atomic.StoreUint32(&isRunning, 0)
+		SetLingeringPolicy(tc.policy)
+		release := make(chan struct{})
+		finished := make(chan struct{})
+		r, err := ExecLgoEntryPointReport(LgoContext{Context: context.Background()}, func() {
+			state := InitGoroutine()
+			go func() {
+				defer FinalizeGoroutine(state)
+				defer close(finished)
+				select {
+				case <-release:
+				case <-time.After(50 * time.Millisecond):
+				case <-state.Context.Done():
+					panic(Bailout)
+				}
+			}()
+		})
+		var msg string
+		if err != nil {
+			msg = err.Error()
+		}
+		if msg != tc.message {
+			t.Errorf("policy %d: Got %q; want %q", tc.policy, msg, tc.message)
+		}
+		if r.CancelReason != tc.reason {
+			t.Errorf("policy %d: Got %q; want %q", tc.policy, r.CancelReason, tc.reason)
+		}
+		if r.DetachedGoroutines != tc.detached {
+			t.Errorf("policy %d: Got %d; want %d", tc.policy, r.DetachedGoroutines, tc.detached)
+		}
+		select {
+		case <-finished:
+			if !tc.finished {
+				t.Errorf("policy %d: the goroutine finished before the execution finished", tc.policy)
+			}
+		default:
+			if tc.finished {
+				t.Errorf("policy %d: the execution finished before the goroutine finished", tc.policy)
+			}
+		}
+		if got := DetachedGoroutines(); got != tc.detached {
+			t.Errorf("policy %d: Got %d; want %d", tc.policy, got, tc.detached)
+		}
+		close(release)
+		<-finished
+	}
+}
+
+func TestCancelDetachedGoroutines(t *testing.T) {
+	defer SetLingeringPolicy(LingeringWait)
+	SetLingeringPolicy(LingeringDetach)
+	atomic.StoreUint32(&isRunning, 0)
+	finished := make(chan struct{})
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		state := InitGoroutine()
+		go func() {
+			defer FinalizeGoroutine(state)
+			defer close(finished)
+			<-state.Context.Done()
+		}()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	CancelDetachedGoroutines()
+	<-finished
+	for i := 0; DetachedGoroutines() != 0; i++ {
+		if i >= 100 {
+			t.Fatal("Detached goroutines are not removed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSetWaitForSubsOnMainExit(t *testing.T) {
+	defer SetLingeringPolicy(LingeringWait)
+	for _, wait := range []bool{true, false} {
+		atomic.StoreUint32(&isRunning, 0)
+		SetWaitForSubsOnMainExit(wait)
+		var completed int32
+		r, err := ExecLgoEntryPointReport(LgoContext{Context: context.Background()}, func() {
+			state := InitGoroutine()
+			go func() {
+				defer FinalizeGoroutine(state)
+				select {
+				case <-time.After(50 * time.Millisecond):
+					atomic.StoreInt32(&completed, 1)
+				case <-state.Context.Done():
+					panic(Bailout)
+				}
+			}()
+		})
+		if wait {
+			if err != nil || r.CancelReason != "" {
+				t.Errorf("wait: Got %v (reason: %q); want no error", err, r.CancelReason)
+			}
+			if atomic.LoadInt32(&completed) != 1 {
+				t.Error("wait: the goroutine did not complete")
+			}
+			continue
+		}
+		if err == nil || err.Error() != "1 goroutine canceled" {
+			t.Errorf("cancel: Got %v; want %q", err, "1 goroutine canceled")
+		}
+		if r.CancelReason != "main routine finished" {
+			t.Errorf("cancel: Got %q; want %q", r.CancelReason, "main routine finished")
+		}
+		if atomic.LoadInt32(&completed) != 0 {
+			t.Error("cancel: the goroutine was not canceled")
+		}
+	}
+}
+
+func TestSetWaitForSubsOnMainExitReplacesPolicy(t *testing.T) {
+	defer SetLingeringPolicy(LingeringWait)
+	for _, wait := range []bool{true, false} {
+		SetLingeringPolicy(LingeringDetach)
+		SetWaitForSubsOnMainExit(wait)
+		want := LingeringCancel
+		if wait {
+			want = LingeringWait
+		}
+		if got := getLingeringPolicy(); got != want {
+			t.Errorf("SetWaitForSubsOnMainExit(%v): Got %d; want %d", wait, got, want)
+		}
+	}
+}
+
+func TestDetachRace(t *testing.T) {
+	defer SetLingeringPolicy(LingeringWait)
+	SetLingeringPolicy(LingeringDetach)
+	for i := 0; i < 100; i++ {
+		atomic.StoreUint32(&isRunning, 0)
+		if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+			state := InitGoroutine()
+			// The goroutine finishes around when the main routine finishes.
+			go FinalizeGoroutine(state)
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		detachedMu.Lock()
+		n := len(detached)
+		detachedMu.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d executions are left detached", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
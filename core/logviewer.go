@@ -0,0 +1,130 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultLogViewerInterval is the default minimum interval between renderings of LogViewer.
+	defaultLogViewerInterval = 100 * time.Millisecond
+	// logViewerHeight is the height of LogViewer in pixels.
+	logViewerHeight = 200
+)
+
+// LogViewer displays the last lines of logs in a scrollable area with a fixed height
+// and updates the area in place when lines are appended.
+// Renderings are throttled like MetricsPanel. LogViewer is created with NewLogViewer.
+// The methods of LogViewer are safe for concurrent use.
+type LogViewer struct {
+	d  DataDisplayer
+	th throttler
+	// id is the display ID of the viewer. It is accessed only in render.
+	id string
+
+	mu sync.Mutex
+	// lines is a ring buffer of the last lines. The oldest line is lines[start].
+	lines []string
+	start int
+	n     int
+}
+
+// NewLogViewer returns a new LogViewer which displays the last maxLines lines with d.
+// If maxLines is not positive, 1 line is kept. Nothing is displayed until the first Append.
+func NewLogViewer(d DataDisplayer, maxLines int) *LogViewer {
+	if maxLines < 1 {
+		maxLines = 1
+	}
+	v := &LogViewer{
+		d:     d,
+		lines: make([]string, maxLines),
+	}
+	v.th.render = v.render
+	v.th.interval = defaultLogViewerInterval
+	return v
+}
+
+// SetInterval sets the minimum interval between renderings. The default is 100ms.
+// If interval is not positive, the viewer is rendered on every update.
+func (v *LogViewer) SetInterval(interval time.Duration) {
+	v.th.setInterval(interval)
+}
+
+// Append appends line to the viewer. If line contains newlines, it is split into lines.
+// The oldest lines are dropped when the viewer has more than maxLines lines.
+func (v *LogViewer) Append(line string) {
+	v.mu.Lock()
+	for _, l := range strings.Split(strings.TrimSuffix(line, "\n"), "\n") {
+		v.push(l)
+	}
+	v.mu.Unlock()
+	v.th.update()
+}
+
+// push adds l to the ring buffer. v.mu must be held.
+func (v *LogViewer) push(l string) {
+	if v.n < len(v.lines) {
+		v.lines[(v.start+v.n)%len(v.lines)] = l
+		v.n++
+		return
+	}
+	v.lines[v.start] = l
+	v.start = (v.start + 1) % len(v.lines)
+}
+
+// Lines returns the lines in the viewer from the oldest.
+func (v *LogViewer) Lines() []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.snapshot()
+}
+
+// snapshot returns the lines in the ring buffer. v.mu must be held.
+func (v *LogViewer) snapshot() []string {
+	lines := make([]string, v.n)
+	for i := range lines {
+		lines[i] = v.lines[(v.start+i)%len(v.lines)]
+	}
+	return lines
+}
+
+// Clear removes all lines from the viewer.
+func (v *LogViewer) Clear() {
+	v.mu.Lock()
+	for i := range v.lines {
+		// Release the strings.
+		v.lines[i] = ""
+	}
+	v.start, v.n = 0, 0
+	v.mu.Unlock()
+	v.th.update()
+}
+
+// Flush renders the latest lines immediately if a rendering is postponed by the throttling.
+func (v *LogViewer) Flush() {
+	v.th.flush()
+}
+
+// render displays the lines. The outer flex container in the reversed order keeps the area
+// scrolled to the bottom without JavaScript.
+func (v *LogViewer) render() {
+	v.mu.Lock()
+	lines := v.snapshot()
+	v.mu.Unlock()
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<div class="lgo-log" style="height:%dpx;overflow-y:auto;display:flex;flex-direction:column-reverse;`+
+		`border:1px solid #ccc;padding:2px 4px">`, logViewerHeight)
+	buf.WriteString(`<pre style="margin:0;white-space:pre-wrap">`)
+	for i, l := range lines {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(html.EscapeString(l))
+	}
+	buf.WriteString("</pre></div>")
+	v.d.HTML(buf.String(), &v.id)
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// logViewerHTML returns the HTML of LogViewer with lines.
+func logViewerHTML(lines string) string {
+	return `<div class="lgo-log" style="height:200px;overflow-y:auto;display:flex;flex-direction:column-reverse;` +
+		`border:1px solid #ccc;padding:2px 4px"><pre style="margin:0;white-space:pre-wrap">` + lines + "</pre></div>"
+}
+
+func TestLogViewer(t *testing.T) {
+	var d fakeDisplayer
+	v := NewLogViewer(&d, 3)
+	v.SetInterval(0)
+	v.Append("a")
+	v.Append("b\nc\n")
+	v.Append("<script>alert(1)</script>")
+	if got, want := v.Lines(), []string{"b", "c", "<script>alert(1)</script>"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	v.Clear()
+	v.Append("d")
+	want := []string{
+		logViewerHTML("a"),
+		logViewerHTML("a\nb\nc"),
+		logViewerHTML("b\nc\n&lt;script&gt;alert(1)&lt;/script&gt;"),
+		logViewerHTML(""),
+		logViewerHTML("d"),
+	}
+	if len(d.contents) != len(want) {
+		t.Fatalf("Got %d contents; want %d", len(d.contents), len(want))
+	}
+	for i, c := range d.contents {
+		if c.content != want[i] {
+			t.Errorf("Got %q; want %q", c.content, want[i])
+		}
+		if c.id != "id1" {
+			t.Errorf("Got %q; want %q", c.id, "id1")
+		}
+	}
+}
+
+func TestLogViewerThrottle(t *testing.T) {
+	var d fakeDisplayer
+	v := NewLogViewer(&d, 0)
+	v.SetInterval(time.Hour)
+	for _, l := range []string{"1", "2", "3"} {
+		v.Append(l)
+	}
+	if len(d.contents) != 1 {
+		t.Fatalf("Got %d contents; want 1", len(d.contents))
+	}
+	v.Flush()
+	if len(d.contents) != 2 {
+		t.Fatalf("Got %d contents; want 2", len(d.contents))
+	}
+	// maxLines is at least 1.
+	if got := d.contents[1].content.(string); !strings.Contains(got, `white-space:pre-wrap">3</pre>`) {
+		t.Errorf("Unexpected output: %s", got)
+	}
+}
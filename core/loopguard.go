@@ -0,0 +1,34 @@
+package core
+
+import "sync/atomic"
+
+// loopGuardPeriod is the number of iterations between the slow checks of LoopGuard and LoopGuardN.
+const loopGuardPeriod = 64
+
+// loopGuardCount counts calls of LoopGuard while the slow check is necessary.
+var loopGuardCount uint32
+
+// LoopGuard is a cheaper version of ExitIfCtxDone for loop heads.
+// Like ExitIfCtxDone, it returns immediately while the code execution is running.
+// Otherwise, it checks the context and throws Bailout only once per loopGuardPeriod calls
+// so that loops in goroutines left after executions are not slowed down by the check.
+// The cancellation is delayed by up to 64 iterations.
+func LoopGuard() {
+	if atomic.LoadUint32(&isRunning) == 1 {
+		return
+	}
+	if atomic.AddUint32(&loopGuardCount, 1)%loopGuardPeriod != 0 {
+		return
+	}
+	exitIfCtxDoneSlow()
+}
+
+// LoopGuardN is LoopGuard for loops with an index i. It calls ExitIfCtxDone only if i is
+// a multiple of 64, which costs nothing but a modulo operation in the other iterations.
+// The cancellation is delayed by up to 64 iterations.
+func LoopGuardN(i int) {
+	if i%loopGuardPeriod != 0 {
+		return
+	}
+	ExitIfCtxDone()
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLoopGuard(t *testing.T) {
+	for _, guard := range []func(i int){
+		func(int) { LoopGuard() },
+		LoopGuardN,
+	} {
+		atomic.StoreUint32(&isRunning, 0)
+		var iterations int
+		ctx, cancel := context.WithCancel(context.Background())
+		e := startExec(LgoContext{Context: ctx}, func() {})
+		// Wait for the main routine.
+		<-e.mainDone
+		cancel()
+		<-e.Context.Done()
+		e.cancel()
+		func() {
+			defer func() {
+				if r := recover(); r != Bailout {
+					t.Errorf("Unexpected panic: %v", r)
+				}
+			}()
+			for i := 1; ; i++ {
+				iterations++
+				guard(i)
+				if i > 10*loopGuardPeriod {
+					t.Error("The loop is not interrupted")
+					return
+				}
+			}
+		}()
+		if iterations > loopGuardPeriod {
+			t.Errorf("The loop is interrupted after %d iterations", iterations)
+		}
+		finalizeExec(e)
+	}
+}
+
+func runLoopBench(b *testing.B, guard func(i int)) {
+	atomic.StoreUint32(&isRunning, 1)
+	defer atomic.StoreUint32(&isRunning, 0)
+	for i := 0; i < b.N; i++ {
+		guard(i)
+	}
+}
+
+func BenchmarkExitIfCtxDone(b *testing.B) {
+	runLoopBench(b, func(int) { ExitIfCtxDone() })
+}
+
+func BenchmarkLoopGuard(b *testing.B) {
+	runLoopBench(b, func(int) { LoopGuard() })
+}
+
+func BenchmarkLoopGuardN(b *testing.B) {
+	runLoopBench(b, LoopGuardN)
+}
+
+// BenchmarkExitIfCtxDoneCanceled measures loops in goroutines left after a canceled execution.
+func BenchmarkExitIfCtxDoneCanceled(b *testing.B) {
+	setExecState(nil)
+	atomic.StoreUint32(&isRunning, 0)
+	n := 0
+	for i := 0; i < b.N; i++ {
+		func() {
+			defer func() { recover() }()
+			ExitIfCtxDone()
+			n++
+		}()
+	}
+}
+
+func BenchmarkLoopGuardCanceled(b *testing.B) {
+	setExecState(nil)
+	atomic.StoreUint32(&isRunning, 0)
+	n := 0
+	for i := 0; i < b.N; i++ {
+		func() {
+			defer func() { recover() }()
+			LoopGuard()
+			n++
+		}()
+	}
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"container/list"
+	"sync"
+)
+
+// LRUCache is a size-bounded cache which evicts the least recently used entry.
+// LRUCache is safe for concurrent use. LRUCache implements Clearable so that the cache
+// is emptied by ZeroClearAllVars if it is registered with RegisterClearable.
+type LRUCache struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[interface{}]*list.Element
+}
+
+type lruEntry struct {
+	key   interface{}
+	value interface{}
+}
+
+// NewLRUCache returns a new LRUCache which holds at most size entries.
+// It panics if size is not positive.
+func NewLRUCache(size int) *LRUCache {
+	if size <= 0 {
+		panic("size of LRUCache must be positive")
+	}
+	return &LRUCache{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[interface{}]*list.Element),
+	}
+}
+
+// Get returns the value of key and marks key as recently used. It returns false if key is not cached.
+func (c *LRUCache) Get(key interface{}) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return el.Value.(*lruEntry).value, true
+}
+
+// Put caches value with key. The least recently used entry is evicted if the cache is full.
+func (c *LRUCache) Put(key, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruEntry).value = value
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&lruEntry{key, value})
+	if c.ll.Len() > c.size {
+		el := c.ll.Back()
+		c.ll.Remove(el)
+		delete(c.items, el.Value.(*lruEntry).key)
+	}
+}
+
+// Len returns the number of cached entries.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// LgoClear removes all entries. This implements Clearable.
+func (c *LRUCache) LgoClear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ll.Init()
+	c.items = make(map[interface{}]*list.Element)
+}
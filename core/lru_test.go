@@ -0,0 +1,62 @@
+package core
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLRUCache(t *testing.T) {
+	c := NewLRUCache(2)
+	c.Put("a", 1)
+	c.Put("b", 2)
+	// a is used recently.
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("Got (%v, %v); want (1, true)", v, ok)
+	}
+	c.Put("c", 3)
+	if _, ok := c.Get("b"); ok {
+		t.Error("b is not evicted")
+	}
+	c.Put("a", 10)
+	if v, ok := c.Get("a"); !ok || v != 10 {
+		t.Errorf("Got (%v, %v); want (10, true)", v, ok)
+	}
+	if n := c.Len(); n != 2 {
+		t.Errorf("Got %d; want 2", n)
+	}
+}
+
+func TestLRUCacheConcurrent(t *testing.T) {
+	c := NewLRUCache(10)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Put(i*100+j, j)
+				c.Get(i*100 + j - 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if n := c.Len(); n != 10 {
+		t.Errorf("Got %d; want 10", n)
+	}
+}
+
+func TestLRUCacheClear(t *testing.T) {
+	defer func() {
+		clearables = make(map[Clearable]bool)
+	}()
+	setExecState(nil)
+	c := NewLRUCache(2)
+	c.Put("a", 1)
+	RegisterClearable(c)
+	if err := ZeroClearAllVars(); err != nil {
+		t.Fatal(err)
+	}
+	if n := c.Len(); n != 0 {
+		t.Errorf("Got %d; want 0", n)
+	}
+}
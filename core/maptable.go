@@ -0,0 +1,61 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"reflect"
+	"sort"
+)
+
+// mapEntry is an entry of a map collected with MapRange.
+type mapEntry struct {
+	key, value reflect.Value
+}
+
+// mapTable is the Tabular of the entries of a map displayed with DisplayMap.
+// Entries are collected with MapRange because MapIndex can not look up NaN keys.
+type mapTable []mapEntry
+
+func (t mapTable) Columns() []string { return []string{"Key", "Value"} }
+func (t mapTable) NumRows() int      { return len(t) }
+func (t mapTable) Row(i int) []interface{} {
+	return []interface{}{t[i].key.Interface(), t[i].value.Interface()}
+}
+
+// isOrderedKind returns whether values of k are ordered by their values.
+func isOrderedKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String, reflect.Bool:
+		return true
+	}
+	return false
+}
+
+// DisplayMap displays the map m as an HTML table of keys and values. The entries are sorted by keys
+// if the keys are numbers, strings or booleans. Otherwise, the entries are displayed in the iteration
+// order of the map, which is random, with a note. It returns an error if m is not a map.
+func DisplayMap(d DataDisplayer, m interface{}, id *string) error {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Map {
+		return fmt.Errorf("%T is not a map", m)
+	}
+	var t mapTable
+	for it := v.MapRange(); it.Next(); {
+		t = append(t, mapEntry{it.Key(), it.Value()})
+	}
+	var buf bytes.Buffer
+	if keyType := v.Type().Key(); isOrderedKind(keyType.Kind()) {
+		sort.SliceStable(t, func(i, j int) bool { return lessMapKey(t[i].key, t[j].key) })
+	} else {
+		fmt.Fprintf(&buf, `<p class="lgo-note">Keys of type %s are not sorted.</p>`, html.EscapeString(keyType.String()))
+	}
+	if err := writeHTMLTable(&buf, t, ` class="lgo-map"`); err != nil {
+		// Never happens because each row has two values.
+		panic(err)
+	}
+	d.HTML(buf.String(), id)
+	return nil
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// mapHTML returns the HTML table of the pairs of keys and values.
+func mapHTML(pairs ...string) string {
+	s := `<table class="lgo-map"><thead><tr><th>Key</th><th>Value</th></tr></thead><tbody>`
+	for i := 0; i < len(pairs); i += 2 {
+		s += "<tr><td>" + pairs[i] + "</td><td>" + pairs[i+1] + "</td></tr>"
+	}
+	return s + "</tbody></table>"
+}
+
+func TestDisplayMap(t *testing.T) {
+	tests := []struct {
+		m    interface{}
+		want string
+	}{
+		{map[string]int{"b": 2, "a": 1, "<c>": 3}, mapHTML("&lt;c&gt;", "3", "a", "1", "b", "2")},
+		{map[int][]string{10: {"x"}, -1: nil, 2: {"y", "z"}}, mapHTML("-1", "[]", "2", "[y z]", "10", "[x]")},
+		{map[float64]bool{1.5: true, 0.5: false}, mapHTML("0.5", "false", "1.5", "true")},
+		{map[bool]int{true: 1, false: 0}, mapHTML("false", "0", "true", "1")},
+		{map[string]int(nil), mapHTML()},
+		{map[float64]int{math.NaN(): 1, 2: 3, math.Inf(-1): 4}, mapHTML("NaN", "1", "-Inf", "4", "2", "3")},
+	}
+	for _, tc := range tests {
+		var d fakeDisplayer
+		id := ""
+		if err := DisplayMap(&d, tc.m, &id); err != nil {
+			t.Error(err)
+			continue
+		}
+		if c := d.contents[0]; c.content != tc.want || c.id != "id1" || id != "id1" {
+			t.Errorf("Got %q (id: %q); want %q", c.content, c.id, tc.want)
+		}
+	}
+}
+
+func TestDisplayMapUnsorted(t *testing.T) {
+	type point struct{ x, y int }
+	var d fakeDisplayer
+	if err := DisplayMap(&d, map[point]string{{1, 2}: "a", {0, 0}: "b"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	got := d.contents[0].content.(string)
+	note := `<p class="lgo-note">Keys of type core.point are not sorted.</p>`
+	if !strings.HasPrefix(got, note) {
+		t.Errorf("Got %q; want the prefix %q", got, note)
+	}
+	for _, s := range []string{"<tr><td>{1 2}</td><td>a</td></tr>", "<tr><td>{0 0}</td><td>b</td></tr>"} {
+		if !strings.Contains(got, s) {
+			t.Errorf("%q does not contain %q", got, s)
+		}
+	}
+}
+
+func TestDisplayMapError(t *testing.T) {
+	var d fakeDisplayer
+	for _, v := range []interface{}{nil, 1, []int{1}, &map[string]int{}} {
+		if err := DisplayMap(&d, v, nil); err == nil {
+			t.Errorf("DisplayMap(%v) must fail", v)
+		}
+	}
+	if err := DisplayMap(&d, []int{}, nil); err == nil || err.Error() != "[]int is not a map" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if len(d.contents) != 0 {
+		t.Errorf("Unexpected contents: %v", d.contents)
+	}
+}
@@ -0,0 +1,273 @@
+package core
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// imageSignatures maps the leading bytes of image formats to their MIME types.
+var imageSignatures = []struct {
+	prefix string
+	mime   string
+}{
+	{"\x89PNG\r\n\x1a\n", "image/png"},
+	{"\xff\xd8\xff", "image/jpeg"},
+	{"GIF87a", "image/gif"},
+	{"GIF89a", "image/gif"},
+	{"<svg", "image/svg+xml"},
+	{"<?xml", "image/svg+xml"},
+}
+
+// imageDataURI returns the data URI of the image b named name.
+// The format of the image is determined by the extension of name or the content.
+func imageDataURI(name string, b []byte) (string, error) {
+	typ := mime.TypeByExtension(path.Ext(name))
+	if !strings.HasPrefix(typ, "image/") {
+		typ = ""
+		head := b
+		if len(head) > 512 {
+			head = head[:512]
+		}
+		// SVG may start with spaces.
+		head = bytes.TrimLeft(head, " \t\r\n")
+		for _, sig := range imageSignatures {
+			if bytes.HasPrefix(head, []byte(sig.prefix)) {
+				typ = sig.mime
+				break
+			}
+		}
+		if len(head) >= 12 && string(head[:4]) == "RIFF" && string(head[8:12]) == "WEBP" {
+			typ = "image/webp"
+		}
+	}
+	if typ == "" {
+		return "", fmt.Errorf("unknown image format of %q", name)
+	}
+	return "data:" + typ + ";base64," + base64.StdEncoding.EncodeToString(b), nil
+}
+
+// DisplayMarkdownWithImages displays markdown in which images (e.g. "![plot](plot.png)") refer to
+// images in the map from names to their contents. The references are replaced with data URIs of the images
+// so that generated images are embedded in a single output. References to names not in images are left untouched.
+// Inline images and reference definitions (e.g. "[plot]: plot.png") are rewritten while code spans and
+// fenced code blocks are kept as is.
+func DisplayMarkdownWithImages(d DataDisplayer, md string, images map[string][]byte, id *string) error {
+	uris := make(map[string]string)
+	for name, b := range images {
+		uri, err := imageDataURI(name, b)
+		if err != nil {
+			return err
+		}
+		uris[name] = uri
+	}
+	d.Markdown(rewriteMarkdownImages(md, uris), id)
+	return nil
+}
+
+// markdownFenceRe matches the opening and closing lines of fenced code blocks.
+var markdownFenceRe = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+
+// markdownRefDefRe matches reference definitions. The second group is the destination.
+var markdownRefDefRe = regexp.MustCompile(`^( {0,3}\[[^\]]+\]:[ \t]*)(<[^>]*>|\S+)`)
+
+// rewriteMarkdownImages replaces the destinations of images in md with uris keyed by the destinations.
+func rewriteMarkdownImages(md string, uris map[string]string) string {
+	var out, text bytes.Buffer
+	flush := func() {
+		out.WriteString(rewriteInlineImages(text.String(), uris))
+		text.Reset()
+	}
+	fence := ""
+	for _, line := range strings.SplitAfter(md, "\n") {
+		if fence != "" {
+			out.WriteString(line)
+			if m := markdownFenceRe.FindStringSubmatch(line); m != nil && m[1][0] == fence[0] && len(m[1]) >= len(fence) &&
+				strings.TrimSpace(line[len(m[0]):]) == "" {
+				fence = ""
+			}
+			continue
+		}
+		if m := markdownFenceRe.FindStringSubmatch(line); m != nil {
+			flush()
+			fence = m[1]
+			out.WriteString(line)
+			continue
+		}
+		if m := markdownRefDefRe.FindStringSubmatchIndex(line); m != nil {
+			dest := line[m[4]:m[5]]
+			name := dest
+			if strings.HasPrefix(dest, "<") {
+				name = dest[1 : len(dest)-1]
+			}
+			if uri, ok := uris[name]; ok {
+				text.WriteString(line[:m[4]] + uri + line[m[5]:])
+				continue
+			}
+		}
+		text.WriteString(line)
+	}
+	flush()
+	return out.String()
+}
+
+// rewriteInlineImages replaces the destinations of inline images in s with uris.
+func rewriteInlineImages(s string, uris map[string]string) string {
+	var buf bytes.Buffer
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			buf.WriteString(s[i : i+2])
+			i += 2
+		case s[i] == '`':
+			n := 1
+			for i+n < len(s) && s[i+n] == '`' {
+				n++
+			}
+			end := codeSpanEnd(s, i+n, n)
+			if end < 0 {
+				end = i + n
+			}
+			buf.WriteString(s[i:end])
+			i = end
+		case strings.HasPrefix(s[i:], "!["):
+			start, end, next, ok := parseInlineImage(s, i)
+			if !ok {
+				buf.WriteByte('!')
+				i++
+				continue
+			}
+			name := s[start:end]
+			if uri, found := uris[name]; found {
+				buf.WriteString(s[i:start] + uri + s[end:next])
+			} else {
+				buf.WriteString(s[i:next])
+			}
+			i = next
+		default:
+			buf.WriteByte(s[i])
+			i++
+		}
+	}
+	return buf.String()
+}
+
+// codeSpanEnd returns the index after the run of n backticks which closes the code span started before i.
+// It returns -1 if the code span is not closed.
+func codeSpanEnd(s string, i, n int) int {
+	for i < len(s) {
+		j := strings.IndexByte(s[i:], '`')
+		if j < 0 {
+			return -1
+		}
+		j += i
+		k := j
+		for k < len(s) && s[k] == '`' {
+			k++
+		}
+		if k-j == n {
+			return k
+		}
+		i = k
+	}
+	return -1
+}
+
+// parseInlineImage parses an inline image (e.g. `![alt](dest "title")`) at i of s.
+// It returns the range of the destination (without angle brackets) and the index after the image.
+func parseInlineImage(s string, i int) (start, end, next int, ok bool) {
+	j := i + 2
+	// Skip the alt text. Brackets in the alt text must be balanced.
+	for depth := 1; ; j++ {
+		if j >= len(s) {
+			return 0, 0, 0, false
+		}
+		if s[j] == '\\' {
+			j++
+		} else if s[j] == '[' {
+			depth++
+		} else if s[j] == ']' {
+			if depth--; depth == 0 {
+				break
+			}
+		}
+	}
+	j++
+	if j >= len(s) || s[j] != '(' {
+		return 0, 0, 0, false
+	}
+	j = skipMarkdownSpaces(s, j+1)
+	if j < len(s) && s[j] == '<' {
+		k := strings.IndexAny(s[j+1:], ">\n")
+		if k < 0 || s[j+1+k] != '>' {
+			return 0, 0, 0, false
+		}
+		start, end = j+1, j+1+k
+		j = end + 1
+	} else {
+		start = j
+		for depth := 0; j < len(s); j++ {
+			c := s[j]
+			if c == '\\' && j+1 < len(s) {
+				j++
+				continue
+			}
+			if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+				break
+			}
+			if c == '(' {
+				depth++
+			} else if c == ')' {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+		}
+		end = j
+	}
+	j = skipMarkdownSpaces(s, j)
+	if j < len(s) && (s[j] == '"' || s[j] == '\'' || s[j] == '(') {
+		closer := s[j]
+		if closer == '(' {
+			closer = ')'
+		}
+		k := j + 1
+		for ; k < len(s) && s[k] != closer; k++ {
+			if s[k] == '\\' {
+				k++
+			}
+		}
+		if k >= len(s) {
+			return 0, 0, 0, false
+		}
+		j = skipMarkdownSpaces(s, k+1)
+	}
+	if j >= len(s) || s[j] != ')' {
+		return 0, 0, 0, false
+	}
+	return start, end, j + 1, true
+}
+
+// skipMarkdownSpaces returns the index of the first non-space character at or after i of s.
+// A single line break is skipped as a space.
+func skipMarkdownSpaces(s string, i int) int {
+	newline := false
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case ' ', '\t':
+		case '\n':
+			if newline {
+				return i
+			}
+			newline = true
+		default:
+			return i
+		}
+	}
+	return i
+}
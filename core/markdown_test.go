@@ -0,0 +1,57 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRewriteMarkdownImages(t *testing.T) {
+	uris := map[string]string{
+		"a.png":   "data:image/png;base64,AA==",
+		"b c.png": "data:image/png;base64,Bw==",
+	}
+	tests := []struct {
+		md   string
+		want string
+	}{
+		{"![](a.png)", "![](data:image/png;base64,AA==)"},
+		{`Plot: ![alt [x]](a.png "title") end`, `Plot: ![alt [x]](data:image/png;base64,AA== "title") end`},
+		{"![x](<b c.png>)", "![x](<data:image/png;base64,Bw==>)"},
+		{"![x]( a.png )", "![x]( data:image/png;base64,AA== )"},
+		{"![x](unknown.png) ![y](a.png)", "![x](unknown.png) ![y](data:image/png;base64,AA==)"},
+		{"[link](a.png) \\![x](a.png)", "[link](a.png) \\![x](a.png)"},
+		{"`![x](a.png)` ![x](a.png)", "`![x](a.png)` ![x](data:image/png;base64,AA==)"},
+		{"![x](a.png", "![x](a.png"},
+		{"![x][ref]\n\n[ref]: a.png \"t\"\n", "![x][ref]\n\n[ref]: data:image/png;base64,AA== \"t\"\n"},
+		{"```\n![x](a.png)\n```\n![x](a.png)\n", "```\n![x](a.png)\n```\n![x](data:image/png;base64,AA==)\n"},
+		{"~~~~md\n![x](a.png)\n~~~\n![x](a.png)", "~~~~md\n![x](a.png)\n~~~\n![x](a.png)"},
+	}
+	for _, tc := range tests {
+		if got := rewriteMarkdownImages(tc.md, uris); got != tc.want {
+			t.Errorf("Got %q; want %q for %q", got, tc.want, tc.md)
+		}
+	}
+}
+
+func TestDisplayMarkdownWithImages(t *testing.T) {
+	var d fakeDisplayer
+	id := "md"
+	images := map[string][]byte{
+		"plot":     []byte("\x89PNG\r\n\x1a\n"),
+		"icon.svg": []byte("<svg></svg>"),
+	}
+	if err := DisplayMarkdownWithImages(&d, "# Report\n![](plot) ![](icon.svg) ![](none)", images, &id); err != nil {
+		t.Fatal(err)
+	}
+	want := "# Report\n![](data:image/png;base64,iVBORw0KGgo=) ![](data:image/svg+xml;base64,PHN2Zz48L3N2Zz4=) ![](none)"
+	if len(d.contents) != 1 || d.contents[0].content != want || d.contents[0].id != "md" {
+		t.Errorf("Got %v; want %q", d.contents, want)
+	}
+	err := DisplayMarkdownWithImages(&d, "![](x)", map[string][]byte{"x": []byte("text")}, nil)
+	if err == nil || !strings.Contains(err.Error(), "unknown image format") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if len(d.contents) != 1 {
+		t.Errorf("Unexpected outputs: %v", d.contents)
+	}
+}
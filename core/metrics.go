@@ -0,0 +1,117 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultMetricsInterval is the default minimum interval between renderings of MetricsPanel.
+const defaultMetricsInterval = 100 * time.Millisecond
+
+// MetricsPanel displays key/value metrics (e.g. loss and accuracy of training) as an HTML table
+// and updates the table in place when the metrics are changed.
+// Renderings are throttled so that frequent updates do not flood the front-end.
+// MetricsPanel is created with NewMetricsPanel. The methods of MetricsPanel are safe for concurrent use.
+type MetricsPanel struct {
+	d  DataDisplayer
+	th throttler
+	// id is the display ID of the panel. It is accessed only in render.
+	id string
+
+	mu     sync.Mutex
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewMetricsPanel returns a new MetricsPanel which displays metrics with d.
+// Nothing is displayed until the first Set.
+func NewMetricsPanel(d DataDisplayer) *MetricsPanel {
+	p := &MetricsPanel{
+		d:      d,
+		values: make(map[string]interface{}),
+	}
+	p.th.render = p.render
+	p.th.interval = defaultMetricsInterval
+	return p
+}
+
+// SetInterval sets the minimum interval between renderings. The default is 100ms.
+// If interval is not positive, the panel is rendered on every update.
+func (p *MetricsPanel) SetInterval(interval time.Duration) {
+	p.th.setInterval(interval)
+}
+
+// Set sets the value of key. Keys are displayed in the order of their first Set.
+func (p *MetricsPanel) Set(key string, value interface{}) {
+	p.mu.Lock()
+	if _, ok := p.values[key]; !ok {
+		p.keys = append(p.keys, key)
+	}
+	p.values[key] = value
+	p.mu.Unlock()
+	p.th.update()
+}
+
+// Remove removes key from the panel.
+func (p *MetricsPanel) Remove(key string) {
+	p.mu.Lock()
+	if _, ok := p.values[key]; !ok {
+		p.mu.Unlock()
+		return
+	}
+	delete(p.values, key)
+	for i, k := range p.keys {
+		if k == key {
+			p.keys = append(p.keys[:i], p.keys[i+1:]...)
+			break
+		}
+	}
+	p.mu.Unlock()
+	p.th.update()
+}
+
+// Flush renders the latest metrics immediately if a rendering is postponed by the throttling.
+func (p *MetricsPanel) Flush() {
+	p.th.flush()
+}
+
+// render displays the metrics.
+func (p *MetricsPanel) render() {
+	var buf bytes.Buffer
+	p.mu.Lock()
+	err := writeHTMLTable(&buf, metricsTable{p}, "")
+	p.mu.Unlock()
+	if err != nil {
+		// Never happens because each row has two values.
+		panic(err)
+	}
+	p.d.HTML(buf.String(), &p.id)
+}
+
+// metricsTable is the Tabular of metrics in MetricsPanel.
+type metricsTable struct {
+	p *MetricsPanel
+}
+
+func (t metricsTable) Columns() []string { return []string{"Metric", "Value"} }
+func (t metricsTable) NumRows() int      { return len(t.p.keys) }
+func (t metricsTable) Row(i int) []interface{} {
+	k := t.p.keys[i]
+	return []interface{}{k, formatMetric(t.p.values[k])}
+}
+
+// formatMetric formats floats with 6 significant digits, durations with HumanDuration and the other values with fmt.Sprint.
+func formatMetric(v interface{}) string {
+	switch v := v.(type) {
+	case time.Duration:
+		return HumanDuration(v)
+	case float64:
+		return strconv.FormatFloat(v, 'g', 6, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', 6, 32)
+	}
+	return fmt.Sprint(v)
+}
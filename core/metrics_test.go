@@ -0,0 +1,71 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMetricsPanel(t *testing.T) {
+	var d fakeDisplayer
+	p := NewMetricsPanel(&d)
+	p.SetInterval(0)
+	p.Set("loss", 0.123456789)
+	p.Set("epoch", 3)
+	p.Set("loss", float32(0.5))
+	p.Remove("epoch")
+	p.Remove("unknown")
+
+	want := []string{
+		"<table><thead><tr><th>Metric</th><th>Value</th></tr></thead><tbody><tr><td>loss</td><td>0.123457</td></tr></tbody></table>",
+		"<table><thead><tr><th>Metric</th><th>Value</th></tr></thead><tbody><tr><td>loss</td><td>0.123457</td></tr><tr><td>epoch</td><td>3</td></tr></tbody></table>",
+		"<table><thead><tr><th>Metric</th><th>Value</th></tr></thead><tbody><tr><td>loss</td><td>0.5</td></tr><tr><td>epoch</td><td>3</td></tr></tbody></table>",
+		"<table><thead><tr><th>Metric</th><th>Value</th></tr></thead><tbody><tr><td>loss</td><td>0.5</td></tr></tbody></table>",
+	}
+	if len(d.contents) != len(want) {
+		t.Fatalf("Got %d contents; want %d", len(d.contents), len(want))
+	}
+	for i, c := range d.contents {
+		if c.content != want[i] {
+			t.Errorf("Got %q; want %q", c.content, want[i])
+		}
+		if c.id != "id1" {
+			t.Errorf("Got %q; want %q", c.id, "id1")
+		}
+	}
+}
+
+func TestMetricsPanelDuration(t *testing.T) {
+	var d fakeDisplayer
+	p := NewMetricsPanel(&d)
+	p.SetInterval(0)
+	p.Set("elapsed", 4213*time.Millisecond)
+	want := "<table><thead><tr><th>Metric</th><th>Value</th></tr></thead><tbody><tr><td>elapsed</td><td>4.2s</td></tr></tbody></table>"
+	if len(d.contents) != 1 || d.contents[0].content != want {
+		t.Errorf("Got %v; want %q", d.contents, want)
+	}
+}
+
+func TestMetricsPanelThrottle(t *testing.T) {
+	var d fakeDisplayer
+	p := NewMetricsPanel(&d)
+	p.SetInterval(time.Hour)
+	p.Set("a", 1)
+	p.Set("a", 2)
+	p.Set("b", 3)
+	if len(d.contents) != 1 {
+		t.Fatalf("Got %d contents; want 1", len(d.contents))
+	}
+	p.Flush()
+	if len(d.contents) != 2 {
+		t.Fatalf("Got %d contents; want 2", len(d.contents))
+	}
+	want := "<table><thead><tr><th>Metric</th><th>Value</th></tr></thead><tbody><tr><td>a</td><td>2</td></tr><tr><td>b</td><td>3</td></tr></tbody></table>"
+	if got := d.contents[1].content; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	// Nothing is postponed.
+	p.Flush()
+	if len(d.contents) != 2 {
+		t.Errorf("Got %d contents; want 2", len(d.contents))
+	}
+}
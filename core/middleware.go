@@ -0,0 +1,25 @@
+package core
+
+// DisplayMiddleware wraps a DataDisplayer to add a feature to displays (e.g. logging of outputs).
+// The returned DataDisplayer should pass outputs to the wrapped DataDisplayer with the same display IDs
+// so that IDs reserved by the underlying displayer are stored to the callers' ID pointers.
+// Middleware implementing only some methods can embed the wrapped DataDisplayer to forward the others.
+// Optional interfaces (e.g. BundleDisplayer and LazyDisplayer) are available through the chain
+// only if every middleware implements them.
+type DisplayMiddleware func(DataDisplayer) DataDisplayer
+
+// ChainDisplayers returns the DataDisplayer which passes outputs through mw in order and then displays
+// them with base. The first middleware is the outermost one: mw[0] wraps mw[1], which wraps base.
+// nil middleware and middleware returning nil are skipped.
+func ChainDisplayers(base DataDisplayer, mw ...DisplayMiddleware) DataDisplayer {
+	d := base
+	for i := len(mw) - 1; i >= 0; i-- {
+		if mw[i] == nil {
+			continue
+		}
+		if next := mw[i](d); next != nil {
+			d = next
+		}
+	}
+	return d
+}
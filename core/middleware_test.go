@@ -0,0 +1,51 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// taggingDisplayer appends its tag to HTML contents and records the order of calls.
+type taggingDisplayer struct {
+	DataDisplayer
+	tag   string
+	order *[]string
+}
+
+func (d *taggingDisplayer) HTML(s string, id *string) {
+	*d.order = append(*d.order, d.tag)
+	d.DataDisplayer.HTML(s+d.tag, id)
+}
+
+func TestChainDisplayers(t *testing.T) {
+	var order []string
+	tagging := func(tag string) DisplayMiddleware {
+		return func(d DataDisplayer) DataDisplayer {
+			return &taggingDisplayer{DataDisplayer: d, tag: tag, order: &order}
+		}
+	}
+	var base fakeDisplayer
+	d := ChainDisplayers(&base, tagging("a"), nil, tagging("b"), func(DataDisplayer) DataDisplayer { return nil })
+	id := ""
+	d.HTML("x", &id)
+	d.HTML("y", &id)
+	// Methods which middleware do not implement are forwarded.
+	d.Text("z", nil)
+	if want := []string{"a", "b", "a", "b"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("Got %q; want %q", order, want)
+	}
+	if id != "id1" {
+		t.Errorf("Got %q; want %q", id, "id1")
+	}
+	want := []displayed{
+		{"text/html", "xab", "id1"},
+		{"text/html", "yab", "id1"},
+		{"text/plain", "z", ""},
+	}
+	if !reflect.DeepEqual(base.contents, want) {
+		t.Errorf("Got %v; want %v", base.contents, want)
+	}
+	if d := ChainDisplayers(&base); d != DataDisplayer(&base) {
+		t.Errorf("Got %v; want the base", d)
+	}
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+)
+
+// NumericSummary is the statistics of a numeric slice. NaNs are excluded from the statistics.
+type NumericSummary struct {
+	Count  int
+	NaNs   int
+	Min    float64
+	Max    float64
+	Mean   float64
+	StdDev float64
+}
+
+// Summarize computes the statistics of data.
+func Summarize(data []float64) NumericSummary {
+	s := NumericSummary{Min: math.NaN(), Max: math.NaN(), Mean: math.NaN(), StdDev: math.NaN()}
+	var sum float64
+	for _, v := range data {
+		if math.IsNaN(v) {
+			s.NaNs++
+			continue
+		}
+		if s.Count == 0 || v < s.Min {
+			s.Min = v
+		}
+		if s.Count == 0 || v > s.Max {
+			s.Max = v
+		}
+		s.Count++
+		sum += v
+	}
+	if s.Count == 0 {
+		return s
+	}
+	s.Mean = sum / float64(s.Count)
+	var sq float64
+	for _, v := range data {
+		if !math.IsNaN(v) {
+			sq += (v - s.Mean) * (v - s.Mean)
+		}
+	}
+	s.StdDev = math.Sqrt(sq / float64(s.Count))
+	return s
+}
+
+const (
+	sparklineWidth  = 120
+	sparklinePoints = 120
+	sparklineHeight = 24
+)
+
+// isFinite returns whether v is neither NaN nor ±Inf.
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// sparkline renders data as a small inline SVG line chart.
+// data is averaged into buckets if it has more than sparklinePoints values. NaNs and ±Inf are skipped.
+func sparkline(data []float64) string {
+	// The range of the chart. It differs from the summary if data has ±Inf.
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, v := range data {
+		if isFinite(v) {
+			low, high = math.Min(low, v), math.Max(high, v)
+		}
+	}
+	if low > high {
+		return ""
+	}
+	var points []float64
+	if len(data) <= sparklinePoints {
+		points = data
+	} else {
+		for i := 0; i < sparklinePoints; i++ {
+			lo, hi := i*len(data)/sparklinePoints, (i+1)*len(data)/sparklinePoints
+			var sum float64
+			var n int
+			for _, v := range data[lo:hi] {
+				if isFinite(v) {
+					sum += v
+					n++
+				}
+			}
+			if n == 0 {
+				points = append(points, math.NaN())
+			} else {
+				points = append(points, sum/float64(n))
+			}
+		}
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg width="%d" height="%d" xmlns="http://www.w3.org/2000/svg"><polyline fill="none" stroke="steelblue" stroke-width="1" points="`, sparklineWidth, sparklineHeight)
+	sep := ""
+	for i, v := range points {
+		if !isFinite(v) {
+			continue
+		}
+		x := float64(sparklineWidth) / 2
+		if len(points) > 1 {
+			x = float64(i) * sparklineWidth / float64(len(points)-1)
+		}
+		y := float64(sparklineHeight) / 2
+		if high > low {
+			y = (high-v)*(sparklineHeight-2)/(high-low) + 1
+		}
+		fmt.Fprintf(&buf, "%s%.1f,%.1f", sep, x, y)
+		sep = " "
+	}
+	buf.WriteString(`"/></svg>`)
+	return buf.String()
+}
+
+// DisplayNumericSummary displays the count, min, max, mean and standard deviation of data with a sparkline
+// instead of printing all values. NaNs in data are excluded from the statistics and counted separately.
+// ±Inf are included in the statistics but are not drawn in the sparkline.
+func DisplayNumericSummary(d DataDisplayer, data []float64, id *string) {
+	s := Summarize(data)
+	var buf bytes.Buffer
+	buf.WriteString("<table><tr><th>count</th><th>min</th><th>max</th><th>mean</th><th>stddev</th>")
+	if s.NaNs > 0 {
+		buf.WriteString("<th>NaN</th>")
+	}
+	buf.WriteString("<th></th></tr>")
+	fmt.Fprintf(&buf, "<tr><td>%d</td><td>%g</td><td>%g</td><td>%g</td><td>%g</td>", s.Count, s.Min, s.Max, s.Mean, s.StdDev)
+	if s.NaNs > 0 {
+		fmt.Fprintf(&buf, "<td>%d</td>", s.NaNs)
+	}
+	fmt.Fprintf(&buf, "<td>%s</td></tr></table>", sparkline(data))
+	d.HTML(buf.String(), id)
+}
+
+// DisplayIntSummary is the []int version of DisplayNumericSummary.
+func DisplayIntSummary(d DataDisplayer, data []int, id *string) {
+	fs := make([]float64, len(data))
+	for i, v := range data {
+		fs[i] = float64(v)
+	}
+	DisplayNumericSummary(d, fs, id)
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	s := Summarize([]float64{1, 2, math.NaN(), 3, 4})
+	want := NumericSummary{Count: 4, NaNs: 1, Min: 1, Max: 4, Mean: 2.5, StdDev: math.Sqrt(1.25)}
+	if s != want {
+		t.Errorf("Got %+v; want %+v", s, want)
+	}
+	empty := Summarize(nil)
+	if empty.Count != 0 || !math.IsNaN(empty.Mean) {
+		t.Errorf("Unexpected summary of an empty slice: %+v", empty)
+	}
+}
+
+func TestDisplayNumericSummary(t *testing.T) {
+	var d fakeDisplayer
+	data := make([]int, 1000)
+	for i := range data {
+		data[i] = i
+	}
+	DisplayIntSummary(&d, data, nil)
+	DisplayNumericSummary(&d, nil, nil)
+	DisplayNumericSummary(&d, []float64{math.NaN()}, nil)
+	if len(d.contents) != 3 {
+		t.Fatalf("Got %d contents; want 3", len(d.contents))
+	}
+	s := d.contents[0].content.(string)
+	if !strings.Contains(s, "<td>1000</td><td>0</td><td>999</td><td>499.5</td>") || !strings.Contains(s, "<polyline") {
+		t.Errorf("Unexpected HTML: %s", s)
+	}
+	if s := d.contents[1].content.(string); strings.Contains(s, "<svg") {
+		t.Errorf("Sparkline is rendered for an empty slice: %s", s)
+	}
+	if s := d.contents[2].content.(string); !strings.Contains(s, "<th>NaN</th>") {
+		t.Errorf("NaN count is not rendered: %s", s)
+	}
+}
+
+func TestDisplayNumericSummaryInf(t *testing.T) {
+	var d fakeDisplayer
+	DisplayNumericSummary(&d, []float64{math.Inf(-1), 0, 1, math.Inf(1)}, nil)
+	DisplayNumericSummary(&d, []float64{math.Inf(1)}, nil)
+	s := d.contents[0].content.(string)
+	if !strings.Contains(s, "<td>4</td><td>-Inf</td><td>+Inf</td>") {
+		t.Errorf("Unexpected HTML: %s", s)
+	}
+	// ±Inf are skipped in the sparkline.
+	if !strings.Contains(s, `points="40.0,23.0 80.0,1.0"`) || strings.Contains(s, "NaN,") || strings.Contains(s, "Inf,") {
+		t.Errorf("Unexpected sparkline: %s", s)
+	}
+	if s := d.contents[1].content.(string); strings.Contains(s, "<svg") {
+		t.Errorf("Sparkline is rendered without finite values: %s", s)
+	}
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// GoroutineEventKind is the kind of a GoroutineEvent.
+type GoroutineEventKind int
+
+const (
+	// GoroutineSpawned is sent when a goroutine is started.
+	GoroutineSpawned GoroutineEventKind = iota
+	// GoroutineFinished is sent when a goroutine returns normally.
+	GoroutineFinished
+	// GoroutinePanicked is sent when a goroutine finishes with a panic.
+	GoroutinePanicked
+	// GoroutineCanceled is sent when a goroutine exits because the execution is canceled.
+	GoroutineCanceled
+)
+
+func (k GoroutineEventKind) String() string {
+	switch k {
+	case GoroutineSpawned:
+		return "spawned"
+	case GoroutineFinished:
+		return "finished"
+	case GoroutinePanicked:
+		return "panicked"
+	case GoroutineCanceled:
+		return "canceled"
+	}
+	return fmt.Sprintf("GoroutineEventKind(%d)", int(k))
+}
+
+// GoroutineEvent is a lifecycle event of a goroutine managed by lgo.
+type GoroutineEvent struct {
+	Kind GoroutineEventKind
+	// ID and Name are the ID and the name of goroutines started with Go. ID is 0 for other goroutines.
+	// Name is the position of the go statement for goroutines started with InitGoroutineAt.
+	ID   int
+	Name string
+	// Time is when the event happened.
+	Time time.Time
+	// Duration is how long the goroutine ran. It is set to finish events of goroutines started with Go.
+	Duration time.Duration
+}
+
+// goroutineLabel identifies a goroutine in GoroutineEvents.
+type goroutineLabel struct {
+	id    int
+	name  string
+	start time.Time
+}
+
+// observerMu protects goroutineObserver.
+var observerMu sync.Mutex
+
+// goroutineObserver is the function set by SetGoroutineObserver.
+var goroutineObserver func(event GoroutineEvent)
+
+// SetGoroutineObserver sets the function called when goroutines managed by lgo start and finish
+// (e.g. to show running goroutines in a task monitor). fn is called in the goroutine which starts
+// or finishes the goroutine, so fn must be safe for concurrent use and should return quickly.
+// Panics in fn are recovered and reported like panics of lgo routines. If fn is nil, events are not observed (the default).
+func SetGoroutineObserver(fn func(event GoroutineEvent)) {
+	observerMu.Lock()
+	defer observerMu.Unlock()
+	goroutineObserver = fn
+}
+
+// notifyGoroutine sends the event of kind of the goroutine labeled l to the observer.
+func notifyGoroutine(kind GoroutineEventKind, l goroutineLabel) {
+	observerMu.Lock()
+	fn := goroutineObserver
+	observerMu.Unlock()
+	if fn == nil {
+		return
+	}
+	ev := GoroutineEvent{Kind: kind, ID: l.id, Name: l.name, Time: time.Now()}
+	if kind != GoroutineSpawned && !l.start.IsZero() {
+		ev.Duration = ev.Time.Sub(l.start)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			reportRecoveredPanic("in the goroutine observer", r)
+		}
+	}()
+	fn(ev)
+}
+
+// finishKind returns the kind of the finish event of a goroutine. r is the value of recover().
+func finishKind(r interface{}) GoroutineEventKind {
+	switch r {
+	case nil:
+		return GoroutineFinished
+	case Bailout:
+		return GoroutineCanceled
+	}
+	return GoroutinePanicked
+}
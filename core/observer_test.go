@@ -0,0 +1,102 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGoroutineObserverPanic(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var mu sync.Mutex
+	var events []GoroutineEvent
+	SetGoroutineObserver(func(ev GoroutineEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, ev)
+	})
+	defer SetGoroutineObserver(nil)
+	var id int
+	state := startExec(LgoContext{Context: context.Background()}, func() {
+		id = Go("worker", func(ctx LgoContext) {
+			panic("boom")
+		})
+	})
+	err := finalizeExec(state)
+	if err == nil || !strings.Contains(err.Error(), "1 goroutine failed") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 2 {
+		t.Fatalf("Got %v; want 2 events", events)
+	}
+	for i, want := range []GoroutineEventKind{GoroutineSpawned, GoroutinePanicked} {
+		ev := events[i]
+		if ev.Kind != want {
+			t.Errorf("Got %v; want %v", ev.Kind, want)
+		}
+		if ev.ID != id || ev.Name != "worker" {
+			t.Errorf("Got (%d, %q); want (%d, %q)", ev.ID, ev.Name, id, "worker")
+		}
+	}
+	if events[1].Time.Before(events[0].Time) || events[1].Duration < 0 {
+		t.Errorf("Unexpected timing: %v", events)
+	}
+}
+
+func TestGoroutineObserverKinds(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var mu sync.Mutex
+	var kinds []GoroutineEventKind
+	SetGoroutineObserver(func(ev GoroutineEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		kinds = append(kinds, ev.Kind)
+	})
+	defer SetGoroutineObserver(nil)
+	state := startExec(LgoContext{Context: context.Background()}, func() {
+		e := InitGoroutineAt("cell1.go:3")
+		func() {
+			defer FinalizeGoroutineAt(e, "cell1.go:3")
+		}()
+		e = InitGoroutine()
+		func() {
+			defer FinalizeGoroutine(e)
+			panic(Bailout)
+		}()
+	})
+	finalizeExec(state)
+	mu.Lock()
+	defer mu.Unlock()
+	want := []GoroutineEventKind{GoroutineSpawned, GoroutineFinished, GoroutineSpawned, GoroutineCanceled}
+	if len(kinds) != len(want) {
+		t.Fatalf("Got %v; want %v", kinds, want)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Errorf("Got %v; want %v", kinds, want)
+			break
+		}
+	}
+}
+
+func TestGoroutineObserverRecover(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	SetGoroutineObserver(func(ev GoroutineEvent) {
+		panic("observer failed")
+	})
+	defer SetGoroutineObserver(nil)
+	done := make(chan struct{})
+	state := startExec(LgoContext{Context: context.Background()}, func() {
+		Go("worker", func(ctx LgoContext) {
+			close(done)
+		})
+	})
+	<-done
+	if err := finalizeExec(state); err != nil {
+		t.Errorf("Panics in the observer must not fail executions: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"errors"
+	"sync"
+)
+
+// onceEntry is the result of an init function of SessionOnce.
+type onceEntry struct {
+	// done is closed when init finishes.
+	done chan struct{}
+	val  interface{}
+	err  error
+}
+
+// onceMu protects onceEntries.
+var onceMu sync.Mutex
+
+// onceEntries keeps the results of SessionOnce by keys.
+var onceEntries = make(map[string]*onceEntry)
+
+// SessionOnce calls init at most once per key in the lgo session (the kernel process) and returns its result.
+// The result is cached and returned from following calls with the same key, even in other code blocks,
+// so that expensive setups (e.g. loading a model) are not repeated when cells are executed again.
+//
+// If init returns an error or panics, the result is not cached and the next call with key calls init again.
+// Concurrent calls with the same key wait for the running init and return its result. While waiting,
+// SessionOnce returns Bailout if the current code execution is canceled.
+func SessionOnce(key string, init func() (interface{}, error)) (interface{}, error) {
+	onceMu.Lock()
+	if en, ok := onceEntries[key]; ok {
+		onceMu.Unlock()
+		var canceled <-chan struct{}
+		if getExecState() != nil {
+			canceled = GetExecContext().Done()
+		}
+		select {
+		case <-en.done:
+			return en.val, en.err
+		case <-canceled:
+			return nil, Bailout
+		}
+	}
+	en := &onceEntry{done: make(chan struct{})}
+	onceEntries[key] = en
+	onceMu.Unlock()
+
+	ok := false
+	defer func() {
+		if !ok {
+			// init panicked.
+			en.err = errOncePanicked
+			forgetOnce(key, en)
+		}
+		close(en.done)
+	}()
+	en.val, en.err = init()
+	ok = true
+	if en.err != nil {
+		forgetOnce(key, en)
+	}
+	return en.val, en.err
+}
+
+// errOncePanicked is returned to callers waiting for an init function of SessionOnce which panicked.
+var errOncePanicked = errors.New("init of SessionOnce panicked")
+
+// forgetOnce removes en of key so that init is called again.
+func forgetOnce(key string, en *onceEntry) {
+	onceMu.Lock()
+	defer onceMu.Unlock()
+	if onceEntries[key] == en {
+		delete(onceEntries, key)
+	}
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// resetOnce removes the cached result of key.
+func resetOnce(key string) {
+	onceMu.Lock()
+	defer onceMu.Unlock()
+	delete(onceEntries, key)
+}
+
+func TestSessionOnce(t *testing.T) {
+	defer resetOnce("once")
+	var calls int32
+	release := make(chan struct{})
+	init := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "model", nil
+	}
+	var wg sync.WaitGroup
+	results := make([]interface{}, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err := SessionOnce("once", init)
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = v
+		}(i)
+	}
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("init is called %d times", n)
+	}
+	for _, v := range results {
+		if v != "model" {
+			t.Errorf("Got %v; want model", v)
+		}
+	}
+	// The result is cached.
+	if v, err := SessionOnce("once", init); v != "model" || err != nil || atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Got (%v, %v, %d); want (model, nil, 1)", v, err, calls)
+	}
+}
+
+func TestSessionOnceRetry(t *testing.T) {
+	defer resetOnce("retry")
+	errInit := errors.New("failed")
+	if _, err := SessionOnce("retry", func() (interface{}, error) { return nil, errInit }); err != errInit {
+		t.Errorf("Got %v; want %v", err, errInit)
+	}
+	func() {
+		defer func() { recover() }()
+		SessionOnce("retry", func() (interface{}, error) { panic("fail") })
+	}()
+	v, err := SessionOnce("retry", func() (interface{}, error) { return 1, nil })
+	if v != 1 || err != nil {
+		t.Errorf("Got (%v, %v); want (1, nil)", v, err)
+	}
+}
+
+func TestSessionOnceCancel(t *testing.T) {
+	defer resetOnce("cancel")
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go SessionOnce("cancel", func() (interface{}, error) {
+		close(started)
+		<-release
+		return nil, nil
+	})
+	defer close(release)
+	<-started
+	atomic.StoreUint32(&isRunning, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	var err error
+	if err := ExecLgoEntryPoint(LgoContext{Context: ctx}, func() {
+		cancel()
+		_, err = SessionOnce("cancel", func() (interface{}, error) { return nil, nil })
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err != Bailout {
+		t.Errorf("Got %v; want %v", err, Bailout)
+	}
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// unifiedOrdering indicates outputs are emitted in a single order. Use atomic.Load/StoreUint32.
+var unifiedOrdering uint32
+
+// SetUnifiedOutputOrdering sets whether outputs printed with LgoPrintln and outputs displayed with
+// the DataDisplayer of code executions are emitted through a single serializer.
+// When enabled, the relative order of prints and displays is preserved as they are called
+// even if they are called from different goroutines or in batches (See BeginBatch).
+// Prints of code executions are displayed as texts with the DataDisplayer instead of LgoPrinters
+// so that front-ends receive prints and displays in a single stream (as HTML if SetColorOutput(true) is called).
+// Prints in batches are buffered with displays. It is disabled by default.
+func SetUnifiedOutputOrdering(enabled bool) {
+	var v uint32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreUint32(&unifiedOrdering, v)
+}
+
+func isUnifiedOutputOrdering() bool {
+	return atomic.LoadUint32(&unifiedOrdering) == 1
+}
+
+// serializer runs functions one by one in the order of calls.
+// Functions can be run reentrantly from functions run by serializer.
+type serializer struct {
+	mu sync.Mutex
+	// queue keeps functions waiting for the running function.
+	queue []func()
+	// running indicates a goroutine is running functions in queue.
+	running bool
+}
+
+// run calls f after functions passed to run before.
+// If another function is running, including the caller of run, f is queued
+// and called by the goroutine which runs the function before run returns there.
+func (s *serializer) run(f func()) {
+	s.mu.Lock()
+	s.queue = append(s.queue, f)
+	if s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = true
+	s.mu.Unlock()
+	defer func() {
+		// Queued functions are run by the next call if a function panics.
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			s.mu.Unlock()
+			return
+		}
+		f := s.queue[0]
+		s.queue[0] = nil
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+		f()
+	}
+}
+
+// emit calls f to emit an output. f is serialized with other outputs if unified ordering is enabled.
+func (e *ExecutionState) emit(f func()) {
+	if isUnifiedOutputOrdering() {
+		e.output.run(f)
+		return
+	}
+	f()
+}
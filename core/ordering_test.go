@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// orderLog records prints and displays in a single log.
+type orderLog struct {
+	fakeDisplayer
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *orderLog) add(s string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, s)
+}
+
+func (l *orderLog) Println(args ...interface{}) { l.add("print " + fmt.Sprint(args...)) }
+func (l *orderLog) Text(s string, id *string)   { l.add("display " + s) }
+
+func runOrderingTest(t *testing.T, unified bool) []string {
+	SetUnifiedOutputOrdering(unified)
+	defer SetUnifiedOutputOrdering(false)
+	l := &orderLog{}
+	RegisterLgoPrinter(l)
+	defer UnregisterLgoPrinter(l)
+	atomic.StoreUint32(&isRunning, 0)
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Display: l}, func() {
+		d := GetExecContext().Display
+		LgoPrintln("a")
+		BeginBatch()
+		d.Text("b", nil)
+		LgoPrintln("c")
+		d.Text("d", nil)
+		EndBatch()
+		LgoPrintln("e")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return l.lines
+}
+
+func TestUnifiedOutputOrdering(t *testing.T) {
+	// Prints are displayed as texts.
+	want := []string{"display a", "display b", "display c", "display d", "display e"}
+	if got := runOrderingTest(t, true); !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v; want %v", got, want)
+	}
+	// Prints are not buffered in batches by default.
+	want = []string{"print a", "print c", "display b", "display d", "print e"}
+	if got := runOrderingTest(t, false); !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v; want %v", got, want)
+	}
+}
+
+// teeDisplayer prints texts it displays with LgoPrintln.
+type teeDisplayer struct {
+	orderLog
+}
+
+func (d *teeDisplayer) Text(s string, id *string) {
+	d.add(s)
+	if s != "tee" {
+		LgoPrintln("tee")
+	}
+}
+
+func TestUnifiedOutputOrderingReentrant(t *testing.T) {
+	SetUnifiedOutputOrdering(true)
+	defer SetUnifiedOutputOrdering(false)
+	d := &teeDisplayer{}
+	atomic.StoreUint32(&isRunning, 0)
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Display: d}, func() {
+		// This must not deadlock.
+		LgoPrintln("hello")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"hello", "tee"}; !reflect.DeepEqual(d.lines, want) {
+		t.Errorf("Got %v; want %v", d.lines, want)
+	}
+}
+
+func TestUnifiedOutputOrderingConcurrent(t *testing.T) {
+	SetUnifiedOutputOrdering(true)
+	defer SetUnifiedOutputOrdering(false)
+	l := &orderLog{}
+	atomic.StoreUint32(&isRunning, 0)
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Display: l}, func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					LgoPrintln(i, j)
+				}
+			}(i)
+		}
+		wg.Wait()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.lines) != 400 {
+		t.Fatalf("Got %d lines; want 400", len(l.lines))
+	}
+	// Prints of each goroutine are displayed in the order of calls.
+	next := make(map[int]int)
+	for _, line := range l.lines {
+		var i, j int
+		if _, err := fmt.Sscanf(line, "display %d %d", &i, &j); err != nil {
+			t.Fatal(err)
+		}
+		if j != next[i] {
+			t.Errorf("Got %q; want the print %d of %d", line, next[i], i)
+		}
+		next[i] = j + 1
+	}
+}
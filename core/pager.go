@@ -0,0 +1,118 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+)
+
+// Pager displays a Tabular page by page under a display ID. Pager is created with DisplayPaged.
+type Pager struct {
+	mu       sync.Mutex
+	d        DataDisplayer
+	t        Tabular
+	pageSize int
+	page     int
+	id       *string
+	err      error
+}
+
+// pageView is a Tabular which is a range of rows of another Tabular.
+type pageView struct {
+	t      Tabular
+	offset int
+	n      int
+}
+
+func (v pageView) Columns() []string       { return v.t.Columns() }
+func (v pageView) NumRows() int            { return v.n }
+func (v pageView) Row(i int) []interface{} { return v.t.Row(v.offset + i) }
+
+// DisplayPaged displays the first page of t, which has pageSize rows, and returns a Pager
+// to show other pages of t by overwriting the display. If id is nil, a new display ID is reserved for the pager.
+// Because JavaScript may be disabled in front-ends, pages are switched from Go code with Next, Prev and Show.
+func DisplayPaged(d DataDisplayer, t Tabular, pageSize int, id *string) *Pager {
+	if pageSize < 1 {
+		pageSize = 1
+	}
+	if id == nil {
+		id = new(string)
+	}
+	p := &Pager{d: d, t: t, pageSize: pageSize, id: id}
+	p.Show(0)
+	return p
+}
+
+// NumPages returns the number of pages. It is at least 1 even if the table is empty.
+func (p *Pager) NumPages() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.numPages()
+}
+
+func (p *Pager) numPages() int {
+	n := (p.t.NumRows() + p.pageSize - 1) / p.pageSize
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+// Page returns the zero-based index of the current page.
+func (p *Pager) Page() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.page
+}
+
+// Show displays the page-th (zero-based) page. page is clamped to the range of pages.
+func (p *Pager) Show(page int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if max := p.numPages() - 1; page > max {
+		page = max
+	}
+	if page < 0 {
+		page = 0
+	}
+	p.page = page
+	p.err = p.render()
+	return p.err
+}
+
+// Next displays the next page. It stays at the last page if the current page is the last one.
+func (p *Pager) Next() error {
+	return p.Show(p.Page() + 1)
+}
+
+// Prev displays the previous page. It stays at the first page if the current page is the first one.
+func (p *Pager) Prev() error {
+	return p.Show(p.Page() - 1)
+}
+
+// Err returns the error of the last rendering.
+func (p *Pager) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+func (p *Pager) render() error {
+	rows := p.t.NumRows()
+	offset := p.page * p.pageSize
+	n := p.pageSize
+	if offset+n > rows {
+		n = rows - offset
+	}
+	var buf bytes.Buffer
+	if err := writeHTMLTable(&buf, pageView{p.t, offset, n}, ""); err != nil {
+		return err
+	}
+	if n > 0 {
+		fmt.Fprintf(&buf, "<div>Page %d of %d (rows %d-%d of %d)</div>", p.page+1, p.numPages(), offset+1, offset+n, rows)
+	} else {
+		buf.WriteString("<div>Page 1 of 1 (no rows)</div>")
+	}
+	p.d.HTML(buf.String(), p.id)
+	return nil
+}
@@ -0,0 +1,61 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDisplayPaged(t *testing.T) {
+	tbl := &sliceTable{cols: []string{"n"}}
+	for i := 0; i < 5; i++ {
+		tbl.rows = append(tbl.rows, []interface{}{i})
+	}
+	var d fakeDisplayer
+	p := DisplayPaged(&d, tbl, 2, nil)
+	if err := p.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n := p.NumPages(); n != 3 {
+		t.Errorf("Got %d pages; want 3", n)
+	}
+	p.Next()
+	p.Next()
+	// Clamped
+	p.Next()
+	p.Prev()
+	p.Show(-10)
+	wants := []string{
+		"rows 1-2 of 5",
+		"rows 3-4 of 5",
+		"rows 5-5 of 5",
+		"rows 5-5 of 5",
+		"rows 3-4 of 5",
+		"rows 1-2 of 5",
+	}
+	if len(d.contents) != len(wants) {
+		t.Fatalf("Got %d contents; want %d", len(d.contents), len(wants))
+	}
+	for i, want := range wants {
+		c := d.contents[i]
+		if !strings.Contains(c.content.(string), want) {
+			t.Errorf("%q is not found in %q", want, c.content)
+		}
+		if c.id != "id1" {
+			t.Errorf("Got %q; want \"id1\"", c.id)
+		}
+	}
+	if !strings.Contains(d.contents[2].content.(string), "<td>4</td>") {
+		t.Errorf("Unexpected last page: %s", d.contents[2].content)
+	}
+}
+
+func TestDisplayPagedEmpty(t *testing.T) {
+	var d fakeDisplayer
+	p := DisplayPaged(&d, &sliceTable{cols: []string{"n"}}, 10, nil)
+	if err := p.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if p.Page() != 0 || p.NumPages() != 1 {
+		t.Errorf("Got page %d of %d; want 0 of 1", p.Page(), p.NumPages())
+	}
+}
@@ -0,0 +1,228 @@
+package core
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// panicMu protects panicDisplayer and panicFormatter.
+var panicMu sync.Mutex
+
+// panicDisplayer is the DataDisplayer set by SetPanicDisplayer.
+var panicDisplayer DataDisplayer
+
+// panicFormatter is the function set by SetPanicFormatter.
+var panicFormatter func(r interface{}) string
+
+// SetPanicDisplayer sets the DataDisplayer to display panics of lgo routines as HTML.
+// When d is not nil, panics are displayed with d as a message and a collapsible stack trace
+// instead of being printed to stderr. If d is nil, panics are printed to stderr (the default).
+func SetPanicDisplayer(d DataDisplayer) {
+	panicMu.Lock()
+	defer panicMu.Unlock()
+	panicDisplayer = d
+}
+
+func getPanicDisplayer() DataDisplayer {
+	panicMu.Lock()
+	defer panicMu.Unlock()
+	return panicDisplayer
+}
+
+// SetPanicFormatter sets the function to format values recovered from panics of lgo routines
+// (e.g. to unwrap wrapped errors or to show fields of custom error types).
+// If fn is nil, values are formatted with fmt.Sprint (the default).
+func SetPanicFormatter(fn func(r interface{}) string) {
+	panicMu.Lock()
+	defer panicMu.Unlock()
+	panicFormatter = fn
+}
+
+// formatPanic formats r with the panic formatter.
+// If the formatter panics, the panic is reported and r is formatted with fmt.Sprint.
+func formatPanic(r interface{}) (s string) {
+	panicMu.Lock()
+	fn := panicFormatter
+	panicMu.Unlock()
+	if fn == nil {
+		return fmt.Sprint(r)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			// Don't use the formatter to report its panic.
+			emitPanic(fmt.Sprintf("panic in the panic formatter: %v", p), debug.Stack())
+			s = fmt.Sprint(r)
+		}
+	}()
+	return fn(r)
+}
+
+// reportPanic reports the panic recovered in an execution of depth. v is the formatted value of the panic.
+func reportPanic(v string, depth int, stack []byte) {
+	msg := "panic: " + v
+	if depth > 1 {
+		msg = fmt.Sprintf("panic (execution depth %d): %s", depth, v)
+	}
+	emitPanic(msg, stack)
+}
+
+// maxRecordedPanics is the max number of panics kept in a list of an execution (e.g. ExecReport.RecoveredPanics).
+const maxRecordedPanics = 100
+
+// reportRecoveredPanic reports the panic r recovered from a callback run by lgo (e.g. a cleanup).
+// where describes the callback (e.g. "in cleanup"). The panic is recorded in the current execution.
+func reportRecoveredPanic(where string, r interface{}) {
+	reportRecovered(getExecState(), fmt.Sprintf("panic %s: %s", where, formatPanic(r)), debug.Stack())
+}
+
+// reportRecovered records a panic recovered from a callback in e and emits it.
+// e can be nil if no code is executed.
+func reportRecovered(e *ExecutionState, msg string, stack []byte) {
+	if e != nil {
+		e.recordRecovered(fmt.Sprintf("%s\n\n%s", msg, stack))
+	}
+	emitPanic(msg, stack)
+}
+
+// recordRecovered appends the panic s to the panics recovered from callbacks of e.
+func (e *ExecutionState) recordRecovered(s string) {
+	e.warnMu.Lock()
+	defer e.warnMu.Unlock()
+	if len(e.recovered) < maxRecordedPanics {
+		e.recovered = append(e.recovered, s)
+	}
+}
+
+// emitPanic displays a panic with the panic displayer or prints it to stderr.
+// The panic is not printed if SetSuppressStderrPanics(true) is called. Then, panics of routines are kept
+// only in executions and panics of callbacks are kept in ExecReport.RecoveredPanics.
+// If the panic displayer panics, the panic is printed to stderr instead.
+func emitPanic(msg string, stack []byte) {
+	if d := getPanicDisplayer(); d != nil {
+		p := displayPanic(d, msg, stack)
+		if p == nil {
+			return
+		}
+		msg = fmt.Sprintf("%s (the panic displayer panicked: %v)", msg, p)
+	}
+	if isStderrPanicSuppressed() {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s\n\n%s", msg, stack)
+}
+
+// displayPanic displays a panic with d. It returns the value of recover() if d panics.
+func displayPanic(d DataDisplayer, msg string, stack []byte) (p interface{}) {
+	defer func() {
+		p = recover()
+	}()
+	d.HTML(panicHTML(msg, stack), nil)
+	return nil
+}
+
+// panicHTML renders a panic message and its stack trace as HTML.
+func panicHTML(msg string, stack []byte) string {
+	return fmt.Sprintf(`<div class="lgo-panic"><pre style="color:#c00">%s</pre>`+
+		`<details><summary>stack trace</summary><pre>%s</pre></details></div>`,
+		html.EscapeString(msg), html.EscapeString(string(stack)))
+}
+
+// suppressStderrPanics indicates panics of lgo routines are not printed to stderr.
+// To access this var, use atomic.Store/LoadUint32.
+var suppressStderrPanics uint32
+
+// SetSuppressStderrPanics sets whether panics of lgo routines are not printed to stderr
+// (e.g. for kernels which route stderr to logs). When enabled, panics are kept only in executions and
+// the error returned from ExecLgoEntryPoint includes the values and the stack trace of panics in main routines
+// and goroutines as if SetAggregateGoroutinePanics(true) is called. Panics are still displayed with
+// the DataDisplayer set by SetPanicDisplayer. It is false by default.
+func SetSuppressStderrPanics(enabled bool) {
+	var v uint32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreUint32(&suppressStderrPanics, v)
+}
+
+func isStderrPanicSuppressed() bool {
+	return atomic.LoadUint32(&suppressStderrPanics) == 1
+}
+
+// PanicAggregation is which panics of goroutines are included in errors of executions.
+type PanicAggregation int32
+
+const (
+	// PanicReportNone does not include panics in errors. This is the default.
+	PanicReportNone PanicAggregation = iota
+	// PanicReportFirst includes the value and the stack trace of the first panic.
+	PanicReportFirst
+	// PanicReportAll includes the values and the stack traces of all panics.
+	PanicReportAll
+)
+
+// aggregateGoroutinePanics is the PanicAggregation set by SetAggregateGoroutinePanics.
+// To access this var, use atomic.Store/LoadInt32.
+var aggregateGoroutinePanics int32
+
+// SetAggregateGoroutinePanics sets which panics in goroutines are included in the error returned from
+// ExecLgoEntryPoint so that front-ends can show the causes of failures. It is PanicReportNone by default.
+func SetAggregateGoroutinePanics(a PanicAggregation) {
+	atomic.StoreInt32(&aggregateGoroutinePanics, int32(a))
+}
+
+func getAggregateGoroutinePanics() PanicAggregation {
+	return PanicAggregation(atomic.LoadInt32(&aggregateGoroutinePanics))
+}
+
+// goroutinePanicDetails returns the details of panics in goroutines of e appended to errors
+// according to SetAggregateGoroutinePanics. If SetSuppressStderrPanics(true) is called, panics
+// in the main routine are also included and PanicReportNone is handled as PanicReportAll
+// because panics are not printed.
+func (e *ExecutionState) goroutinePanicDetails() string {
+	suppressed := isStderrPanicSuppressed()
+	a := getAggregateGoroutinePanics()
+	if a == PanicReportNone {
+		if !suppressed {
+			return ""
+		}
+		a = PanicReportAll
+	}
+	var panics []recordedPanic
+	if suppressed {
+		panics = append(panics, e.mainCounter.recordedPanics()...)
+	}
+	panics = append(panics, e.subCounter.recordedPanics()...)
+	if len(panics) == 0 {
+		return ""
+	}
+	if a == PanicReportFirst {
+		panics = panics[:1]
+	}
+	var b strings.Builder
+	for _, p := range panics {
+		fmt.Fprintf(&b, "\npanic: %s\n\n%s", p.msg, p.stack)
+	}
+	return b.String()
+}
+
+// recordRestartPanic records the panic of a routine restarted by SafeGo. It is not counted as a failure
+// and is not included in the error of the execution. See ExecReport.RestartPanics.
+func (c *resultCounter) recordRestartPanic(msg string, stack []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.restarts) < maxRecordedPanics {
+		c.restarts = append(c.restarts, recordedPanic{msg, stack})
+	}
+}
+
+// recordedPanics returns the panics recorded in c.
+func (c *resultCounter) recordedPanics() []recordedPanic {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]recordedPanic(nil), c.panics...)
+}
@@ -0,0 +1,250 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPanicDisplayer(t *testing.T) {
+	defer SetPanicDisplayer(nil)
+	var d fakeDisplayer
+	SetPanicDisplayer(&d)
+	atomic.StoreUint32(&isRunning, 0)
+	err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		panic("<oops>")
+	})
+	if err == nil || err.Error() != "main routine failed" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if len(d.contents) != 1 {
+		t.Fatalf("Got %d contents; want 1", len(d.contents))
+	}
+	got := d.contents[0].content.(string)
+	if !strings.HasPrefix(got, `<div class="lgo-panic"><pre style="color:#c00">panic: &lt;oops&gt;</pre><details>`) {
+		t.Errorf("Unexpected HTML: %s", got)
+	}
+	if !strings.Contains(got, "TestPanicDisplayer") {
+		t.Errorf("The stack trace is not displayed: %s", got)
+	}
+}
+
+type codeError struct {
+	code int
+}
+
+func (e *codeError) Error() string { return "code error" }
+
+func TestPanicFormatter(t *testing.T) {
+	defer SetPanicDisplayer(nil)
+	defer SetPanicFormatter(nil)
+	var d fakeDisplayer
+	SetPanicDisplayer(&d)
+	SetPanicFormatter(func(r interface{}) string {
+		if e, ok := r.(*codeError); ok {
+			return fmt.Sprintf("%v (code=%d)", e, e.code)
+		}
+		return fmt.Sprint(r)
+	})
+	atomic.StoreUint32(&isRunning, 0)
+	ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		panic(&codeError{42})
+	})
+	if len(d.contents) != 1 {
+		t.Fatalf("Got %d contents; want 1", len(d.contents))
+	}
+	if got := d.contents[0].content.(string); !strings.Contains(got, "<pre style=\"color:#c00\">panic: code error (code=42)</pre>") {
+		t.Errorf("Unexpected HTML: %s", got)
+	}
+}
+
+func TestPanicFormatterPanics(t *testing.T) {
+	defer SetPanicDisplayer(nil)
+	defer SetPanicFormatter(nil)
+	var d fakeDisplayer
+	SetPanicDisplayer(&d)
+	SetPanicFormatter(func(r interface{}) string { panic("bad formatter") })
+	atomic.StoreUint32(&isRunning, 0)
+	ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		panic("failure")
+	})
+	if len(d.contents) != 2 {
+		t.Fatalf("Got %d contents; want 2", len(d.contents))
+	}
+	for i, want := range []string{"panic in the panic formatter: bad formatter", "panic: failure</pre>"} {
+		if got := d.contents[i].content.(string); !strings.Contains(got, want) {
+			t.Errorf("%q does not contain %q", got, want)
+		}
+	}
+}
+
+// panickingDisplayer is a DataDisplayer whose HTML panics.
+type panickingDisplayer struct {
+	fakeDisplayer
+}
+
+func (*panickingDisplayer) HTML(s string, id *string) { panic("bad displayer") }
+
+func TestPanicDisplayerPanics(t *testing.T) {
+	defer SetPanicDisplayer(nil)
+	SetPanicDisplayer(&panickingDisplayer{})
+	stderr := captureStderr(t, func() {
+		atomic.StoreUint32(&isRunning, 0)
+		ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+			panic("failure")
+		})
+	})
+	if want := "panic: failure (the panic displayer panicked: bad displayer)\n\ngoroutine "; !strings.HasPrefix(stderr, want) {
+		t.Errorf("Got %q; want the prefix %q", stderr, want)
+	}
+}
+
+func TestSuppressedRecoveredPanics(t *testing.T) {
+	SetSuppressStderrPanics(true)
+	defer SetSuppressStderrPanics(false)
+	var r *ExecReport
+	var err error
+	stderr := captureStderr(t, func() {
+		atomic.StoreUint32(&isRunning, 0)
+		r, err = ExecLgoEntryPointReport(LgoContext{Context: context.Background()}, func() {
+			RegisterCleanup(func() { panic("cleanup failure") })
+			AfterExecution(func(*ExecReport) { panic("callback failure") })
+		})
+	})
+	if err != nil || stderr != "" {
+		t.Errorf("Unexpected error or stderr: %v, %q", err, stderr)
+	}
+	if len(r.RecoveredPanics) != 2 {
+		t.Fatalf("Got %q; want 2 panics", r.RecoveredPanics)
+	}
+	for i, want := range []string{"panic in cleanup: cleanup failure\n\ngoroutine ", "panic in AfterExecution callback: callback failure\n\ngoroutine "} {
+		if got := r.RecoveredPanics[i]; !strings.HasPrefix(got, want) {
+			t.Errorf("Got %q; want the prefix %q", got, want)
+		}
+	}
+}
+
+func TestAggregateGoroutinePanics(t *testing.T) {
+	defer SetAggregateGoroutinePanics(PanicReportNone)
+	defer SetPanicDisplayer(nil)
+	// Suppress outputs to stderr. Panics are displayed concurrently.
+	SetPanicDisplayer(NopDisplayer())
+	tests := []struct {
+		a      PanicAggregation
+		panics int
+	}{
+		{PanicReportNone, 0},
+		{PanicReportFirst, 1},
+		{PanicReportAll, 2},
+	}
+	for _, tc := range tests {
+		SetAggregateGoroutinePanics(tc.a)
+		atomic.StoreUint32(&isRunning, 0)
+		err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+			for i := 0; i < 2; i++ {
+				state := InitGoroutine()
+				go func(i int) {
+					defer FinalizeGoroutine(state)
+					panic(fmt.Sprintf("sub failure %d", i))
+				}(i)
+			}
+			<-GetExecContext().Done()
+		})
+		if err == nil {
+			t.Fatal("No error is returned")
+		}
+		msg := err.Error()
+		if !strings.HasPrefix(msg, "2 goroutines failed") {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		// Each panic is followed by its stack trace.
+		if got := strings.Count(msg, "\n\ngoroutine "); got != tc.panics {
+			t.Errorf("Got %d stack traces with %d; want %d: %v", got, tc.a, tc.panics, err)
+		}
+		if got := strings.Count(msg, "\npanic: sub failure "); got != tc.panics {
+			t.Errorf("Got %d panics with %d; want %d: %v", got, tc.a, tc.panics, err)
+		}
+	}
+}
+
+// captureStderr returns the outputs written to os.Stderr in f.
+func captureStderr(t *testing.T, f func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stderr
+	os.Stderr = w
+	out := make(chan string)
+	go func() {
+		b, _ := ioutil.ReadAll(r)
+		out <- string(b)
+	}()
+	func() {
+		defer func() { os.Stderr = orig }()
+		f()
+	}()
+	w.Close()
+	return <-out
+}
+
+func TestSuppressStderrPanics(t *testing.T) {
+	defer SetSuppressStderrPanics(false)
+	SetRestartPolicy(1, 0)
+	defer SetRestartPolicy(0, 0)
+	for _, suppressed := range []bool{false, true} {
+		SetSuppressStderrPanics(suppressed)
+		var err error
+		stderr := captureStderr(t, func() {
+			atomic.StoreUint32(&isRunning, 0)
+			err = ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+				RegisterCleanup(func() { panic("cleanup failure") })
+				restarted := make(chan struct{})
+				SafeGo(func() {
+					select {
+					case <-restarted:
+					default:
+						close(restarted)
+						panic("restart failure")
+					}
+				})
+				<-restarted
+				state := InitGoroutine()
+				go func() {
+					defer FinalizeGoroutine(state)
+					panic("sub failure")
+				}()
+				<-GetExecContext().Done()
+				panic("main failure")
+			})
+		})
+		if got := stderr == ""; got != suppressed {
+			t.Errorf("Unexpected stderr with %v: %q", suppressed, stderr)
+		}
+		for _, want := range []string{"panic: restart failure (restart 1/1)", "panic in cleanup: cleanup failure"} {
+			if got := strings.Contains(stderr, want); got == suppressed {
+				t.Errorf("strings.Contains(%q, %q) = %v with %v", stderr, want, got, suppressed)
+			}
+		}
+		if err == nil {
+			t.Fatal("No error is returned")
+		}
+		msg := err.Error()
+		if !strings.HasPrefix(msg, "main routine failed, 1 goroutine failed") {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		for _, want := range []string{"\npanic: main failure", "\npanic: sub failure", "\n\ngoroutine "} {
+			if got := strings.Contains(msg, want); got != suppressed {
+				t.Errorf("strings.Contains(%q, %q) = %v with %v", msg, want, got, suppressed)
+			}
+		}
+		// The restarted goroutine recovered.
+		if strings.Contains(msg, "restart failure") {
+			t.Errorf("The restart panic is included in the error: %q", msg)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package core
+
+import "sync"
+
+// lastPartialMu protects lastPartial.
+var lastPartialMu sync.Mutex
+
+// lastPartial is the partial result of the last canceled execution.
+var lastPartial interface{}
+
+// SetPartialResult stores v as the best-so-far result of the current code execution.
+// Long computations can call this periodically so that the result computed so far is
+// available from LastPartialResult if the execution is interrupted.
+// SetPartialResult does nothing when lgo does not execute any code blocks.
+func SetPartialResult(v interface{}) {
+	if e := getExecState(); e != nil {
+		e.cancelMu.Lock()
+		defer e.cancelMu.Unlock()
+		e.partial = v
+	}
+}
+
+// LastPartialResult returns the value passed to SetPartialResult last in the last execution
+// if the execution was interrupted or canceled. It returns nil if the last execution was not canceled.
+func LastPartialResult() interface{} {
+	lastPartialMu.Lock()
+	defer lastPartialMu.Unlock()
+	return lastPartial
+}
+
+// storePartialResult makes the partial result of e available from LastPartialResult.
+// Only results of interrupted or canceled executions are kept. Executions canceled for other reasons
+// (e.g. timeouts and the main routine finished with LingeringCancel) do not keep partial results.
+func (e *ExecutionState) storePartialResult() {
+	e.cancelMu.Lock()
+	var v interface{}
+	if e.cancelReason == interruptedReason || e.cancelReason == canceledReason {
+		v = e.partial
+	}
+	e.cancelMu.Unlock()
+	lastPartialMu.Lock()
+	defer lastPartialMu.Unlock()
+	lastPartial = v
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPartialResult(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	err := ExecLgoEntryPoint(LgoContext{Context: ctx}, func() {
+		sum := 0
+		for i := 1; i <= 10; i++ {
+			sum += i
+			SetPartialResult(sum)
+		}
+		cancel()
+		for {
+			// The cancellation is propagated to isRunning asynchronously.
+			ExitIfCtxDone()
+			time.Sleep(time.Millisecond)
+		}
+	})
+	if err == nil || err.Error() != "main routine canceled" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if got := LastPartialResult(); got != 55 {
+		t.Errorf("Got %v; want 55", got)
+	}
+
+	atomic.StoreUint32(&isRunning, 0)
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		SetPartialResult(1)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := LastPartialResult(); got != nil {
+		t.Errorf("Got %v; want nil", got)
+	}
+}
+
+func TestPartialResultNotInterrupted(t *testing.T) {
+	defer SetLingeringPolicy(LingeringWait)
+	defer SetExecTimeout(0)
+
+	// Canceled because the main routine finished.
+	SetLingeringPolicy(LingeringCancel)
+	atomic.StoreUint32(&isRunning, 0)
+	ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		SetPartialResult(1)
+		state := InitGoroutine()
+		go func() {
+			defer FinalizeGoroutine(state)
+			<-state.Context.Done()
+		}()
+	})
+	if got := LastPartialResult(); got != nil {
+		t.Errorf("Got %v; want nil", got)
+	}
+	SetLingeringPolicy(LingeringWait)
+
+	// Canceled by the timeout.
+	SetExecTimeout(10 * time.Millisecond)
+	atomic.StoreUint32(&isRunning, 0)
+	ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		SetPartialResult(2)
+		for {
+			ExitIfCtxDone()
+			time.Sleep(time.Millisecond)
+		}
+	})
+	if got := LastPartialResult(); got != nil {
+		t.Errorf("Got %v; want nil", got)
+	}
+}
@@ -0,0 +1,251 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// maxPrintDepth and maxPrintElements are the limits of values printed with FormatValue.
+// They are accessed atomically. Values <= 0 mean no limits.
+var maxPrintDepth, maxPrintElements int64
+
+// SetMaxPrintDepth sets the maximum depth of nested structs, arrays, slices and maps printed with LgoPrintln
+// to prevent enormous outputs from deeply nested data. Values nested deeper than n are printed as "...".
+// If n <= 0, the depth is not limited (the default).
+//
+// While the depth or the number of elements is limited, values are printed like %v of fmt except that
+// pointers to structs, arrays, slices and maps are followed at any depth and cycles are printed as "<cycle>".
+func SetMaxPrintDepth(n int) {
+	atomic.StoreInt64(&maxPrintDepth, int64(n))
+}
+
+// SetMaxPrintElements sets the maximum number of elements of each array, slice and map printed with LgoPrintln.
+// Remaining elements are omitted with "...". If n <= 0, the number of elements is not limited (the default).
+func SetMaxPrintElements(n int) {
+	atomic.StoreInt64(&maxPrintElements, int64(n))
+}
+
+func getPrintLimits() (depth, elems int) {
+	return int(atomic.LoadInt64(&maxPrintDepth)), int(atomic.LoadInt64(&maxPrintElements))
+}
+
+// formatLimited formats v with fmt.Sprint or with limitedPrinter if the print limits are set.
+// time.Duration is formatted with HumanDuration.
+func formatLimited(v interface{}) string {
+	if d, ok := v.(time.Duration); ok {
+		return HumanDuration(d)
+	}
+	depth, elems := getPrintLimits()
+	if depth <= 0 && elems <= 0 {
+		return fmt.Sprint(v)
+	}
+	return formatWithLimits(v, depth, elems)
+}
+
+// formatWithLimits formats v with the maximum depth and the maximum number of elements of collections.
+func formatWithLimits(v interface{}, depth, elems int) string {
+	if t, ok := v.(tuple); ok {
+		return t.format(func(v interface{}) string { return formatWithLimits(v, depth, elems) })
+	}
+	p := &limitedPrinter{maxDepth: depth, maxElems: elems, visiting: make(map[visitKey]bool)}
+	p.print(reflect.ValueOf(v), 0)
+	return p.buf.String()
+}
+
+// visitKey identifies a pointer, a slice or a map on the path from the root value to detect cycles.
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// limitedPrinter prints values like %v with the limits of depth and elements.
+type limitedPrinter struct {
+	buf      bytes.Buffer
+	maxDepth int
+	maxElems int
+	// visiting keeps the references being printed.
+	visiting map[visitKey]bool
+}
+
+// isComposite returns whether values of k contain other values printed recursively.
+func isComposite(k reflect.Kind) bool {
+	switch k {
+	case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// hasFormatMethods returns whether fmt formats v with its methods.
+func hasFormatMethods(v reflect.Value) bool {
+	if !v.CanInterface() {
+		return false
+	}
+	switch v.Interface().(type) {
+	case fmt.Formatter, fmt.Stringer, error:
+		return true
+	}
+	return false
+}
+
+// enter marks the reference k as visiting. It returns false if k is already being printed.
+func (p *limitedPrinter) enter(k visitKey) bool {
+	if p.visiting[k] {
+		return false
+	}
+	p.visiting[k] = true
+	return true
+}
+
+// print prints v nested in depth composite values.
+func (p *limitedPrinter) print(v reflect.Value, depth int) {
+	if !v.IsValid() {
+		p.buf.WriteString("<nil>")
+		return
+	}
+	if hasFormatMethods(v) {
+		fmt.Fprint(&p.buf, v.Interface())
+		return
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			p.buf.WriteString("<nil>")
+			return
+		}
+		p.print(v.Elem(), depth)
+		return
+	case reflect.Ptr:
+		if v.IsNil() {
+			p.buf.WriteString("<nil>")
+			return
+		}
+		if !isComposite(v.Elem().Kind()) {
+			fmt.Fprint(&p.buf, v)
+			return
+		}
+		if p.truncated(depth) {
+			return
+		}
+		k := visitKey{ptr: v.Pointer(), typ: v.Type()}
+		if !p.enter(k) {
+			p.buf.WriteString("<cycle>")
+			return
+		}
+		defer delete(p.visiting, k)
+		p.buf.WriteByte('&')
+		p.print(v.Elem(), depth)
+		return
+	}
+	if !isComposite(v.Kind()) {
+		fmt.Fprint(&p.buf, v)
+		return
+	}
+	if p.truncated(depth) {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		p.buf.WriteByte('{')
+		for i := 0; i < v.NumField(); i++ {
+			if i > 0 {
+				p.buf.WriteByte(' ')
+			}
+			p.print(v.Field(i), depth+1)
+		}
+		p.buf.WriteByte('}')
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && !v.IsNil() {
+			k := visitKey{ptr: v.Pointer(), typ: v.Type(), len: v.Len()}
+			if !p.enter(k) {
+				p.buf.WriteString("<cycle>")
+				return
+			}
+			defer delete(p.visiting, k)
+		}
+		p.buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				p.buf.WriteByte(' ')
+			}
+			if p.maxElems > 0 && i >= p.maxElems {
+				p.buf.WriteString("...")
+				break
+			}
+			p.print(v.Index(i), depth+1)
+		}
+		p.buf.WriteByte(']')
+	case reflect.Map:
+		if !v.IsNil() {
+			k := visitKey{ptr: v.Pointer(), typ: v.Type()}
+			if !p.enter(k) {
+				p.buf.WriteString("<cycle>")
+				return
+			}
+			defer delete(p.visiting, k)
+		}
+		p.buf.WriteString("map[")
+		// Collect entries with MapRange because MapIndex can not look up NaN keys.
+		var entries []mapEntry
+		for it := v.MapRange(); it.Next(); {
+			entries = append(entries, mapEntry{it.Key(), it.Value()})
+		}
+		sortMapEntries(entries)
+		for i, e := range entries {
+			if i > 0 {
+				p.buf.WriteByte(' ')
+			}
+			if p.maxElems > 0 && i >= p.maxElems {
+				p.buf.WriteString("...")
+				break
+			}
+			p.print(e.key, depth+1)
+			p.buf.WriteByte(':')
+			p.print(e.value, depth+1)
+		}
+		p.buf.WriteByte(']')
+	}
+}
+
+// truncated prints the placeholder and returns true if a composite value in depth exceeds the limit.
+func (p *limitedPrinter) truncated(depth int) bool {
+	if p.maxDepth > 0 && depth >= p.maxDepth {
+		p.buf.WriteString("...")
+		return true
+	}
+	return false
+}
+
+// sortMapEntries sorts map entries by keys to print maps deterministically like fmt.
+func sortMapEntries(entries []mapEntry) {
+	sort.SliceStable(entries, func(i, j int) bool { return lessMapKey(entries[i].key, entries[j].key) })
+}
+
+// lessMapKey reports whether the map key a is ordered before b.
+// Keys of numbers, strings and booleans are compared by their values and others by their printed forms.
+// NaN is ordered before the other floats like fmt.
+func lessMapKey(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		x, y := a.Float(), b.Float()
+		if math.IsNaN(x) || math.IsNaN(y) {
+			return math.IsNaN(x) && !math.IsNaN(y)
+		}
+		return x < y
+	case reflect.String:
+		return a.String() < b.String()
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
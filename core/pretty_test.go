@@ -0,0 +1,125 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+type prettyNode struct {
+	Value int
+	Next  *prettyNode
+}
+
+type prettyStringer struct{ name string }
+
+func (s prettyStringer) String() string { return "<" + s.name + ">" }
+
+func TestFormatWithLimitsLikeFmt(t *testing.T) {
+	type pair struct {
+		A int
+		b string
+	}
+	values := []interface{}{
+		nil, 1, "abc", 1.5, true, []int{1, 2}, [2]string{"a", "b"}, []int(nil),
+		map[string]int{"b": 2, "a": 1, "c": 3}, map[int]bool{3: true, 1: false},
+		map[float64]int{math.NaN(): 1, 2: 3, math.Inf(-1): 4},
+		pair{1, "x"}, &pair{2, "y"}, []interface{}{nil, 1, "a"},
+		prettyStringer{"s"}, errors.New("err"), []fmt.Stringer{prettyStringer{"t"}},
+	}
+	for _, v := range values {
+		want := fmt.Sprint(v)
+		if got := formatWithLimits(v, 100, 100); got != want {
+			t.Errorf("Got %q; want %q", got, want)
+		}
+	}
+}
+
+func TestFormatWithLimitsDeep(t *testing.T) {
+	var list *prettyNode
+	for i := 5; i > 0; i-- {
+		list = &prettyNode{i, list}
+	}
+	if got, want := formatWithLimits(list, 0, 0), "&{1 &{2 &{3 &{4 &{5 <nil>}}}}}"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	if got, want := formatWithLimits(list, 3, 0), "&{1 &{2 &{3 ...}}}"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	nested := [][]interface{}{{1, []int{2, 3}}, {map[string][]int{"a": {4}}}}
+	if got, want := formatWithLimits(nested, 2, 0), "[[1 ...] [...]]"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	if got, want := formatWithLimits(tuple{[]int{1}, [][]int{{2}}}, 1, 0), "([1], [...])"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+}
+
+func TestFormatWithLimitsElements(t *testing.T) {
+	if got, want := formatWithLimits([]int{1, 2, 3, 4, 5}, 0, 3), "[1 2 3 ...]"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	if got, want := formatWithLimits(map[int]string{3: "c", 1: "a", 2: "b"}, 0, 2), "map[1:a 2:b ...]"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	if got, want := formatWithLimits([]int{1, 2}, 0, 2), "[1 2]"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+}
+
+func TestFormatWithLimitsNaNKeys(t *testing.T) {
+	m := map[float64][]int{math.NaN(): {1}, 1: {2}}
+	if got, want := formatWithLimits(m, 0, 0), "map[NaN:[1] 1:[2]]"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	// NaN keys are ordered first like fmt.
+	if got, want := formatWithLimits(m, 0, 1), "map[NaN:[1] ...]"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+}
+
+func TestFormatWithLimitsCycle(t *testing.T) {
+	ring := &prettyNode{Value: 1}
+	ring.Next = &prettyNode{Value: 2, Next: ring}
+	if got, want := formatWithLimits(ring, 0, 0), "&{1 &{2 <cycle>}}"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	m := map[string]interface{}{"a": 1}
+	m["self"] = m
+	if got, want := formatWithLimits(m, 0, 0), "map[a:1 self:<cycle>]"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	s := []interface{}{1, nil}
+	s[1] = s
+	if got, want := formatWithLimits(s, 0, 0), "[1 <cycle>]"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	// Shared references which are not cycles are printed repeatedly.
+	shared := &prettyNode{Value: 3}
+	if got, want := formatWithLimits([]*prettyNode{shared, shared}, 0, 0), "[&{3 <nil>} &{3 <nil>}]"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+}
+
+func TestSetMaxPrintDepth(t *testing.T) {
+	defer RestoreConfig(SaveConfig())
+	v := [][]int{{1, 2, 3}}
+	if got, want := FormatValue(v), "[[1 2 3]]"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	SetMaxPrintDepth(1)
+	if got, want := FormatValue(v), "[...]"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	SetMaxPrintDepth(0)
+	SetMaxPrintElements(2)
+	if got, want := FormatValue(v), "[[1 2 ...]]"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	SetColorOutput(true)
+	if got := FormatValue(v); !strings.Contains(got, "[[1 2 ...]]") {
+		t.Errorf("Unexpected colorized output: %q", got)
+	}
+}
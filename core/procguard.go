@@ -0,0 +1,47 @@
+package core
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// processPanicGuard indicates InstallProcessPanicGuard is called. Use atomic.Load/StoreUint32.
+var processPanicGuard uint32
+
+// InstallProcessPanicGuard hardens the kernel process against panics in code executions.
+// After this call,
+//   - panics in callbacks of AfterFunc and in renders of throttled displays (e.g. MultiProgress) are
+//     recovered and reported like panics of lgo routines (See SetPanicDisplayer) instead of crashing the process.
+//   - memory faults in main routines and the callbacks above are converted to panics with debug.SetPanicOnFault
+//     so that they are recovered.
+//   - the stack traces of all goroutines are printed if the process crashes (debug.SetTraceback("all")).
+//
+// Note that Go can not recover panics in other goroutines. A panic in a goroutine which is not managed
+// by lgo (e.g. a goroutine started by a library. Goroutines started with go statements in lgo are
+// managed with InitGoroutine) still crashes the process.
+func InstallProcessPanicGuard() {
+	atomic.StoreUint32(&processPanicGuard, 1)
+	debug.SetTraceback("all")
+}
+
+func isProcessPanicGuardInstalled() bool {
+	return atomic.LoadUint32(&processPanicGuard) == 1
+}
+
+// guardCallback returns f which recovers panics in f if InstallProcessPanicGuard is called.
+// f must be called in a new goroutine (e.g. a callback of time.AfterFunc).
+func guardCallback(where string, f func()) func() {
+	if !isProcessPanicGuardInstalled() {
+		return f
+	}
+	return func() {
+		defer func() {
+			if r := recover(); r != nil {
+				reportRecovered(getExecState(), fmt.Sprintf("panic: %s (recovered in %s)", formatPanic(r), where), debug.Stack())
+			}
+		}()
+		debug.SetPanicOnFault(true)
+		f()
+	}
+}
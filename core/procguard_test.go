@@ -0,0 +1,36 @@
+package core
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// chanDisplayer sends HTML outputs to a channel.
+type chanDisplayer struct {
+	nopDisplayer
+	html chan string
+}
+
+func (d *chanDisplayer) HTML(s string, id *string) { d.html <- s }
+
+func TestProcessPanicGuard(t *testing.T) {
+	InstallProcessPanicGuard()
+	defer atomic.StoreUint32(&processPanicGuard, 0)
+	d := &chanDisplayer{html: make(chan string, 1)}
+	SetPanicDisplayer(d)
+	defer SetPanicDisplayer(nil)
+
+	AfterFunc(time.Millisecond, func() {
+		panic("timer failed")
+	})
+	select {
+	case s := <-d.html:
+		if want := "panic: timer failed (recovered in AfterFunc)"; !strings.Contains(s, want) {
+			t.Errorf("%q does not contain %q", s, want)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("The panic is not reported")
+	}
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"runtime/pprof"
+	"sync"
+)
+
+// profileMu protects the CPU profiling settings and results below.
+var profileMu sync.Mutex
+
+// profileExecution indicates each execution is profiled with pprof.
+var profileExecution bool
+
+// lastProfile keeps the CPU profile of the last profiled execution.
+var lastProfile []byte
+
+// SetProfileExecution enables or disables CPU profiling of lgo executions.
+// When enabled, each ExecLgoEntryPoint records a CPU profile in memory and the profile
+// of the last execution is available from LastExecutionProfile.
+//
+// CPU profiling samples all goroutines at 100 Hz and slows down executions slightly.
+// Because CPU profiling is process-global, an execution which starts while another
+// execution is profiled (e.g. a nested execution) is not profiled.
+func SetProfileExecution(enabled bool) {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	profileExecution = enabled
+}
+
+// LastExecutionProfile returns the CPU profile in the pprof format recorded in the last profiled execution.
+// It returns nil if no execution has been profiled yet. The returned slice is a copy.
+func LastExecutionProfile() []byte {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	if lastProfile == nil {
+		return nil
+	}
+	return append([]byte(nil), lastProfile...)
+}
+
+// startProfile starts CPU profiling for e if profiling is enabled.
+func (e *ExecutionState) startProfile() {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	if !profileExecution {
+		return
+	}
+	var buf bytes.Buffer
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		// Other execution or the user code is profiling.
+		fmt.Fprintf(os.Stderr, "Failed to start CPU profiling: %v\n", err)
+		return
+	}
+	e.profile = &buf
+}
+
+// stopProfile stops CPU profiling started by startProfile and stores the result.
+func (e *ExecutionState) stopProfile() {
+	if e.profile == nil {
+		return
+	}
+	pprof.StopCPUProfile()
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	lastProfile = e.profile.Bytes()
+	e.profile = nil
+}
@@ -0,0 +1,32 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProfileExecution(t *testing.T) {
+	SetProfileExecution(true)
+	defer SetProfileExecution(false)
+
+	atomic.StoreUint32(&isRunning, 0)
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		start := time.Now()
+		for time.Since(start) < 20*time.Millisecond {
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	p := LastExecutionProfile()
+	if len(p) == 0 {
+		t.Fatal("LastExecutionProfile returned an empty profile")
+	}
+	// Modifying the returned profile does not change the recorded profile.
+	b := p[0]
+	p[0]++
+	if got := LastExecutionProfile()[0]; got != b {
+		t.Errorf("Got %d; want %d", got, b)
+	}
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"sync"
+	"time"
+)
+
+// MultiProgress displays progress bars of parallel tasks (e.g. downloads) in one table
+// and updates the table in place when any bar advances. Renderings are throttled like MetricsPanel.
+// MultiProgress is created with NewMultiProgress. The methods of MultiProgress and ProgressBar
+// are safe for concurrent use.
+type MultiProgress struct {
+	d  DataDisplayer
+	th throttler
+	// id is the display ID of the table. It is accessed only in render.
+	id string
+
+	mu   sync.Mutex
+	bars []*ProgressBar
+}
+
+// ProgressBar is a progress bar of a task in MultiProgress. ProgressBar is created with MultiProgress.Track.
+type ProgressBar struct {
+	m     *MultiProgress
+	name  string
+	total int
+	// current is protected by m.mu.
+	current int
+}
+
+// NewMultiProgress returns a new MultiProgress which displays progress bars with d.
+// Nothing is displayed until the first Track.
+func NewMultiProgress(d DataDisplayer) *MultiProgress {
+	m := &MultiProgress{d: d}
+	m.th.render = m.render
+	m.th.interval = defaultMetricsInterval
+	return m
+}
+
+// SetInterval sets the minimum interval between renderings. The default is 100ms.
+// If interval is not positive, the table is rendered on every update.
+func (m *MultiProgress) SetInterval(interval time.Duration) {
+	m.th.setInterval(interval)
+}
+
+// Track adds a progress bar of the task named name whose amount of work is total.
+func (m *MultiProgress) Track(name string, total int) *ProgressBar {
+	b := &ProgressBar{m: m, name: name, total: total}
+	m.mu.Lock()
+	m.bars = append(m.bars, b)
+	m.mu.Unlock()
+	m.th.update()
+	return b
+}
+
+// Flush renders the latest progress immediately if a rendering is postponed by the throttling.
+func (m *MultiProgress) Flush() {
+	m.th.flush()
+}
+
+// Add advances b by n.
+func (b *ProgressBar) Add(n int) {
+	b.m.mu.Lock()
+	b.current += n
+	b.m.mu.Unlock()
+	b.m.th.update()
+}
+
+// Set sets the progress of b to n.
+func (b *ProgressBar) Set(n int) {
+	b.m.mu.Lock()
+	b.current = n
+	b.m.mu.Unlock()
+	b.m.th.update()
+}
+
+// Done removes b from the display because the task finished.
+func (b *ProgressBar) Done() {
+	m := b.m
+	m.mu.Lock()
+	removed := false
+	for i, e := range m.bars {
+		if e == b {
+			m.bars = append(m.bars[:i], m.bars[i+1:]...)
+			removed = true
+			break
+		}
+	}
+	m.mu.Unlock()
+	if removed {
+		m.th.update()
+	}
+}
+
+// render displays the progress bars as an HTML table.
+func (m *MultiProgress) render() {
+	var buf bytes.Buffer
+	buf.WriteString("<table><tbody>")
+	m.mu.Lock()
+	for _, b := range m.bars {
+		cur := b.current
+		if cur > b.total {
+			cur = b.total
+		}
+		var pct float64
+		if b.total > 0 {
+			pct = float64(cur) * 100 / float64(b.total)
+		}
+		fmt.Fprintf(&buf, `<tr><td>%s</td><td><progress value="%d" max="%d"></progress></td><td>%d/%d (%.0f%%)</td></tr>`,
+			html.EscapeString(b.name), cur, b.total, b.current, b.total, pct)
+	}
+	m.mu.Unlock()
+	buf.WriteString("</tbody></table>")
+	m.d.HTML(buf.String(), &m.id)
+}
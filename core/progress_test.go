@@ -0,0 +1,63 @@
+package core
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMultiProgress(t *testing.T) {
+	var d fakeDisplayer
+	m := NewMultiProgress(&d)
+	m.SetInterval(0)
+	a := m.Track("<a>", 10)
+	b := m.Track("b", 4)
+	a.Add(5)
+	b.Set(4)
+	b.Done()
+	// Done twice has no effect.
+	b.Done()
+
+	if len(d.contents) != 5 {
+		t.Fatalf("Got %d contents; want 5", len(d.contents))
+	}
+	for _, c := range d.contents {
+		if c.id != "id1" {
+			t.Errorf("Got %q; want %q", c.id, "id1")
+		}
+	}
+	want := `<table><tbody><tr><td>&lt;a&gt;</td><td><progress value="5" max="10"></progress></td><td>5/10 (50%)</td></tr>` +
+		`<tr><td>b</td><td><progress value="4" max="4"></progress></td><td>4/4 (100%)</td></tr></tbody></table>`
+	if got := d.contents[3].content; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	if got := d.contents[4].content.(string); strings.Contains(got, "<td>b</td>") {
+		t.Errorf("The finished bar is not removed: %s", got)
+	}
+}
+
+func TestMultiProgressThrottle(t *testing.T) {
+	var d fakeDisplayer
+	m := NewMultiProgress(&d)
+	m.SetInterval(time.Hour)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		b := m.Track("worker", 100)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				b.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	m.Flush()
+	if len(d.contents) != 2 {
+		t.Fatalf("Got %d contents; want 2", len(d.contents))
+	}
+	if got := d.contents[1].content.(string); strings.Count(got, "100/100") != 4 {
+		t.Errorf("Unexpected content: %s", got)
+	}
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// execSeed is the seed of the random source of each execution.
+// To access this var, use atomic.Store/LoadInt64.
+var execSeed int64 = 1
+
+// globalRand is returned from ExecRand when lgo does not execute any code blocks.
+var globalRand = rand.New(&lockedSource{src: rand.NewSource(time.Now().UnixNano()).(rand.Source64)})
+
+// SetExecSeed sets the seed of the random source returned from ExecRand.
+// The seed is applied from the next execution. The default seed is 1.
+func SetExecSeed(seed int64) {
+	atomic.StoreInt64(&execSeed, seed)
+}
+
+// ExecRand returns the random source of the current code execution.
+// The source is seeded with the value set by SetExecSeed at the beginning of each execution
+// so that a code block generates the same random numbers every time it is executed.
+// It returns a source shared process-wide when lgo does not execute any code blocks.
+//
+// The returned source is safe for concurrent use by multiple goroutines except Read.
+// Note that the sequence of random numbers is not reproducible if multiple goroutines use it concurrently.
+func ExecRand() *rand.Rand {
+	e := getExecState()
+	if e == nil {
+		return globalRand
+	}
+	e.randOnce.Do(func() {
+		e.rand = rand.New(&lockedSource{src: rand.NewSource(e.seed).(rand.Source64)})
+	})
+	return e.rand
+}
+
+// lockedSource is a rand.Source64 which is safe for concurrent use.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
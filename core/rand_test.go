@@ -0,0 +1,56 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestExecRand(t *testing.T) {
+	SetExecSeed(42)
+	defer SetExecSeed(1)
+	run := func() []int64 {
+		atomic.StoreUint32(&isRunning, 0)
+		var vals []int64
+		if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+			r := ExecRand()
+			if r != ExecRand() {
+				t.Error("ExecRand returned a different source in the same execution")
+			}
+			for i := 0; i < 3; i++ {
+				vals = append(vals, r.Int63())
+			}
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return vals
+	}
+	first, second := run(), run()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("Random numbers are not reproducible: %v, %v", first, second)
+		}
+	}
+}
+
+func TestExecRandConcurrent(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					ExecRand().Intn(10)
+				}
+			}()
+		}
+		wg.Wait()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// Idle
+	ExecRand().Intn(10)
+}
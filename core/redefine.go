@@ -0,0 +1,36 @@
+package core
+
+import "sync/atomic"
+
+// RedefineMode controls how LgoRegisterVar registers variables defined with names of existing variables.
+//
+// Variables are redefined when a code block declares a variable with the name of a variable declared by
+// a previous code block, including the case where a cell of a notebook is executed again. The old variables
+// are still alive after the redefinition because functions and goroutines defined in previous code blocks
+// may refer them.
+type RedefineMode int32
+
+const (
+	// RedefineAppend keeps all variables of the same name. GetVarValue and ExportVars use the latest one
+	// and ZeroClearAllVars clears all of them. This is the default.
+	RedefineAppend RedefineMode = iota
+	// RedefineReplace keeps only the latest variable of the same name. Old variables are not cleared
+	// by ZeroClearAllVars, so their memory is not released while code of previous code blocks refers them.
+	RedefineReplace
+	// RedefineStrictType is RedefineAppend which emits a warning with Warn when a variable is redefined
+	// with a type different from the latest one (e.g. when a cell declaring x := 1 is changed to x := "a").
+	RedefineStrictType
+)
+
+// redefineMode is the mode set by SetRedefineMode. To access this var, use atomic.Store/LoadInt32.
+var redefineMode int32
+
+// SetRedefineMode sets how variables redefined with the same name are registered.
+// The mode is applied to variables registered after this call.
+func SetRedefineMode(mode RedefineMode) {
+	atomic.StoreInt32(&redefineMode, int32(mode))
+}
+
+func getRedefineMode() RedefineMode {
+	return RedefineMode(atomic.LoadInt32(&redefineMode))
+}
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetRedefineMode(t *testing.T) {
+	defer SetRedefineMode(RedefineAppend)
+	defer func() {
+		AllVars = make(map[string][]interface{})
+		varAccessed = make(map[string]bool)
+	}()
+	defer SetWarningHandler(nil)
+	var warnings []string
+	SetWarningHandler(func(msg string) { warnings = append(warnings, msg) })
+
+	tests := []struct {
+		mode     RedefineMode
+		n        int
+		warnings []string
+	}{
+		{RedefineAppend, 3, nil},
+		{RedefineReplace, 1, nil},
+		{RedefineStrictType, 3, []string{"variable x is redefined with type string; it was int"}},
+	}
+	for _, tc := range tests {
+		AllVars = make(map[string][]interface{})
+		warnings = nil
+		SetRedefineMode(tc.mode)
+		x0, x1, x2 := 1, 2, "a"
+		LgoRegisterVar("x", &x0)
+		LgoRegisterVar("x", &x1)
+		LgoRegisterVar("x", &x2)
+		if n := len(AllVars["x"]); n != tc.n {
+			t.Errorf("mode %d: Got %d; want %d", tc.mode, n, tc.n)
+		}
+		if v, _ := GetVarValue("x"); v != "a" {
+			t.Errorf("mode %d: Got %v; want %q", tc.mode, v, "a")
+		}
+		if !reflect.DeepEqual(warnings, tc.warnings) {
+			t.Errorf("mode %d: Got %q; want %q", tc.mode, warnings, tc.warnings)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// stdStreamsMu protects os.Stdout and os.Stderr replaced by RedirectStdStreams.
+var stdStreamsMu sync.Mutex
+
+// redirectDrainTimeout is how long restoring a redirection waits for outputs in the pipe to be copied.
+// The copy does not finish if the destination blocks or the pipe is still open (e.g. in child processes).
+var redirectDrainTimeout = time.Second
+
+// streamRedirect is a redirection of os.Stdout or os.Stderr to a writer through a pipe.
+type streamRedirect struct {
+	std  **os.File
+	prev *os.File
+	r, w *os.File
+	// done is closed when the outputs written to the pipe are copied.
+	done chan struct{}
+}
+
+// redirectStream replaces *std with a pipe whose outputs are copied to dst. std must be locked by stdStreamsMu.
+func redirectStream(std **os.File, dst io.Writer) (*streamRedirect, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	s := &streamRedirect{std: std, prev: *std, r: r, w: w, done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		if _, err := io.Copy(dst, r); err != nil {
+			// Keep draining the pipe so that writers are not blocked by the full pipe.
+			io.Copy(ioutil.Discard, r)
+		}
+	}()
+	*std = w
+	return s, nil
+}
+
+// restore puts the original stream back and waits until outputs in the pipe are drained
+// or redirectDrainTimeout passes. Outputs left in the pipe after the timeout are discarded.
+func (s *streamRedirect) restore() {
+	stdStreamsMu.Lock()
+	if *s.std == s.w {
+		*s.std = s.prev
+	}
+	stdStreamsMu.Unlock()
+	s.w.Close()
+	timer := time.NewTimer(redirectDrainTimeout)
+	defer timer.Stop()
+	select {
+	case <-s.done:
+	case <-timer.C:
+	}
+	s.r.Close()
+}
+
+// RedirectStdStreams replaces os.Stdout and os.Stderr with pipes whose outputs are copied to out and err
+// so that outputs which libraries write to os.Stdout and os.Stderr directly (e.g. with fmt.Println) are
+// routed to out and err (e.g. writers displaying texts in the notebook). If out or err is nil, the stream is
+// not redirected. It returns the function which restores the original streams after all outputs already
+// written are copied. The function waits for the copy up to 1s. The function can be called more than once.
+//
+// If RedirectStdStreams is called in a code execution, the streams are restored when the execution finishes
+// or is canceled even if the execution panics (See RegisterCleanup). Note that os.Stdout and os.Stderr are
+// process-wide, so outputs of other goroutines are redirected too. Nested redirections must be restored
+// in the reverse order.
+//
+// Redirection is not safe while other goroutines write to os.Stdout or os.Stderr. Goroutines which read
+// the variables before the redirection or its restoration may write to the closed pipe and lose outputs.
+func RedirectStdStreams(out, err io.Writer) func() {
+	var redirects []*streamRedirect
+	stdStreamsMu.Lock()
+	for _, s := range []struct {
+		std **os.File
+		dst io.Writer
+	}{{&os.Stdout, out}, {&os.Stderr, err}} {
+		if s.dst == nil {
+			continue
+		}
+		r, rerr := redirectStream(s.std, s.dst)
+		if rerr != nil {
+			// Keep the stream as is if the pipe can not be created (e.g. too many open files).
+			continue
+		}
+		redirects = append(redirects, r)
+	}
+	stdStreamsMu.Unlock()
+	var once sync.Once
+	restore := func() {
+		once.Do(func() {
+			for i := len(redirects) - 1; i >= 0; i-- {
+				redirects[i].restore()
+			}
+		})
+	}
+	if getExecState() != nil {
+		RegisterCleanup(restore)
+	}
+	return restore
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRedirectStdStreams(t *testing.T) {
+	stdout, stderr := os.Stdout, os.Stderr
+	var out, errOut bytes.Buffer
+	restore := RedirectStdStreams(&out, &errOut)
+	fmt.Println("hello")
+	fmt.Fprintln(os.Stderr, "error")
+	restore()
+	restore()
+	if os.Stdout != stdout || os.Stderr != stderr {
+		t.Error("The streams are not restored")
+	}
+	if got, want := out.String(), "hello\n"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	if got, want := errOut.String(), "error\n"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+}
+
+func TestRedirectStdStreamsNil(t *testing.T) {
+	stderr := os.Stderr
+	var out bytes.Buffer
+	restore := RedirectStdStreams(&out, nil)
+	if os.Stderr != stderr {
+		t.Error("stderr must not be redirected")
+	}
+	os.Stdout.WriteString("a")
+	restore()
+	if got, want := out.String(), "a"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+}
+
+func TestRedirectStdStreamsInExec(t *testing.T) {
+	stdout := os.Stdout
+	atomic.StoreUint32(&isRunning, 0)
+	var out bytes.Buffer
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		RedirectStdStreams(&out, nil)
+		fmt.Print("in exec")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if os.Stdout != stdout {
+		t.Error("stdout is not restored when the execution finishes")
+	}
+	// Restored on panic.
+	err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		RedirectStdStreams(&out, nil)
+		fmt.Print(", panic")
+		panic("boom")
+	})
+	if err == nil {
+		t.Error("The execution must fail")
+	}
+	if os.Stdout != stdout {
+		t.Error("stdout is not restored when the execution panics")
+	}
+	if got, want := out.String(), "in exec, panic"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+}
+
+// blockingWriter blocks writes until release is closed.
+type blockingWriter struct {
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return len(p), nil
+}
+
+func TestRedirectStdStreamsDrainTimeout(t *testing.T) {
+	defer func(d time.Duration) { redirectDrainTimeout = d }(redirectDrainTimeout)
+	redirectDrainTimeout = 10 * time.Millisecond
+	stdout := os.Stdout
+	w := &blockingWriter{release: make(chan struct{})}
+	defer close(w.release)
+	restore := RedirectStdStreams(w, nil)
+	fmt.Println("hello")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		restore()
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("restore is blocked by the writer")
+	}
+	if os.Stdout != stdout {
+		t.Error("The stream is not restored")
+	}
+}
@@ -0,0 +1,84 @@
+package core
+
+import "errors"
+
+// ReplayLastExecution displays the outputs recorded in the transcript of the last finished execution
+// to d in the order they were emitted (e.g. to render the outputs again after a front-end reconnects).
+// Transcripts must be enabled with SetTranscriptEnabled. Outputs are displayed with new display IDs.
+// See ReplayOutputs for details.
+func ReplayLastExecution(d DataDisplayer) error {
+	transcriptMu.Lock()
+	t := finishedTranscript
+	transcriptMu.Unlock()
+	if t == nil {
+		return errors.New("no transcript of the last execution: transcripts are disabled")
+	}
+	return ReplayOutputs(d, t.snapshot(), true)
+}
+
+// ReplayOutputs displays events to d in order. Outputs printed with LgoPrintln are displayed as text/plain.
+// If freshIDs is true, outputs with display IDs are displayed with new IDs so that they do not update
+// the original outputs, and later outputs with the same original ID update the replayed output.
+// If freshIDs is false, the original IDs are reused. Outputs whose payloads were dropped from the transcript
+// are skipped and truncated payloads are displayed as they are.
+func ReplayOutputs(d DataDisplayer, events []OutputEvent, freshIDs bool) error {
+	ids := make(map[string]*string)
+	for _, ev := range events {
+		if ev.Payload == nil {
+			continue
+		}
+		var id *string
+		if ev.ID != "" {
+			if !freshIDs {
+				orig := ev.ID
+				id = &orig
+			} else if id = ids[ev.ID]; id == nil {
+				id = new(string)
+				ids[ev.ID] = id
+			}
+		}
+		if err := displayPayload(d, ev.MIMEType, ev.Payload, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// displayPayload displays payload of contentType with the method of d for the type.
+func displayPayload(d DataDisplayer, contentType string, payload interface{}, id *string) error {
+	switch p := payload.(type) {
+	case string:
+		switch contentType {
+		case "application/javascript":
+			d.JavaScript(p, id)
+		case "text/html":
+			d.HTML(p, id)
+		case "text/markdown":
+			d.Markdown(p, id)
+		case "text/latex":
+			d.Latex(p, id)
+		case "image/svg+xml":
+			d.SVG(p, id)
+		case "text/plain":
+			d.Text(p, id)
+		default:
+			return d.Raw(contentType, p, id)
+		}
+	case []byte:
+		switch contentType {
+		case "image/png":
+			d.PNG(p, id)
+		case "image/jpeg":
+			d.JPEG(p, id)
+		case "image/gif":
+			d.GIF(p, id)
+		case "application/pdf":
+			d.PDF(p, id)
+		default:
+			return d.Raw(contentType, p, id)
+		}
+	default:
+		return d.Raw(contentType, p, id)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestReplayLastExecution(t *testing.T) {
+	SetTranscriptEnabled(true)
+	defer SetTranscriptEnabled(false)
+	atomic.StoreUint32(&isRunning, 0)
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Display: &fakeDisplayer{}}, func() {
+		d := GetExecContext().Display
+		LgoPrintln("hello")
+		d.HTML("<b>a</b>", nil)
+		id := ""
+		d.PNG([]byte("png"), &id)
+		d.PNG([]byte("png2"), &id)
+		d.Raw("application/json", map[string]int{"x": 1}, nil)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var replayed fakeDisplayer
+	// The execution replaying outputs does not overwrite the transcript to replay.
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Display: &replayed}, func() {
+		if err := ReplayLastExecution(GetExecContext().Display); err != nil {
+			t.Error(err)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := []displayed{
+		{"text/plain", "hello\n", ""},
+		{"text/html", "<b>a</b>", ""},
+		{"image/png", []byte("png"), "id1"},
+		{"image/png", []byte("png2"), "id1"},
+		{"application/json", map[string]int{"x": 1}, ""},
+	}
+	if !reflect.DeepEqual(replayed.contents, want) {
+		t.Errorf("Got %v; want %v", replayed.contents, want)
+	}
+}
+
+func TestReplayOutputsReuseIDs(t *testing.T) {
+	var d fakeDisplayer
+	if err := ReplayOutputs(&d, []OutputEvent{
+		{MIMEType: "text/html", Payload: "a", ID: "orig"},
+		{MIMEType: "text/html", Payload: nil, Truncated: true},
+	}, false); err != nil {
+		t.Fatal(err)
+	}
+	want := []displayed{{"text/html", "a", "orig"}}
+	if !reflect.DeepEqual(d.contents, want) {
+		t.Errorf("Got %v; want %v", d.contents, want)
+	}
+}
+
+func TestReplayLastExecutionDisabled(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReplayLastExecution(&fakeDisplayer{}); err == nil {
+		t.Error("ReplayLastExecution succeeded unexpectedly")
+	}
+}
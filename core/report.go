@@ -0,0 +1,172 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ExecReport summarizes the result of a code execution.
+type ExecReport struct {
+	// Start is the time when the execution started.
+	Start time.Time
+	// Depth is the nesting depth of the execution. See ExecDepth.
+	Depth int
+	// Duration is how long the execution took, including the wait for goroutines.
+	Duration time.Duration
+	// Goroutines is the number of goroutines started in the execution.
+	Goroutines int
+	// FailedGoroutines, CanceledGoroutines and HangingGoroutines are the numbers of goroutines
+	// which panicked, were canceled and were still running at the end of the execution.
+	FailedGoroutines   int
+	CanceledGoroutines int
+	HangingGoroutines  int
+	// DetachedGoroutines is the number of goroutines which were running when the execution finished with LingeringDetach.
+	// They are not counted in HangingGoroutines.
+	DetachedGoroutines int
+	// CancelReason describes why the execution was canceled (e.g. "interrupted"). Empty if it was not canceled.
+	CancelReason string
+	// CancelLatency is how long routines took to finish after the execution was canceled. See LastCancellationLatency.
+	CancelLatency time.Duration
+	// Warnings is the messages emitted with Warn in the execution.
+	Warnings []string
+	// OutputBytes is the number of bytes printed with LgoPrintln and displayed with the DataDisplayer.
+	// Values displayed with Raw are not counted.
+	OutputBytes int64
+	// Summary summarizes the results of routines. This is the message of the error returned from ExecLgoEntryPoint.
+	Summary string
+	// RecoveredPanics is the panics recovered from callbacks run by lgo in the execution (e.g. cleanups and
+	// AfterExecution callbacks) with their stack traces, up to 100. Panics of AfterExecution callbacks are
+	// appended after the callbacks are called.
+	RecoveredPanics []string
+	// RestartPanics is the panics of goroutines restarted by SafeGo with their stack traces, up to 100.
+	// They are not counted as failures.
+	RestartPanics []string
+}
+
+// ExecLgoEntryPointReport is ExecLgoEntryPoint which also returns the report of the execution.
+// The report is returned even if the error is not nil.
+func ExecLgoEntryPointReport(parent LgoContext, main func()) (*ExecReport, error) {
+	return finalizeExecReport(startExec(parent, main))
+}
+
+func (e *ExecutionState) report() *ExecReport {
+	r := &ExecReport{
+		Start:       e.start,
+		Depth:       e.depth,
+		Duration:    time.Since(e.start),
+		OutputBytes: atomic.LoadInt64(&e.outputBytes),
+		Summary:     e.counterMessage(),
+	}
+	e.warnMu.Lock()
+	r.Warnings = append([]string(nil), e.warnings...)
+	r.RecoveredPanics = append([]string(nil), e.recovered...)
+	e.warnMu.Unlock()
+	e.cancelMu.Lock()
+	r.CancelReason = e.cancelReason
+	r.CancelLatency = e.cancelLatency()
+	e.cancelMu.Unlock()
+	if r.CancelReason == goroutineLimitReason || r.CancelReason == execTimeoutReason {
+		msg := "canceled: " + r.CancelReason
+		if r.Summary != "" {
+			msg += ", " + r.Summary
+		}
+		r.Summary = msg
+	}
+	e.subCounter.mu.Lock()
+	for _, p := range e.subCounter.restarts {
+		r.RestartPanics = append(r.RestartPanics, fmt.Sprintf("%s\n\n%s", p.msg, p.stack))
+	}
+	r.Goroutines = int(e.subCounter.total)
+	r.FailedGoroutines = int(e.subCounter.fail)
+	r.CanceledGoroutines = int(e.subCounter.cancel)
+	active := int(e.subCounter.active)
+	e.subCounter.mu.Unlock()
+	if e.isDetached() {
+		r.DetachedGoroutines = active
+	} else {
+		r.HangingGoroutines = active
+	}
+	return r
+}
+
+// execReportJSON is the JSON representation of ExecReport. Durations are in milliseconds.
+type execReportJSON struct {
+	Start              time.Time `json:"start"`
+	Depth              int       `json:"depth"`
+	DurationMs         float64   `json:"duration_ms"`
+	Goroutines         int       `json:"goroutines"`
+	FailedGoroutines   int       `json:"failed_goroutines"`
+	CanceledGoroutines int       `json:"canceled_goroutines"`
+	HangingGoroutines  int       `json:"hanging_goroutines"`
+	DetachedGoroutines int       `json:"detached_goroutines"`
+	CancelReason       string    `json:"cancel_reason"`
+	CancelLatencyMs    float64   `json:"cancel_latency_ms"`
+	Warnings           []string  `json:"warnings"`
+	OutputBytes        int64     `json:"output_bytes"`
+	Summary            string    `json:"summary"`
+	RecoveredPanics    []string  `json:"recovered_panics"`
+	RestartPanics      []string  `json:"restart_panics"`
+}
+
+// MarshalJSON encodes r as a JSON object with snake_case keys so that execution results can be
+// sent to structured logging pipelines. Duration and CancelLatency are encoded as numbers of milliseconds
+// in "duration_ms" and "cancel_latency_ms". All keys are always present.
+func (r *ExecReport) MarshalJSON() ([]byte, error) {
+	warnings := r.Warnings
+	if warnings == nil {
+		warnings = []string{}
+	}
+	recovered := r.RecoveredPanics
+	if recovered == nil {
+		recovered = []string{}
+	}
+	restarts := r.RestartPanics
+	if restarts == nil {
+		restarts = []string{}
+	}
+	return json.Marshal(&execReportJSON{
+		Start:              r.Start,
+		Depth:              r.Depth,
+		DurationMs:         float64(r.Duration) / float64(time.Millisecond),
+		Goroutines:         r.Goroutines,
+		FailedGoroutines:   r.FailedGoroutines,
+		CanceledGoroutines: r.CanceledGoroutines,
+		HangingGoroutines:  r.HangingGoroutines,
+		DetachedGoroutines: r.DetachedGoroutines,
+		CancelReason:       r.CancelReason,
+		CancelLatencyMs:    float64(r.CancelLatency) / float64(time.Millisecond),
+		Warnings:           warnings,
+		OutputBytes:        r.OutputBytes,
+		Summary:            r.Summary,
+		RecoveredPanics:    recovered,
+		RestartPanics:      restarts,
+	})
+}
+
+// UnmarshalJSON decodes the JSON encoded with MarshalJSON.
+func (r *ExecReport) UnmarshalJSON(b []byte) error {
+	var v execReportJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*r = ExecReport{
+		Start:              v.Start,
+		Depth:              v.Depth,
+		Duration:           time.Duration(v.DurationMs * float64(time.Millisecond)),
+		Goroutines:         v.Goroutines,
+		FailedGoroutines:   v.FailedGoroutines,
+		CanceledGoroutines: v.CanceledGoroutines,
+		HangingGoroutines:  v.HangingGoroutines,
+		DetachedGoroutines: v.DetachedGoroutines,
+		CancelReason:       v.CancelReason,
+		CancelLatency:      time.Duration(v.CancelLatencyMs * float64(time.Millisecond)),
+		Warnings:           v.Warnings,
+		OutputBytes:        v.OutputBytes,
+		Summary:            v.Summary,
+		RecoveredPanics:    v.RecoveredPanics,
+		RestartPanics:      v.RestartPanics,
+	}
+	return nil
+}
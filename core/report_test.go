@@ -0,0 +1,140 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExecLgoEntryPointReport(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var d fakeDisplayer
+	r, err := ExecLgoEntryPointReport(LgoContext{Context: context.Background(), Display: &d}, func() {
+		LgoPrintln("hello")
+		GetExecContext().Display.HTML("<b>x</b>", nil)
+		for i := 0; i < 2; i++ {
+			state := InitGoroutine()
+			go func() {
+				defer FinalizeGoroutine(state)
+			}()
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Goroutines != 2 || r.FailedGoroutines != 0 || r.CanceledGoroutines != 0 || r.HangingGoroutines != 0 {
+		t.Errorf("Unexpected goroutine counts: %+v", r)
+	}
+	if r.CancelReason != "" || r.Summary != "" {
+		t.Errorf("Unexpected result: %+v", r)
+	}
+	if r.OutputBytes != int64(len("hello\n")+len("<b>x</b>")) {
+		t.Errorf("Got %d; want %d", r.OutputBytes, len("hello\n")+len("<b>x</b>"))
+	}
+	if r.Duration <= 0 || r.Start.IsZero() {
+		t.Errorf("Unexpected timing: %+v", r)
+	}
+}
+
+func TestExecLgoEntryPointReportCanceled(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	r, err := ExecLgoEntryPointReport(LgoContext{Context: ctx}, func() {
+		state := InitGoroutine()
+		go func() {
+			defer FinalizeGoroutine(state)
+			<-GetExecContext().Done()
+			panic(Bailout)
+		}()
+		cancel()
+		<-GetExecContext().Done()
+		panic(Bailout)
+	})
+	want := "main routine canceled, 1 goroutine canceled"
+	if err == nil || err.Error() != want {
+		t.Errorf("Got %v; want %q", err, want)
+	}
+	if r.CancelReason != "interrupted" || r.Summary != want || r.Goroutines != 1 || r.CanceledGoroutines != 1 {
+		t.Errorf("Unexpected report: %+v", r)
+	}
+}
+
+func TestExecDepth(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var outer, inner, restored int
+	var innerReport *ExecReport
+	r, err := ExecLgoEntryPointReport(LgoContext{Context: context.Background()}, func() {
+		outer = ExecDepth()
+		var err error
+		innerReport, err = ExecLgoEntryPointReport(GetExecContext(), func() {
+			inner = ExecDepth()
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		restored = ExecDepth()
+		// The outer execution is still running after the inner execution finished.
+		ExitIfCtxDone()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outer != 1 || inner != 2 || restored != 1 {
+		t.Errorf("Got (%d, %d, %d); want (1, 2, 1)", outer, inner, restored)
+	}
+	if r.Depth != 1 || innerReport.Depth != 2 {
+		t.Errorf("Got (%d, %d); want (1, 2)", r.Depth, innerReport.Depth)
+	}
+	if got := ExecDepth(); got != 0 {
+		t.Errorf("Got %d; want 0", got)
+	}
+}
+
+func TestExecReportJSON(t *testing.T) {
+	r := &ExecReport{
+		Start:            time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC),
+		Depth:            1,
+		Duration:         1500 * time.Millisecond,
+		Goroutines:       3,
+		FailedGoroutines: 1,
+		CancelReason:     "interrupted",
+		CancelLatency:    250 * time.Millisecond,
+		Warnings:         []string{"w"},
+		OutputBytes:      10,
+		Summary:          "1 goroutine failed",
+		RecoveredPanics:  []string{"panic in cleanup: x"},
+		RestartPanics:    []string{"panic: y (restart 1/1)"},
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); !strings.Contains(s, `"duration_ms":1500,`) || !strings.Contains(s, `"cancel_reason":"interrupted"`) ||
+		!strings.Contains(s, `"cancel_latency_ms":250,`) {
+		t.Errorf("Unexpected JSON: %s", s)
+	}
+	var got ExecReport
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&got, r) {
+		t.Errorf("Got %+v; want %+v", &got, r)
+	}
+
+	// All keys are present even if the report is empty.
+	b, err = json.Marshal(&ExecReport{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 15 || m["duration_ms"] != 0.0 || m["warnings"] == nil || m["recovered_panics"] == nil || m["restart_panics"] == nil {
+		t.Errorf("Unexpected JSON: %s", b)
+	}
+}
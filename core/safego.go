@@ -0,0 +1,74 @@
+package core
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+var restartMu sync.Mutex
+var restartMax int
+var restartBackoff time.Duration
+
+// SetRestartPolicy sets how many times SafeGo restarts a function which panics and how long it waits before a restart.
+// By default, SafeGo does not restart functions.
+func SetRestartPolicy(max int, backoff time.Duration) {
+	restartMu.Lock()
+	defer restartMu.Unlock()
+	restartMax = max
+	restartBackoff = backoff
+}
+
+func getRestartPolicy() (int, time.Duration) {
+	restartMu.Lock()
+	defer restartMu.Unlock()
+	return restartMax, restartBackoff
+}
+
+// tryRun runs fn and returns the value of recover() and the stack trace if fn panics.
+func tryRun(fn func()) (r interface{}, stack []byte) {
+	defer func() {
+		if r = recover(); r != nil {
+			stack = debug.Stack()
+		}
+	}()
+	fn()
+	return nil, nil
+}
+
+// SafeGo starts fn in a new goroutine managed by lgo.
+// If fn panics, SafeGo restarts fn according to the policy set by SetRestartPolicy.
+// Panics followed by restarts are reported like panics of lgo routines but they are not counted as failures
+// of the execution. They are kept in ExecReport.RestartPanics.
+// The goroutine fails only if fn panics after the number of restarts reaches the max.
+// SafeGo does not restart fn once the execution is canceled.
+func SafeGo(fn func()) {
+	e := InitGoroutine()
+	if e == nil {
+		return
+	}
+	max, backoff := getRestartPolicy()
+	go func() {
+		defer FinalizeGoroutine(e)
+		for i := 0; i < max; i++ {
+			r, stack := tryRun(fn)
+			if r == nil {
+				return
+			}
+			if r == Bailout {
+				panic(Bailout)
+			}
+			msg := fmt.Sprintf("%s (restart %d/%d)", formatPanic(r), i+1, max)
+			e.subCounter.recordRestartPanic(msg, stack)
+			reportPanic(msg, e.subCounter.depth, stack)
+			select {
+			case <-e.Context.Done():
+				panic(Bailout)
+			case <-time.After(backoff):
+			}
+		}
+		// Run fn directly in the last attempt so that FinalizeGoroutine records the panic.
+		fn()
+	}()
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSafeGoRestart(t *testing.T) {
+	SetRestartPolicy(3, 0)
+	defer SetRestartPolicy(0, 0)
+
+	atomic.StoreUint32(&isRunning, 0)
+	var calls int32
+	r, err := ExecLgoEntryPointReport(LgoContext{Context: context.Background()}, func() {
+		SafeGo(func() {
+			if atomic.AddInt32(&calls, 1) <= 2 {
+				panic("fail")
+			}
+		})
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Got %d; want 3", calls)
+	}
+	if len(r.RestartPanics) != 2 || !strings.HasPrefix(r.RestartPanics[1], "fail (restart 2/3)\n\n") {
+		t.Errorf("Unexpected restart panics: %q", r.RestartPanics)
+	}
+}
+
+func TestSafeGoExhausted(t *testing.T) {
+	SetRestartPolicy(1, 0)
+	defer SetRestartPolicy(0, 0)
+
+	atomic.StoreUint32(&isRunning, 0)
+	var calls int32
+	err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		SafeGo(func() {
+			atomic.AddInt32(&calls, 1)
+			panic("fail")
+		})
+	})
+	want := "1 goroutine failed"
+	if err == nil || err.Error() != want {
+		t.Errorf("Got %v; want %q", err, want)
+	}
+	if calls != 2 {
+		t.Errorf("Got %d; want 2", calls)
+	}
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"io"
+)
+
+// CtxScanner is a bufio.Scanner which stops scanning when the lgo execution is canceled.
+// CtxScanner is created with ScanCtx.
+type CtxScanner struct {
+	ctx  context.Context
+	r    io.Reader
+	s    *bufio.Scanner
+	req  chan struct{}
+	resp chan scanResult
+	done bool
+	tok  []byte
+	err  error
+}
+
+type scanResult struct {
+	tok []byte
+	ok  bool
+	err error
+}
+
+// ScanCtx returns a new CtxScanner to read r.
+// Unlike bufio.Scanner, Scan of CtxScanner returns false and Err returns the error of the context
+// when the current lgo execution is canceled even if the read from r is blocked.
+//
+// If r implements io.Closer, r is closed when the execution is canceled during a read so that the goroutine
+// which reads r quits. Otherwise, the goroutine is left until the read returns. Wrap r
+// (e.g. struct{ io.Reader }{r}) to keep r open.
+func ScanCtx(r io.Reader) *CtxScanner {
+	return &CtxScanner{
+		ctx: GetExecContext(),
+		r:   r,
+		s:   bufio.NewScanner(r),
+	}
+}
+
+// Split sets the split function for the scanner. See bufio.Scanner.Split.
+// Split must be called before the first Scan.
+func (s *CtxScanner) Split(split bufio.SplitFunc) {
+	s.s.Split(split)
+}
+
+// Buffer sets the initial buffer and the maximum size of the buffer. See bufio.Scanner.Buffer.
+// Buffer must be called before the first Scan.
+func (s *CtxScanner) Buffer(buf []byte, max int) {
+	s.s.Buffer(buf, max)
+}
+
+// loop runs bufio.Scanner in a separated goroutine.
+// It scans the next token only when Scan requests it so that tokens are never read ahead and dropped.
+func (s *CtxScanner) loop() {
+	for range s.req {
+		ok := s.s.Scan()
+		var tok []byte
+		if ok {
+			// Copy the token because the underlying buffer is overwritten by the next Scan.
+			tok = append([]byte(nil), s.s.Bytes()...)
+		}
+		s.resp <- scanResult{tok, ok, s.s.Err()}
+		if !ok {
+			return
+		}
+	}
+}
+
+func (s *CtxScanner) finish(err error) {
+	s.done = true
+	s.tok = nil
+	s.err = err
+	if s.req != nil {
+		close(s.req)
+	}
+}
+
+// Scan advances the scanner to the next token like bufio.Scanner.Scan.
+// It returns false when the scan stops by reaching the end of the input, an error or the cancellation of the execution.
+func (s *CtxScanner) Scan() bool {
+	if s.done {
+		return false
+	}
+	select {
+	case <-s.ctx.Done():
+		s.finish(s.ctx.Err())
+		return false
+	default:
+	}
+	if s.req == nil {
+		s.req = make(chan struct{})
+		// Buffered so that loop can quit after a cancellation.
+		s.resp = make(chan scanResult, 1)
+		go s.loop()
+	}
+	s.req <- struct{}{}
+	select {
+	case r := <-s.resp:
+		if !r.ok {
+			// loop quits by itself.
+			s.req = nil
+			s.finish(r.err)
+			return false
+		}
+		s.tok = r.tok
+		return true
+	case <-s.ctx.Done():
+		// Unblock the read in loop.
+		if c, ok := s.r.(io.Closer); ok {
+			c.Close()
+		}
+		s.finish(s.ctx.Err())
+		return false
+	}
+}
+
+// Bytes returns the most recent token generated by a call to Scan.
+func (s *CtxScanner) Bytes() []byte {
+	return s.tok
+}
+
+// Text returns the most recent token generated by a call to Scan as a string.
+func (s *CtxScanner) Text() string {
+	return string(s.tok)
+}
+
+// Err returns the first non-EOF error that was encountered by the scanner.
+// It returns the error of the execution context (context.Canceled) if the scan is interrupted.
+func (s *CtxScanner) Err() error {
+	return s.err
+}
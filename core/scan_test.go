@@ -0,0 +1,120 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// chanReader is an io.Reader which blocks until data is sent to the channel.
+type chanReader chan string
+
+func (r chanReader) Read(p []byte) (int, error) {
+	s, ok := <-r
+	if !ok {
+		return 0, io.EOF
+	}
+	return copy(p, s), nil
+}
+
+func TestScanCtx(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var got []string
+	var err error
+	if execErr := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		s := ScanCtx(strings.NewReader("a\nb\nc\n"))
+		for s.Scan() {
+			got = append(got, s.Text())
+		}
+		err = s.Err()
+	}); execErr != nil {
+		t.Fatal(execErr)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v; want %v", got, want)
+	}
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestScanCtxCancel(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	r := make(chanReader)
+	scanned := make(chan string)
+	var ok bool
+	var err error
+	state := startExec(LgoContext{Context: context.Background()}, func() {
+		s := ScanCtx(r)
+		if s.Scan() {
+			scanned <- s.Text()
+		}
+		// Blocks until the execution is canceled.
+		ok = s.Scan()
+		err = s.Err()
+	})
+	r <- "hello\nwor"
+	if got := <-scanned; got != "hello" {
+		t.Errorf("Got %q; want \"hello\"", got)
+	}
+	state.cancel()
+	if err := finalizeExec(state); err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Error("Scan returned true after the cancellation")
+	}
+	if err != context.Canceled {
+		t.Errorf("Got %v; want %v", err, context.Canceled)
+	}
+	close(r)
+}
+
+// closeReader is an io.ReadCloser whose Read blocks until it is closed.
+type closeReader struct {
+	reading  chan struct{}
+	closed   chan struct{}
+	returned chan struct{}
+}
+
+func (r *closeReader) Read(p []byte) (int, error) {
+	close(r.reading)
+	<-r.closed
+	close(r.returned)
+	return 0, errors.New("closed")
+}
+
+func (r *closeReader) Close() error {
+	close(r.closed)
+	return nil
+}
+
+func TestScanCtxCancelClose(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	r := &closeReader{reading: make(chan struct{}), closed: make(chan struct{}), returned: make(chan struct{})}
+	var err error
+	state := startExec(LgoContext{Context: context.Background()}, func() {
+		s := ScanCtx(r)
+		s.Scan()
+		err = s.Err()
+	})
+	<-r.reading
+	state.cancel()
+	if err := finalizeExec(state); err != nil {
+		t.Fatal(err)
+	}
+	if err != context.Canceled {
+		t.Errorf("Got %v; want %v", err, context.Canceled)
+	}
+	// The blocked read returns because the reader is closed.
+	select {
+	case <-r.returned:
+	case <-time.After(time.Second):
+		t.Error("The read is not unblocked")
+	}
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+// unknownSchemaType is displayed as the type of columns whose types can not be inferred.
+const unknownSchemaType = "unknown"
+
+// schemaField is a column or a field in schemas.
+type schemaField struct {
+	name, typ string
+}
+
+// schemaTable is the Tabular of schemas displayed with DisplaySchema.
+type schemaTable struct {
+	header string
+	fields []schemaField
+}
+
+func (t schemaTable) Columns() []string { return []string{t.header, "Type"} }
+func (t schemaTable) NumRows() int      { return len(t.fields) }
+func (t schemaTable) Row(i int) []interface{} {
+	return []interface{}{t.fields[i].name, t.fields[i].typ}
+}
+
+// DisplaySchema displays the names and the Go types of the columns of t as a compact HTML table
+// to inspect the shape of data before displaying them. t is a Tabular, a struct, a pointer to a struct,
+// a slice or an array of structs, or the reflect.Type of them.
+//
+// For a struct, the fields of the struct type are displayed. Embedded fields are named by their types.
+// For a Tabular, the types of columns are inferred from the values of the first row.
+// The types are "unknown" if t has no rows or if the values are nil.
+func DisplaySchema(d DataDisplayer, t interface{}, id *string) error {
+	s, err := schemaOf(t)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := writeHTMLTable(&buf, s, ` class="lgo-schema"`); err != nil {
+		return err
+	}
+	d.HTML(buf.String(), id)
+	return nil
+}
+
+func schemaOf(t interface{}) (schemaTable, error) {
+	if tab, ok := t.(Tabular); ok {
+		return tabularSchema(tab)
+	}
+	typ, ok := t.(reflect.Type)
+	if !ok {
+		typ = reflect.TypeOf(t)
+	}
+	st := typ
+	for st != nil && (st.Kind() == reflect.Ptr || st.Kind() == reflect.Slice || st.Kind() == reflect.Array) {
+		st = st.Elem()
+	}
+	if st == nil || st.Kind() != reflect.Struct {
+		return schemaTable{}, fmt.Errorf("cannot display the schema of %v", typ)
+	}
+	s := schemaTable{header: "Field"}
+	for i := 0; i < st.NumField(); i++ {
+		f := st.Field(i)
+		s.fields = append(s.fields, schemaField{f.Name, f.Type.String()})
+	}
+	return s, nil
+}
+
+// tabularSchema infers the schema of t from the first row.
+func tabularSchema(t Tabular) (schemaTable, error) {
+	cols := t.Columns()
+	s := schemaTable{header: "Column", fields: make([]schemaField, len(cols))}
+	for i, c := range cols {
+		s.fields[i] = schemaField{c, unknownSchemaType}
+	}
+	if t.NumRows() == 0 {
+		return s, nil
+	}
+	row := t.Row(0)
+	if len(row) != len(cols) {
+		return schemaTable{}, fmt.Errorf("row 0 has %d values but the table has %d columns", len(row), len(cols))
+	}
+	for i, v := range row {
+		if v != nil {
+			s.fields[i].typ = reflect.TypeOf(v).String()
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type schemaEmbedded struct{}
+
+type schemaRecord struct {
+	Name  string
+	Score float64
+	tags  []string
+	When  *time.Time
+	schemaEmbedded
+}
+
+// schemaHTML returns the HTML of a schema with header and the pairs of names and types.
+func schemaHTML(header string, pairs ...string) string {
+	s := `<table class="lgo-schema"><thead><tr><th>` + header + `</th><th>Type</th></tr></thead><tbody>`
+	for i := 0; i < len(pairs); i += 2 {
+		s += "<tr><td>" + pairs[i] + "</td><td>" + pairs[i+1] + "</td></tr>"
+	}
+	return s + "</tbody></table>"
+}
+
+func TestDisplaySchema(t *testing.T) {
+	record := schemaHTML("Field",
+		"Name", "string", "Score", "float64", "tags", "[]string", "When", "*time.Time", "schemaEmbedded", "core.schemaEmbedded")
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"struct", schemaRecord{}, record},
+		{"pointer", &schemaRecord{}, record},
+		{"slice", []schemaRecord{}, record},
+		{"type", reflect.TypeOf(schemaRecord{}), record},
+		{"tabular", &sliceTable{
+			cols: []string{"a", "b", "c"},
+			rows: [][]interface{}{{1, "x", nil}, {2, "y", 1.5}},
+		}, schemaHTML("Column", "a", "int", "b", "string", "c", "unknown")},
+		{"empty", &sliceTable{cols: []string{"a"}}, schemaHTML("Column", "a", "unknown")},
+	}
+	for _, tc := range tests {
+		var d fakeDisplayer
+		id := ""
+		if err := DisplaySchema(&d, tc.v, &id); err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if len(d.contents) != 1 {
+			t.Errorf("%s: Got %d contents; want 1", tc.name, len(d.contents))
+			continue
+		}
+		if c := d.contents[0]; c.content != tc.want || c.id != "id1" || id != "id1" {
+			t.Errorf("%s: Got %q (id: %q); want %q", tc.name, c.content, c.id, tc.want)
+		}
+	}
+}
+
+func TestDisplaySchemaError(t *testing.T) {
+	var d fakeDisplayer
+	for _, v := range []interface{}{10, nil, []int{1}} {
+		if err := DisplaySchema(&d, v, nil); err == nil {
+			t.Errorf("DisplaySchema(%v) must fail", v)
+		}
+	}
+	err := DisplaySchema(&d, &sliceTable{cols: []string{"a"}, rows: [][]interface{}{{}}}, nil)
+	if want := "row 0 has 0 values but the table has 1 columns"; err == nil || err.Error() != want {
+		t.Errorf("Got %v; want %q", err, want)
+	}
+	if got, want := DisplaySchema(&d, 10, nil).Error(), "cannot display the schema of int"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	if len(d.contents) != 0 {
+		t.Errorf("Unexpected contents: %v", d.contents)
+	}
+}
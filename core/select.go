@@ -0,0 +1,31 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// SelectRecv receives a value from one of chans like a select statement with receive cases.
+// idx is the index of the channel in chans, val is the received value and ok is false if the channel is closed.
+// Unlike reflect.Select, SelectRecv exits the code execution with Bailout when the execution is canceled.
+// SelectRecv panics if an element of chans is not a channel which can receive values.
+// nil channels in chans are never selected like select statements.
+func SelectRecv(chans []interface{}) (idx int, val interface{}, ok bool) {
+	cases := make([]reflect.SelectCase, 0, len(chans)+1)
+	for i, ch := range chans {
+		v := reflect.ValueOf(ch)
+		if v.Kind() != reflect.Chan || v.Type().ChanDir()&reflect.RecvDir == 0 {
+			panic(fmt.Sprintf("SelectRecv: chans[%d] is not a receivable channel: %T", i, ch))
+		}
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: v})
+	}
+	cases = append(cases, reflect.SelectCase{
+		Dir:  reflect.SelectRecv,
+		Chan: reflect.ValueOf(GetExecContext().Done()),
+	})
+	i, v, ok := reflect.Select(cases)
+	if i == len(chans) {
+		panic(Bailout)
+	}
+	return i, v.Interface(), ok
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSelectRecv(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var idx int
+	var val interface{}
+	var ok bool
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		a := make(chan int)
+		b := make(chan string, 1)
+		var c chan bool
+		b <- "hello"
+		idx, val, ok = SelectRecv([]interface{}{a, b, c})
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if idx != 1 || val != "hello" || !ok {
+		t.Errorf("Got (%d, %v, %v); want (1, hello, true)", idx, val, ok)
+	}
+}
+
+func TestSelectRecvCancel(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	err := ExecLgoEntryPoint(LgoContext{Context: ctx}, func() {
+		a := make(chan int)
+		b := make(<-chan string)
+		cancel()
+		SelectRecv([]interface{}{a, b})
+		t.Error("SelectRecv returned after the cancellation")
+	})
+	if err == nil || err.Error() != "main routine canceled" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestSelectRecvInvalid(t *testing.T) {
+	for _, ch := range []interface{}{1, make(chan<- int), nil} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("SelectRecv did not panic with %T", ch)
+				}
+			}()
+			SelectRecv([]interface{}{ch})
+		}()
+	}
+}
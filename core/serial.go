@@ -0,0 +1,37 @@
+package core
+
+import "sync"
+
+// serialMu protects serialNext and serialServing.
+var serialMu sync.Mutex
+
+// serialCond is signaled when serialServing is incremented.
+var serialCond = sync.NewCond(&serialMu)
+
+// serialNext is the ticket of the next call of ExecLgoEntryPointSerial and
+// serialServing is the ticket of the running call.
+var serialNext, serialServing uint64
+
+// ExecLgoEntryPointSerial is ExecLgoEntryPoint which runs executions one at a time.
+// If other executions started with ExecLgoEntryPointSerial are running or waiting, it blocks
+// until they finish and executions start in the order of calls (FIFO).
+//
+// lgo keeps the state of the current execution in a global variable, so front-ends which may
+// dispatch overlapping executions should use this. ExecLgoEntryPointSerial must not be called in
+// executions started with it because it waits for the outer execution forever.
+func ExecLgoEntryPointSerial(parent LgoContext, main func()) error {
+	serialMu.Lock()
+	ticket := serialNext
+	serialNext++
+	for serialServing != ticket {
+		serialCond.Wait()
+	}
+	serialMu.Unlock()
+	defer func() {
+		serialMu.Lock()
+		serialServing++
+		serialMu.Unlock()
+		serialCond.Broadcast()
+	}()
+	return ExecLgoEntryPoint(parent, main)
+}
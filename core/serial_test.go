@@ -0,0 +1,61 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExecLgoEntryPointSerial(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var mu sync.Mutex
+	var order []int
+	var running, maxRunning int
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		serialMu.Lock()
+		ticket := serialNext
+		serialMu.Unlock()
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := ExecLgoEntryPointSerial(LgoContext{Context: context.Background()}, func() {
+				mu.Lock()
+				order = append(order, i)
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				mu.Unlock()
+				<-release
+				mu.Lock()
+				running--
+				mu.Unlock()
+			}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+		// Wait until the goroutine gets its ticket to dispatch executions in order.
+		for {
+			serialMu.Lock()
+			next := serialNext
+			serialMu.Unlock()
+			if next > ticket {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	close(release)
+	wg.Wait()
+	if want := []int{0, 1, 2}; !reflect.DeepEqual(order, want) {
+		t.Errorf("Got %v; want %v", order, want)
+	}
+	if maxRunning != 1 {
+		t.Errorf("Got %d; want 1", maxRunning)
+	}
+}
@@ -0,0 +1,24 @@
+package core
+
+import (
+	"database/sql"
+)
+
+// QueryCtx executes a query with the current execution context so that the query is canceled
+// when the execution is interrupted. See sql.DB.QueryContext.
+// As with QueryContext, callers must close the returned Rows.
+func QueryCtx(db *sql.DB, query string, args ...interface{}) (*sql.Rows, error) {
+	return db.QueryContext(GetExecContext(), query, args...)
+}
+
+// QueryRowCtx executes a query which returns at most one row with the current execution context.
+// See sql.DB.QueryRowContext.
+func QueryRowCtx(db *sql.DB, query string, args ...interface{}) *sql.Row {
+	return db.QueryRowContext(GetExecContext(), query, args...)
+}
+
+// ExecCtx executes a query without returning rows with the current execution context so that the query is canceled
+// when the execution is interrupted. See sql.DB.ExecContext.
+func ExecCtx(db *sql.DB, query string, args ...interface{}) (sql.Result, error) {
+	return db.ExecContext(GetExecContext(), query, args...)
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+// blockingDriver is a database driver whose queries block until the context is canceled.
+type blockingDriver struct{}
+
+func (blockingDriver) Open(name string) (driver.Conn, error) { return blockingConn{}, nil }
+
+type blockingConn struct{}
+
+func (blockingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+func (blockingConn) Close() error              { return nil }
+func (blockingConn) Begin() (driver.Tx, error) { return nil, errors.New("not implemented") }
+
+func (blockingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (blockingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func init() {
+	sql.Register("lgo-blocking", blockingDriver{})
+}
+
+func TestSQLCtx(t *testing.T) {
+	db, err := sql.Open("lgo-blocking", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, tc := range []struct {
+		name string
+		run  func() error
+	}{
+		{"query", func() error {
+			_, err := QueryCtx(db, "SELECT 1")
+			return err
+		}},
+		{"exec", func() error {
+			_, err := ExecCtx(db, "DELETE")
+			return err
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			atomic.StoreUint32(&isRunning, 0)
+			started := make(chan struct{})
+			var err error
+			state := startExec(LgoContext{Context: context.Background()}, func() {
+				close(started)
+				err = tc.run()
+			})
+			<-started
+			state.cancel()
+			if execErr := finalizeExec(state); execErr != nil {
+				t.Fatal(execErr)
+			}
+			if err != context.Canceled {
+				t.Errorf("Got %v; want %v", err, context.Canceled)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// goroutineStack is a stack trace of a goroutine in the output of runtime.Stack.
+type goroutineStack struct {
+	id     uint64
+	parent uint64
+	stack  string
+}
+
+// allStacks returns the stack traces of all goroutines.
+func allStacks() []goroutineStack {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	var stacks []goroutineStack
+	for _, b := range bytes.Split(buf, []byte("\n\n")) {
+		s := goroutineStack{stack: string(b)}
+		// The first line is "goroutine 18 [running]:".
+		header := strings.Fields(s.stack)
+		if len(header) < 2 || header[0] != "goroutine" {
+			continue
+		}
+		s.id, _ = strconv.ParseUint(header[1], 10, 64)
+		// A goroutine created in another goroutine has "created by f in goroutine 7".
+		if i := strings.LastIndex(s.stack, "\ncreated by "); i >= 0 {
+			line := s.stack[i+1:]
+			if j := strings.IndexByte(line, '\n'); j >= 0 {
+				line = line[:j]
+			}
+			if j := strings.LastIndex(line, " in goroutine "); j >= 0 {
+				s.parent, _ = strconv.ParseUint(line[j+len(" in goroutine "):], 10, 64)
+			}
+		}
+		stacks = append(stacks, s)
+	}
+	return stacks
+}
+
+// CurrentStacks returns the stack traces of the goroutines of the current code execution
+// keyed by "main" for the main routine and "goroutine N" for goroutines.
+// It returns nil if lgo does not execute any code blocks.
+// CurrentStacks is safe to call from other goroutines (e.g. to show what a running code block is doing).
+//
+// This is a best-effort snapshot. Goroutines are identified by the goroutines which created them,
+// so goroutines whose creators have already finished (except the main routine) are not included.
+func CurrentStacks() map[string]string {
+	e := getExecState()
+	if e == nil {
+		return nil
+	}
+	main := atomic.LoadUint64(&e.mainGoroutine)
+	if main == 0 {
+		return nil
+	}
+	stacks := allStacks()
+	members := map[uint64]bool{main: true}
+	// Goroutines are not sorted by creation, so iterate until no goroutines are added.
+	for added := true; added; {
+		added = false
+		for _, s := range stacks {
+			if !members[s.id] && members[s.parent] {
+				members[s.id] = true
+				added = true
+			}
+		}
+	}
+	m := make(map[string]string)
+	for _, s := range stacks {
+		if !members[s.id] {
+			continue
+		}
+		key := fmt.Sprintf("goroutine %d", s.id)
+		if s.id == main {
+			key = "main"
+		}
+		m[key] = s.stack
+	}
+	return m
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func stacksTestWorker(ch chan struct{}) {
+	<-ch
+}
+
+func TestCurrentStacks(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	e := startExec(LgoContext{Context: context.Background()}, func() {
+		state := InitGoroutine()
+		go func() {
+			defer FinalizeGoroutine(state)
+			stacksTestWorker(release)
+		}()
+		close(started)
+		<-release
+	})
+	<-started
+	// A goroutine which does not belong to the execution.
+	other := make(chan struct{})
+	go stacksTestWorker(other)
+	defer close(other)
+
+	var stacks map[string]string
+	for i := 0; i < 100; i++ {
+		// Wait until the goroutines are blocked.
+		stacks = CurrentStacks()
+		if len(stacks) == 2 && strings.Contains(fmt.Sprint(stacks), "stacksTestWorker") {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	if err := finalizeExec(e); err != nil {
+		t.Fatal(err)
+	}
+	if len(stacks) != 2 {
+		t.Fatalf("Unexpected stacks: %v", stacks)
+	}
+	for key, s := range stacks {
+		if key != "main" && (!strings.HasPrefix(key, "goroutine ") || !strings.Contains(s, "stacksTestWorker")) {
+			t.Errorf("Unexpected stack of %q: %s", key, s)
+		}
+	}
+	if stacks := CurrentStacks(); stacks != nil {
+		t.Errorf("Got %v; want nil", stacks)
+	}
+}
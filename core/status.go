@@ -0,0 +1,61 @@
+package core
+
+import (
+	"math"
+	"time"
+)
+
+// ProgressReporter is the interface that reports the progress of executions to the front-end
+// (e.g. in the metadata of the kernel status) so that the progress is visible even when outputs of
+// the cell are scrolled away. fraction is in [0, 1].
+type ProgressReporter interface {
+	ReportProgress(fraction float64)
+}
+
+// progressInterval is the minimum interval between reports of SetProgress.
+var progressInterval = 100 * time.Millisecond
+
+// SetProgress reports the progress of the current code execution to the front-end with the ProgressReporter
+// of the execution. fraction is clamped to [0, 1]. NaN is reported as 0. Reports are throttled to
+// once per 100ms and the latest fraction is reported before the execution finishes.
+// Progress set after the execution finishes is not reported.
+// SetProgress does nothing if the execution has no ProgressReporter or lgo does not execute any code blocks.
+func SetProgress(fraction float64) {
+	e := getExecState()
+	if e == nil || e.Context.Progress == nil {
+		return
+	}
+	if math.IsNaN(fraction) {
+		fraction = 0
+	}
+	fraction = math.Max(0, math.Min(1, fraction))
+	e.progressMu.Lock()
+	e.progress = fraction
+	if e.progressTh == nil {
+		e.progressTh = &throttler{render: e.reportProgress, interval: progressInterval}
+	}
+	th := e.progressTh
+	e.progressMu.Unlock()
+	th.update()
+}
+
+// reportProgress reports the latest progress of e.
+func (e *ExecutionState) reportProgress() {
+	e.progressMu.Lock()
+	fraction := e.progress
+	e.progressMu.Unlock()
+	e.Context.Progress.ReportProgress(fraction)
+}
+
+// stopProgress reports the latest progress if the report is postponed by the throttling.
+// Later calls of SetProgress (e.g. from goroutines left after the execution) are ignored
+// so that the progress is not reported after the execution finishes.
+func (e *ExecutionState) stopProgress() {
+	e.progressMu.Lock()
+	if e.progressTh == nil {
+		e.progressTh = &throttler{render: e.reportProgress}
+	}
+	th := e.progressTh
+	e.progressMu.Unlock()
+	th.stop()
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeProgressReporter struct {
+	mu        sync.Mutex
+	fractions []float64
+}
+
+func (r *fakeProgressReporter) ReportProgress(fraction float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fractions = append(r.fractions, fraction)
+}
+
+func TestSetProgress(t *testing.T) {
+	orig := progressInterval
+	defer func() { progressInterval = orig }()
+	progressInterval = 0
+	atomic.StoreUint32(&isRunning, 0)
+	var r fakeProgressReporter
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Progress: &r}, func() {
+		SetProgress(-0.5)
+		SetProgress(0.25)
+		SetProgress(1.5)
+		SetProgress(math.NaN())
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []float64{0, 0.25, 1, 0}; !reflect.DeepEqual(r.fractions, want) {
+		t.Errorf("Got %v; want %v", r.fractions, want)
+	}
+	// No reporters.
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		SetProgress(0.5)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	SetProgress(0.5)
+}
+
+func TestSetProgressThrottle(t *testing.T) {
+	orig := progressInterval
+	defer func() { progressInterval = orig }()
+	progressInterval = time.Hour
+	atomic.StoreUint32(&isRunning, 0)
+	var r fakeProgressReporter
+	var during []float64
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Progress: &r}, func() {
+		for i := 0; i <= 10; i++ {
+			SetProgress(float64(i) / 10)
+		}
+		r.mu.Lock()
+		during = append(during, r.fractions...)
+		r.mu.Unlock()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []float64{0}; !reflect.DeepEqual(during, want) {
+		t.Errorf("Got %v; want %v", during, want)
+	}
+	// The latest progress is reported when the execution finishes.
+	if want := []float64{0, 1}; !reflect.DeepEqual(r.fractions, want) {
+		t.Errorf("Got %v; want %v", r.fractions, want)
+	}
+}
+
+func TestSetProgressAfterExecution(t *testing.T) {
+	orig := progressInterval
+	defer func() { progressInterval = orig }()
+	progressInterval = time.Hour
+	atomic.StoreUint32(&isRunning, 0)
+	var r fakeProgressReporter
+	var e *ExecutionState
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Progress: &r}, func() {
+		e = getExecState()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// e.g. SetProgress called by a goroutine left after the execution.
+	e.progressMu.Lock()
+	th := e.progressTh
+	e.progressMu.Unlock()
+	th.update()
+	if len(r.fractions) != 0 {
+		t.Errorf("Progress is reported after the execution: %v", r.fractions)
+	}
+}
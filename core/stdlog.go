@@ -0,0 +1,49 @@
+package core
+
+import (
+	"log"
+	"sync"
+)
+
+// stdLogMu serializes the replacement of the output of the standard logger by CaptureStdLog.
+var stdLogMu sync.Mutex
+
+// displayWriter is an io.Writer which displays written bytes as texts with a DataDisplayer.
+type displayWriter struct {
+	d DataDisplayer
+}
+
+func (w *displayWriter) Write(p []byte) (int, error) {
+	// The standard logger writes each message with one Write.
+	w.d.Text(string(p), nil)
+	return len(p), nil
+}
+
+// CaptureStdLog sets the output of the standard logger of the log package to d so that messages which
+// libraries log with log.Println and others are displayed as texts in the cell instead of stderr.
+// It returns the function which restores the original output. The function can be called more than once.
+//
+// If CaptureStdLog is called in a code execution, the output is restored when the execution finishes
+// or is canceled (See RegisterCleanup). If the output is replaced after CaptureStdLog, restoring leaves
+// the new output as is.
+func CaptureStdLog(d DataDisplayer) func() {
+	w := &displayWriter{d: d}
+	stdLogMu.Lock()
+	prev := log.Writer()
+	log.SetOutput(w)
+	stdLogMu.Unlock()
+	var once sync.Once
+	restore := func() {
+		once.Do(func() {
+			stdLogMu.Lock()
+			defer stdLogMu.Unlock()
+			if log.Writer() == w {
+				log.SetOutput(prev)
+			}
+		})
+	}
+	if getExecState() != nil {
+		RegisterCleanup(restore)
+	}
+	return restore
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCaptureStdLog(t *testing.T) {
+	orig := log.Writer()
+	flags := log.Flags()
+	defer log.SetFlags(flags)
+	log.SetFlags(0)
+	var d fakeDisplayer
+	restore := CaptureStdLog(&d)
+	log.Println("hello")
+	log.Printf("n=%d", 10)
+	restore()
+	restore()
+	if log.Writer() != orig {
+		t.Error("The output is not restored")
+	}
+	want := []string{"hello\n", "n=10\n"}
+	if len(d.contents) != len(want) {
+		t.Fatalf("Got %d contents; want %d", len(d.contents), len(want))
+	}
+	for i, c := range d.contents {
+		if c.contentType != "text/plain" || c.content != want[i] {
+			t.Errorf("Got %s %q; want text/plain %q", c.contentType, c.content, want[i])
+		}
+	}
+}
+
+func TestCaptureStdLogInExec(t *testing.T) {
+	orig := log.Writer()
+	flags := log.Flags()
+	defer log.SetFlags(flags)
+	log.SetFlags(0)
+	atomic.StoreUint32(&isRunning, 0)
+	var d fakeDisplayer
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		CaptureStdLog(&d)
+		log.Print("in exec")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if log.Writer() != orig {
+		t.Error("The output is not restored when the execution finishes")
+	}
+	if len(d.contents) != 1 || d.contents[0].content != "in exec\n" {
+		t.Errorf("Unexpected contents: %v", d.contents)
+	}
+}
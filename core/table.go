@@ -0,0 +1,119 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"sync/atomic"
+)
+
+// Tabular is the interface that wraps table-like data (e.g. DataFrames) to display them as tables.
+type Tabular interface {
+	// Columns returns the names of columns.
+	Columns() []string
+	// NumRows returns the number of rows.
+	NumRows() int
+	// Row returns the values of the i-th row. The length must be equal to the number of columns.
+	Row(i int) []interface{}
+}
+
+// javaScriptEnabled indicates the front-end executes JavaScript in outputs.
+// To access this var, use atomic.Store/LoadUint32.
+var javaScriptEnabled uint32
+
+// SetJavaScriptEnabled tells lgo whether the front-end executes JavaScript embedded in outputs.
+// It is false by default because JavaScript output is disabled in JupyterLab (See DataDisplayer).
+// Display helpers with interactive features fall back to static outputs when this is false.
+func SetJavaScriptEnabled(enabled bool) {
+	var v uint32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreUint32(&javaScriptEnabled, v)
+}
+
+func isJavaScriptEnabled() bool {
+	return atomic.LoadUint32(&javaScriptEnabled) == 1
+}
+
+// tableSeq is used to generate unique element IDs of tables.
+var tableSeq uint64
+
+// writeHTMLTable writes t as an HTML table to buf.
+func writeHTMLTable(buf *bytes.Buffer, t Tabular, attrs string) error {
+	cols := t.Columns()
+	fmt.Fprintf(buf, "<table%s><thead><tr>", attrs)
+	for _, c := range cols {
+		fmt.Fprintf(buf, "<th>%s</th>", html.EscapeString(c))
+	}
+	buf.WriteString("</tr></thead><tbody>")
+	n := t.NumRows()
+	for i := 0; i < n; i++ {
+		row := t.Row(i)
+		if len(row) != len(cols) {
+			return fmt.Errorf("row %d has %d values but the table has %d columns", i, len(row), len(cols))
+		}
+		buf.WriteString("<tr>")
+		for _, v := range row {
+			fmt.Fprintf(buf, "<td>%s</td>", html.EscapeString(fmt.Sprint(v)))
+		}
+		buf.WriteString("</tr>")
+	}
+	buf.WriteString("</tbody></table>")
+	return nil
+}
+
+// DisplayTable displays t as a static HTML table.
+func DisplayTable(d DataDisplayer, t Tabular, id *string) error {
+	var buf bytes.Buffer
+	if err := writeHTMLTable(&buf, t, ""); err != nil {
+		return err
+	}
+	d.HTML(buf.String(), id)
+	return nil
+}
+
+// sortTableScript sorts the rows of the table whose ID is %[1]q when a header is clicked.
+// Numeric columns are sorted numerically and the others are sorted lexicographically.
+const sortTableScript = `<script>
+(function() {
+  var table = document.getElementById(%[1]q);
+  if (!table) return;
+  var ths = table.tHead.rows[0].cells;
+  for (var i = 0; i < ths.length; i++) {
+    (function(col) {
+      var asc = true;
+      ths[col].style.cursor = 'pointer';
+      ths[col].onclick = function() {
+        var body = table.tBodies[0];
+        var rows = Array.prototype.slice.call(body.rows);
+        rows.sort(function(a, b) {
+          var x = a.cells[col].textContent, y = b.cells[col].textContent;
+          var nx = parseFloat(x), ny = parseFloat(y);
+          var c = (!isNaN(nx) && !isNaN(ny)) ? nx - ny : x.localeCompare(y);
+          return asc ? c : -c;
+        });
+        asc = !asc;
+        rows.forEach(function(r) { body.appendChild(r); });
+      };
+    })(i);
+  }
+})();
+</script>`
+
+// DisplayInteractiveTable displays t as an HTML table whose rows are sorted by clicking column headers.
+// Because JavaScript output is disabled in JupyterLab, this falls back to DisplayTable
+// unless SetJavaScriptEnabled(true) is called.
+func DisplayInteractiveTable(d DataDisplayer, t Tabular, id *string) error {
+	if !isJavaScriptEnabled() {
+		return DisplayTable(d, t, id)
+	}
+	elemID := fmt.Sprintf("lgo-table-%d", atomic.AddUint64(&tableSeq, 1))
+	var buf bytes.Buffer
+	if err := writeHTMLTable(&buf, t, fmt.Sprintf(" id=%q", elemID)); err != nil {
+		return err
+	}
+	fmt.Fprintf(&buf, sortTableScript, elemID)
+	d.HTML(buf.String(), id)
+	return nil
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+type sliceTable struct {
+	cols []string
+	rows [][]interface{}
+}
+
+func (t *sliceTable) Columns() []string       { return t.cols }
+func (t *sliceTable) NumRows() int            { return len(t.rows) }
+func (t *sliceTable) Row(i int) []interface{} { return t.rows[i] }
+
+func TestDisplayTable(t *testing.T) {
+	var d fakeDisplayer
+	tbl := &sliceTable{
+		cols: []string{"name", "value"},
+		rows: [][]interface{}{{"<a>", 1}, {"b", 2.5}},
+	}
+	if err := DisplayTable(&d, tbl, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := "<table><thead><tr><th>name</th><th>value</th></tr></thead><tbody>" +
+		"<tr><td>&lt;a&gt;</td><td>1</td></tr><tr><td>b</td><td>2.5</td></tr></tbody></table>"
+	if len(d.contents) != 1 || d.contents[0].content != want {
+		t.Errorf("Got %v; want %q", d.contents, want)
+	}
+}
+
+func TestDisplayTableMismatch(t *testing.T) {
+	var d fakeDisplayer
+	tbl := &sliceTable{
+		cols: []string{"a", "b"},
+		rows: [][]interface{}{{1}},
+	}
+	if err := DisplayTable(&d, tbl, nil); err == nil {
+		t.Error("DisplayTable succeeded unexpectedly")
+	}
+	if len(d.contents) != 0 {
+		t.Errorf("Unexpected contents: %v", d.contents)
+	}
+}
+
+func TestDisplayInteractiveTable(t *testing.T) {
+	tbl := &sliceTable{
+		cols: []string{"x"},
+		rows: [][]interface{}{{3}, {1}},
+	}
+	// Fall back to a static table.
+	var static fakeDisplayer
+	if err := DisplayInteractiveTable(&static, tbl, nil); err != nil {
+		t.Fatal(err)
+	}
+	if s := static.contents[0].content.(string); strings.Contains(s, "<script>") {
+		t.Errorf("JavaScript is used though it is disabled: %s", s)
+	}
+
+	SetJavaScriptEnabled(true)
+	defer SetJavaScriptEnabled(false)
+	var d fakeDisplayer
+	id := ""
+	if err := DisplayInteractiveTable(&d, tbl, &id); err != nil {
+		t.Fatal(err)
+	}
+	s := d.contents[0].content.(string)
+	if !strings.Contains(s, "<script>") || !strings.Contains(s, `<table id="lgo-table-`) {
+		t.Errorf("Unexpected HTML: %s", s)
+	}
+	if id == "" {
+		t.Error("id is not assigned")
+	}
+}
@@ -0,0 +1,267 @@
+package core
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"sync"
+	"sync/atomic"
+)
+
+// tabsSeq makes IDs of the elements of tab panels unique in a notebook. Use atomic.AddUint64.
+var tabsSeq uint64
+
+// tabFragment is an output displayed in a tab.
+type tabFragment struct {
+	// id is the display ID of the output in the tab panel. Empty if the output has no ID.
+	id   string
+	html string
+}
+
+type tabPane struct {
+	title     string
+	fragments []*tabFragment
+}
+
+// TabPanel displays outputs in tabs of a container. TabPanel is created with NewTabPanel.
+//
+// TabPanel is also the DataDisplayer which displays outputs into its tabs. Outputs are rendered
+// as HTML in the tab whose content is being displayed with AddTab, or the last tab after AddTab returns.
+// Outputs with display IDs are overwritten in the tab panel, so tab panels and other display helpers
+// which update outputs (e.g. Pager) can be nested in tabs.
+//
+// Tabs are switched with JavaScript if SetJavaScriptEnabled(true) is called. Otherwise, tabs are displayed
+// as sections stacked under their titles because JavaScript output is disabled in JupyterLab.
+type TabPanel struct {
+	mu     sync.Mutex
+	d      DataDisplayer
+	id     *string
+	elemID string
+	tabs   []*tabPane
+	// current is the index of the tab whose content is being displayed. -1 if AddTab is not running.
+	current int
+	// fragSeq is the sequence to reserve display IDs of outputs in tabs.
+	fragSeq int
+}
+
+// NewTabPanel returns a TabPanel which displays its tabs with d. The container is displayed
+// when the first tab is added and is overwritten when tabs are added or updated.
+func NewTabPanel(d DataDisplayer) *TabPanel {
+	return &TabPanel{
+		d:       d,
+		id:      new(string),
+		elemID:  fmt.Sprintf("lgo-tabs-%d", atomic.AddUint64(&tabsSeq, 1)),
+		current: -1,
+	}
+}
+
+// AddTab adds a tab titled title and calls content to display the content of the tab with p.
+// id points an empty string to reserve a display ID of the content. Pass id to display helpers
+// to overwrite the content later (e.g. p.HTML(s, id)).
+func (p *TabPanel) AddTab(title string, content func(id *string)) {
+	p.mu.Lock()
+	p.tabs = append(p.tabs, &tabPane{title: title})
+	idx := len(p.tabs) - 1
+	p.current = idx
+	p.render()
+	p.mu.Unlock()
+	// Don't hold the lock while content displays outputs with p.
+	defer func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.current == idx {
+			p.current = -1
+		}
+	}()
+	content(new(string))
+}
+
+// add adds or overwrites the output rendered as s in HTML with the display ID semantics of DataDisplayer.
+func (p *TabPanel) add(s string, id *string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if id != nil && *id != "" {
+		for _, t := range p.tabs {
+			for _, f := range t.fragments {
+				if f.id == *id {
+					f.html = s
+					p.render()
+					return
+				}
+			}
+		}
+	}
+	idx := p.current
+	if idx < 0 {
+		idx = len(p.tabs) - 1
+	}
+	if idx < 0 {
+		// No tabs to display outputs.
+		return
+	}
+	f := &tabFragment{html: s}
+	if id != nil {
+		if *id == "" {
+			p.fragSeq++
+			*id = fmt.Sprintf("%s-out%d", p.elemID, p.fragSeq)
+		}
+		f.id = *id
+	}
+	p.tabs[idx].fragments = append(p.tabs[idx].fragments, f)
+	p.render()
+}
+
+// tabsScript switches the tabs of the tab panel whose element ID is %[1]q.
+const tabsScript = `<script>
+(function() {
+  var root = document.getElementById(%[1]q);
+  if (!root) {
+    return;
+  }
+  var buttons = root.querySelectorAll(":scope > .lgo-tab-bar > button");
+  var panes = root.querySelectorAll(":scope > .lgo-tab-pane");
+  for (var i = 0; i < buttons.length; i++) {
+    (function(i) {
+      buttons[i].onclick = function() {
+        for (var j = 0; j < panes.length; j++) {
+          panes[j].style.display = j === i ? "" : "none";
+          buttons[j].style.fontWeight = j === i ? "bold" : "normal";
+        }
+      };
+    })(i);
+  }
+})();
+</script>`
+
+// render displays the container of tabs. p.mu must be held.
+func (p *TabPanel) render() {
+	var buf bytes.Buffer
+	if isJavaScriptEnabled() {
+		fmt.Fprintf(&buf, `<div class="lgo-tabs" id="%s"><div class="lgo-tab-bar">`, p.elemID)
+		for i, t := range p.tabs {
+			weight := "normal"
+			if i == 0 {
+				weight = "bold"
+			}
+			fmt.Fprintf(&buf, `<button type="button" style="font-weight:%s">%s</button>`, weight, html.EscapeString(t.title))
+		}
+		buf.WriteString("</div>")
+		for i, t := range p.tabs {
+			if i == 0 {
+				buf.WriteString(`<div class="lgo-tab-pane">`)
+			} else {
+				buf.WriteString(`<div class="lgo-tab-pane" style="display:none">`)
+			}
+			t.writeFragments(&buf)
+			buf.WriteString("</div>")
+		}
+		buf.WriteString("</div>")
+		fmt.Fprintf(&buf, tabsScript, p.elemID)
+	} else {
+		fmt.Fprintf(&buf, `<div class="lgo-tabs" id="%s">`, p.elemID)
+		for _, t := range p.tabs {
+			fmt.Fprintf(&buf, `<section class="lgo-tab-pane"><h4>%s</h4>`, html.EscapeString(t.title))
+			t.writeFragments(&buf)
+			buf.WriteString("</section>")
+		}
+		buf.WriteString("</div>")
+	}
+	p.d.HTML(buf.String(), p.id)
+}
+
+func (t *tabPane) writeFragments(buf *bytes.Buffer) {
+	for _, f := range t.fragments {
+		buf.WriteString(f.html)
+	}
+}
+
+// preHTML renders s as preformatted text.
+func preHTML(s string) string {
+	return "<pre>" + html.EscapeString(s) + "</pre>"
+}
+
+// imageHTML renders the image b of contentType.
+func imageHTML(contentType string, b []byte) string {
+	return fmt.Sprintf(`<img src="data:%s;base64,%s">`, contentType, base64.StdEncoding.EncodeToString(b))
+}
+
+func (p *TabPanel) JavaScript(s string, id *string) {
+	if isJavaScriptEnabled() {
+		p.add("<script>"+s+"</script>", id)
+	}
+}
+func (p *TabPanel) HTML(s string, id *string)     { p.add(s, id) }
+func (p *TabPanel) Markdown(s string, id *string) { p.add(preHTML(s), id) }
+func (p *TabPanel) Latex(s string, id *string)    { p.add(preHTML(s), id) }
+func (p *TabPanel) SVG(s string, id *string)      { p.add(s, id) }
+func (p *TabPanel) PNG(b []byte, id *string)      { p.add(imageHTML("image/png", b), id) }
+func (p *TabPanel) JPEG(b []byte, id *string)     { p.add(imageHTML("image/jpeg", b), id) }
+func (p *TabPanel) GIF(b []byte, id *string)      { p.add(imageHTML("image/gif", b), id) }
+func (p *TabPanel) Text(s string, id *string)     { p.add(preHTML(s), id) }
+func (p *TabPanel) PDF(b []byte, id *string) {
+	uri := "data:application/pdf;base64," + base64.StdEncoding.EncodeToString(b)
+	p.add(fmt.Sprintf(`<embed type="application/pdf" src="%s" width="100%%" height="500">`, uri), id)
+}
+
+// rawHTML renders v of contentType as HTML.
+func rawHTML(contentType string, v interface{}) (string, error) {
+	switch contentType {
+	case "text/html", "image/svg+xml", "text/plain", "text/markdown", "text/latex":
+		s, ok := v.(string)
+		if !ok {
+			return "", fmt.Errorf("%s must be a string in tabs: %T", contentType, v)
+		}
+		if contentType == "text/html" || contentType == "image/svg+xml" {
+			return s, nil
+		}
+		return preHTML(s), nil
+	case "image/png", "image/jpeg", "image/gif":
+		switch v := v.(type) {
+		case []byte:
+			return imageHTML(contentType, v), nil
+		case string:
+			// Images in bundles are encoded in base64.
+			return fmt.Sprintf(`<img src="data:%s;base64,%s">`, contentType, html.EscapeString(v)), nil
+		}
+		return "", fmt.Errorf("%s must be []byte or a base64 string in tabs: %T", contentType, v)
+	}
+	return "", fmt.Errorf("%s can not be displayed in tabs", contentType)
+}
+
+func (p *TabPanel) Raw(contentType string, v interface{}, id *string) error {
+	s, err := rawHTML(contentType, v)
+	if err != nil {
+		return err
+	}
+	p.add(s, id)
+	return nil
+}
+
+// Widget returns an error because widgets can not be rendered in HTML.
+func (p *TabPanel) Widget(modelID string, id *string) error {
+	return errors.New("widgets can not be displayed in tabs")
+}
+
+func (p *TabPanel) UpdateDisplay(id string, bundle map[string]interface{}) error {
+	if err := CheckDisplayUpdate(id, bundle); err != nil {
+		return err
+	}
+	contentType := preferredMIMEType(bundle, p.SupportedMIMETypes())
+	s, err := rawHTML(contentType, bundle[contentType])
+	if err != nil {
+		return err
+	}
+	p.add(s, &id)
+	return nil
+}
+
+// SupportedMIMETypes returns the MIME types embedded in tabs as HTML.
+func (p *TabPanel) SupportedMIMETypes() []string {
+	types := []string{"text/html", "image/svg+xml", "image/png", "image/jpeg", "image/gif", "text/plain"}
+	if isJavaScriptEnabled() {
+		types = append(types, "application/javascript")
+	}
+	return types
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+var _ DataDisplayer = (*TabPanel)(nil)
+
+// lastHTML returns the content of the last output displayed to d.
+func lastHTML(t *testing.T, d *fakeDisplayer) string {
+	if len(d.contents) == 0 {
+		t.Fatal("Nothing is displayed")
+	}
+	return d.contents[len(d.contents)-1].content.(string)
+}
+
+func TestTabPanelFallback(t *testing.T) {
+	defer SetJavaScriptEnabled(false)
+	SetJavaScriptEnabled(false)
+	var d fakeDisplayer
+	p := NewTabPanel(&d)
+	if len(d.contents) != 0 {
+		t.Errorf("Empty tab panels must not be displayed: %v", d.contents)
+	}
+	var first *string
+	p.AddTab("<Plot>", func(id *string) {
+		p.SVG("<svg></svg>", id)
+		first = id
+	})
+	p.AddTab("Log", func(id *string) {
+		p.Text("a<b", nil)
+		p.PNG([]byte{1, 2}, nil)
+	})
+	want := `<div class="lgo-tabs" id="` + p.elemID + `">` +
+		`<section class="lgo-tab-pane"><h4>&lt;Plot&gt;</h4><svg></svg></section>` +
+		`<section class="lgo-tab-pane"><h4>Log</h4><pre>a&lt;b</pre><img src="data:image/png;base64,AQI="></section></div>`
+	if got := lastHTML(t, &d); got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	// All renders overwrite the same display.
+	for _, c := range d.contents {
+		if c.id != "id1" {
+			t.Errorf("Unexpected display ID: %v", c)
+		}
+	}
+	// Update the content of the first tab after AddTab returns.
+	p.SVG("<svg>2</svg>", first)
+	if got := lastHTML(t, &d); !strings.Contains(got, "<h4>&lt;Plot&gt;</h4><svg>2</svg></section>") || strings.Contains(got, "<svg></svg>") {
+		t.Errorf("Unexpected update: %s", got)
+	}
+	// Outputs without IDs are added to the last tab.
+	p.HTML("<i>x</i>", nil)
+	if got := lastHTML(t, &d); !strings.HasSuffix(got, `<i>x</i></section></div>`) {
+		t.Errorf("Unexpected output: %s", got)
+	}
+}
+
+func TestTabPanelNested(t *testing.T) {
+	defer SetJavaScriptEnabled(false)
+	SetJavaScriptEnabled(false)
+	var d fakeDisplayer
+	outer := NewTabPanel(&d)
+	var inner *TabPanel
+	outer.AddTab("A", func(id *string) {
+		inner = NewTabPanel(outer)
+		inner.AddTab("A1", func(id *string) { outer.Text("outer", nil) })
+		inner.AddTab("A2", func(id *string) {})
+	})
+	got := lastHTML(t, &d)
+	if n := strings.Count(got, `<div class="lgo-tabs"`); n != 2 {
+		t.Errorf("Got %d panels; want 2: %s", n, got)
+	}
+	// The inner panel is overwritten in the outer panel.
+	if n := strings.Count(got, "<h4>A1</h4>"); n != 1 {
+		t.Errorf("Got %d inner panels; want 1: %s", n, got)
+	}
+	// Outputs to the outer panel are added after the inner panel in the tab.
+	if !strings.HasSuffix(got, "<h4>A2</h4></section></div><pre>outer</pre></section></div>") {
+		t.Errorf("Unexpected output: %s", got)
+	}
+}
+
+func TestTabPanelJavaScript(t *testing.T) {
+	defer SetJavaScriptEnabled(false)
+	SetJavaScriptEnabled(true)
+	var d fakeDisplayer
+	p := NewTabPanel(&d)
+	p.AddTab("A", func(id *string) { p.HTML("a", id) })
+	p.AddTab("B", func(id *string) { p.HTML("b", id) })
+	got := lastHTML(t, &d)
+	for _, want := range []string{
+		`<button type="button" style="font-weight:bold">A</button><button type="button" style="font-weight:normal">B</button>`,
+		`<div class="lgo-tab-pane">a</div><div class="lgo-tab-pane" style="display:none">b</div>`,
+		`document.getElementById("` + p.elemID + `")`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%q is not in %s", want, got)
+		}
+	}
+}
+
+func TestTabPanelRaw(t *testing.T) {
+	var d fakeDisplayer
+	p := NewTabPanel(&d)
+	p.AddTab("A", func(id *string) {
+		if err := p.Raw("text/markdown", "# x", nil); err != nil {
+			t.Error(err)
+		}
+		if err := p.Raw("application/json", "{}", nil); err == nil {
+			t.Error("Raw with an unsupported type succeeded unexpectedly")
+		}
+		if err := p.Widget("model", nil); err == nil {
+			t.Error("Widget succeeded unexpectedly")
+		}
+		if err := p.UpdateDisplay("out", map[string]interface{}{"text/plain": "p", "text/html": "<b>h</b>"}); err != nil {
+			t.Error(err)
+		}
+	})
+	if got := lastHTML(t, &d); !strings.Contains(got, "<h4>A</h4><pre># x</pre><b>h</b></section>") {
+		t.Errorf("Unexpected output: %s", got)
+	}
+}
@@ -0,0 +1,55 @@
+package core
+
+// Tags of code blocks recognized by ExecLgoEntryPointWithTags.
+const (
+	// TagNoTimeout runs the code block without the timeout set with SetExecTimeout.
+	TagNoTimeout = "no-timeout"
+	// TagBackground lets goroutines keep running in background after the main routine finishes
+	// as if the lingering policy is LingeringDetach (See SetLingeringPolicy).
+	TagBackground = "background"
+	// TagQuiet suppresses printing the results of expressions with LgoPrintln.
+	TagQuiet = "quiet"
+)
+
+// execTags is the set of recognized tags of an execution.
+type execTags struct {
+	noTimeout  bool
+	background bool
+	quiet      bool
+}
+
+func parseExecTags(tags []string) execTags {
+	var t execTags
+	for _, tag := range tags {
+		switch tag {
+		case TagNoTimeout:
+			t.noTimeout = true
+		case TagBackground:
+			t.background = true
+		case TagQuiet:
+			t.quiet = true
+		}
+	}
+	return t
+}
+
+// ExecLgoEntryPointWithTags is ExecLgoEntryPoint which adjusts the execution with tags of the code block
+// (e.g. tags in the metadata of a cell passed by the kernel). The recognized tags are TagNoTimeout,
+// TagBackground and TagQuiet. Unknown tags are ignored.
+func ExecLgoEntryPointWithTags(parent LgoContext, main func(), tags []string) error {
+	return finalizeExec(startExecWithTags(parent, main, parseExecTags(tags)))
+}
+
+// lingeringPolicy returns the lingering policy applied to e.
+func (e *ExecutionState) lingeringPolicy() OnLingering {
+	if e.tags.background {
+		return LingeringDetach
+	}
+	return getLingeringPolicy()
+}
+
+// isQuiet returns whether the results of expressions are not printed in the current execution.
+func isQuiet() bool {
+	e := getExecState()
+	return e != nil && e.tags.quiet
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseExecTags(t *testing.T) {
+	got := parseExecTags([]string{"quiet", "unknown", "no-timeout", ""})
+	if want := (execTags{noTimeout: true, quiet: true}); got != want {
+		t.Errorf("Got %+v; want %+v", got, want)
+	}
+}
+
+func TestExecTagQuiet(t *testing.T) {
+	p := &linesPrinter{}
+	RegisterLgoPrinter(p)
+	defer UnregisterLgoPrinter(p)
+	atomic.StoreUint32(&isRunning, 0)
+	for _, tags := range [][]string{{TagQuiet}, {"unknown"}} {
+		if err := ExecLgoEntryPointWithTags(LgoContext{Context: context.Background()}, func() {
+			LgoPrintln(tags[0])
+		}, tags); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := []string{"unknown"}; !reflect.DeepEqual(p.lines, want) {
+		t.Errorf("Got %q; want %q", p.lines, want)
+	}
+}
+
+func TestExecTagBackground(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	finished := make(chan struct{})
+	if err := ExecLgoEntryPointWithTags(LgoContext{Context: context.Background()}, func() {
+		state := InitGoroutine()
+		go func() {
+			defer FinalizeGoroutine(state)
+			defer close(finished)
+			<-state.Context.Done()
+		}()
+	}, []string{TagBackground}); err != nil {
+		t.Fatal(err)
+	}
+	if n := DetachedGoroutines(); n != 1 {
+		t.Errorf("Got %d; want 1", n)
+	}
+	CancelDetachedGoroutines()
+	<-finished
+	for i := 0; DetachedGoroutines() != 0; i++ {
+		if i >= 100 {
+			t.Fatal("Detached goroutines are not removed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestExecTagNoTimeout(t *testing.T) {
+	defer SetExecTimeout(0)
+	SetExecTimeout(10 * time.Millisecond)
+	atomic.StoreUint32(&isRunning, 0)
+	sleep := func() {
+		deadline := time.Now().Add(50 * time.Millisecond)
+		for time.Now().Before(deadline) {
+			ExitIfCtxDone()
+			runtime.Gosched()
+		}
+	}
+	if err := ExecLgoEntryPointWithTags(LgoContext{Context: context.Background()}, sleep, []string{TagNoTimeout}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	err := ExecLgoEntryPointWithTags(LgoContext{Context: context.Background()}, sleep, nil)
+	if want := "canceled: timeout exceeded, main routine canceled"; err == nil || err.Error() != want {
+		t.Errorf("Got %v; want %q", err, want)
+	}
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// errNoExecution is returned from ExecTempDir when lgo does not execute any code blocks.
+var errNoExecution = errors.New("no code execution is running")
+
+// errExecFinishing is returned from ExecTempDir when the current execution is being torn down.
+var errExecFinishing = errors.New("the code execution is finishing")
+
+// ExecTempDir returns the temporary directory of the current code execution for intermediate files.
+// The directory is created on the first call in the execution and the same directory is returned
+// in the execution afterwards. The directory and its contents are removed when the execution finishes
+// or is canceled (See RegisterCleanup). An error in the removal is printed to stderr.
+// It returns an error if lgo does not execute any code blocks.
+func ExecTempDir() (string, error) {
+	e := getExecState()
+	if e == nil {
+		return "", errNoExecution
+	}
+	e.tempMu.Lock()
+	defer e.tempMu.Unlock()
+	if e.tempDir != "" {
+		return e.tempDir, nil
+	}
+	dir, err := ioutil.TempDir("", "lgo-exec-")
+	if err != nil {
+		return "", err
+	}
+	remove := func() {
+		if err := os.RemoveAll(dir); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to remove the temporary directory of the execution: %v\n", err)
+		}
+	}
+	e.cleanupMu.Lock()
+	tearingDown := e.tearingDown
+	if !tearingDown {
+		e.cleanups = append(e.cleanups, remove)
+	}
+	e.cleanupMu.Unlock()
+	if tearingDown {
+		// The directory would be left because cleanups were called already.
+		remove()
+		return "", errExecFinishing
+	}
+	e.tempDir = dir
+	return dir, nil
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestExecTempDir(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var dir string
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		var err error
+		if dir, err = ExecTempDir(); err != nil {
+			t.Error(err)
+			return
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644); err != nil {
+			t.Error(err)
+		}
+		again, err := ExecTempDir()
+		if err != nil || again != dir {
+			t.Errorf("Got (%q, %v); want %q", again, err, dir)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if dir == "" {
+		t.Fatal("no directory")
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("%s is not removed: %v", dir, err)
+	}
+	if _, err := ExecTempDir(); err != errNoExecution {
+		t.Errorf("Got %v; want %v", err, errNoExecution)
+	}
+}
+
+func TestExecTempDirCanceled(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	dirc := make(chan string, 1)
+	e := startExec(LgoContext{Context: ctx}, func() {
+		dir, err := ExecTempDir()
+		if err != nil {
+			t.Error(err)
+		}
+		dirc <- dir
+		<-GetExecContext().Done()
+		panic(Bailout)
+	})
+	dir := <-dirc
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("%s does not exist during the execution: %v", dir, err)
+	}
+	cancel()
+	finalizeExec(e)
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("%s is not removed: %v", dir, err)
+	}
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"text/template"
+)
+
+// DisplayTemplate executes tmpl, a html/template template, with data and displays the result as HTML.
+// Values in data are escaped by html/template. It returns errors of parsing and executing tmpl.
+func DisplayTemplate(d DataDisplayer, tmpl string, data interface{}, id *string) error {
+	t, err := htmltemplate.New("lgo").Parse(tmpl)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return err
+	}
+	d.HTML(buf.String(), id)
+	return nil
+}
+
+// DisplayTemplateUnsafe is DisplayTemplate with text/template, which does not escape values in data.
+// Use this only if data is trusted and contains HTML to embed.
+func DisplayTemplateUnsafe(d DataDisplayer, tmpl string, data interface{}, id *string) error {
+	t, err := template.New("lgo").Parse(tmpl)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return err
+	}
+	d.HTML(buf.String(), id)
+	return nil
+}
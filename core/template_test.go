@@ -0,0 +1,35 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestDisplayTemplate(t *testing.T) {
+	var d fakeDisplayer
+	data := map[string]string{"Name": "<b>lgo</b>"}
+	if err := DisplayTemplate(&d, "<p>{{.Name}}</p>", data, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := DisplayTemplateUnsafe(&d, "<p>{{.Name}}</p>", data, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := d.contents[0].content, "<p>&lt;b&gt;lgo&lt;/b&gt;</p>"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+	if got, want := d.contents[1].content, "<p><b>lgo</b></p>"; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+}
+
+func TestDisplayTemplateError(t *testing.T) {
+	var d fakeDisplayer
+	if err := DisplayTemplate(&d, "{{.Name", nil, nil); err == nil {
+		t.Error("Parse error is not returned")
+	}
+	if err := DisplayTemplate(&d, "{{.Name.Foo}}", struct{ Name int }{}, nil); err == nil {
+		t.Error("Execution error is not returned")
+	}
+	if len(d.contents) != 0 {
+		t.Errorf("Unexpected contents: %v", d.contents)
+	}
+}
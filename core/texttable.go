@@ -0,0 +1,305 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// defaultTextTableWidth is the maximum width of tables rendered with DisplayTextTable.
+const defaultTextTableWidth = 120
+
+// TextTableOptions controls how DisplayTextTableOpts renders tables in plain text.
+type TextTableOptions struct {
+	// BoxDrawing draws the borders of tables with box-drawing characters instead of ASCII characters.
+	BoxDrawing bool
+	// MaxWidth is the maximum width of tables in columns of monospace fonts.
+	// Wide columns are narrowed and their cells are truncated to fit into MaxWidth.
+	// If MaxWidth is not positive, the width is not limited.
+	MaxWidth int
+}
+
+// textTableBorders are the characters to draw the borders of text tables.
+type textTableBorders struct {
+	// top, mid and bottom are the left, the junction, the right and the horizontal line of the rules.
+	top, mid, bottom [4]string
+	vertical         string
+	ellipsis         string
+}
+
+var (
+	asciiBorders = textTableBorders{
+		top:      [4]string{"+", "+", "+", "-"},
+		mid:      [4]string{"+", "+", "+", "-"},
+		bottom:   [4]string{"+", "+", "+", "-"},
+		vertical: "|",
+		ellipsis: "...",
+	}
+	boxBorders = textTableBorders{
+		top:      [4]string{"┌", "┬", "┐", "─"},
+		mid:      [4]string{"├", "┼", "┤", "─"},
+		bottom:   [4]string{"└", "┴", "┘", "─"},
+		vertical: "│",
+		ellipsis: "…",
+	}
+)
+
+// textTableMinColumn is the width to which wide columns are narrowed at least to fit into the maximum width.
+const textTableMinColumn = 3
+
+// DisplayTextTable displays t as a column-aligned table in plain text with ASCII borders for front-ends
+// which do not render HTML well (e.g. terminals). Numeric columns are aligned to the right.
+// Cells can have multiple lines. Tables wider than 120 columns are truncated.
+func DisplayTextTable(d DataDisplayer, t Tabular, id *string) error {
+	return DisplayTextTableOpts(d, t, TextTableOptions{MaxWidth: defaultTextTableWidth}, id)
+}
+
+// DisplayTextTableOpts is DisplayTextTable which renders t as opts specifies.
+func DisplayTextTableOpts(d DataDisplayer, t Tabular, opts TextTableOptions, id *string) error {
+	s, err := formatTextTable(t, opts)
+	if err != nil {
+		return err
+	}
+	d.Text(s, id)
+	return nil
+}
+
+// textCell is a cell of text tables split into lines.
+type textCell []string
+
+func newTextCell(s string) textCell {
+	s = strings.Replace(s, "\r\n", "\n", -1)
+	s = strings.Replace(s, "\t", "    ", -1)
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) {
+				return -1
+			}
+			return r
+		}, l)
+	}
+	return textCell(lines)
+}
+
+func (c textCell) width() int {
+	w := 0
+	for _, l := range c {
+		if lw := stringWidth(l); lw > w {
+			w = lw
+		}
+	}
+	return w
+}
+
+// isNumericValue returns whether v is a number aligned to the right in text tables.
+func isNumericValue(v interface{}) bool {
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// formatTextTable renders t as a text table.
+func formatTextTable(t Tabular, opts TextTableOptions) (string, error) {
+	cols := t.Columns()
+	header := make([]textCell, len(cols))
+	widths := make([]int, len(cols))
+	for i, c := range cols {
+		header[i] = newTextCell(c)
+		widths[i] = header[i].width()
+	}
+	right := make([]bool, len(cols))
+	for i := range right {
+		right[i] = true
+	}
+	n := t.NumRows()
+	rows := make([][]textCell, n)
+	for i := 0; i < n; i++ {
+		row := t.Row(i)
+		if len(row) != len(cols) {
+			return "", fmt.Errorf("row %d has %d values but the table has %d columns", i, len(row), len(cols))
+		}
+		cells := make([]textCell, len(row))
+		for j, v := range row {
+			cells[j] = newTextCell(fmt.Sprint(v))
+			if w := cells[j].width(); w > widths[j] {
+				widths[j] = w
+			}
+			if !isNumericValue(v) {
+				right[j] = false
+			}
+		}
+		rows[i] = cells
+	}
+	if n == 0 {
+		for i := range right {
+			right[i] = false
+		}
+	}
+	if opts.MaxWidth > 0 {
+		fitTextColumns(widths, opts.MaxWidth)
+	}
+	b := asciiBorders
+	if opts.BoxDrawing {
+		b = boxBorders
+	}
+	var buf bytes.Buffer
+	writeTextRule(&buf, widths, b.top)
+	writeTextRow(&buf, header, widths, right, b)
+	writeTextRule(&buf, widths, b.mid)
+	for _, r := range rows {
+		writeTextRow(&buf, r, widths, right, b)
+	}
+	writeTextRule(&buf, widths, b.bottom)
+	return buf.String(), nil
+}
+
+// fitTextColumns narrows the widest columns until the table fits into maxWidth
+// or all columns are narrowed to textTableMinColumn.
+func fitTextColumns(widths []int, maxWidth int) {
+	// Each column has a space on both sides and a vertical line on the right. The first column has
+	// another vertical line on the left.
+	total := 1
+	for _, w := range widths {
+		total += w + 3
+	}
+	for total > maxWidth && len(widths) > 0 {
+		widest := 0
+		for i, w := range widths {
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= textTableMinColumn {
+			return
+		}
+		widths[widest]--
+		total--
+	}
+}
+
+func writeTextRule(buf *bytes.Buffer, widths []int, chars [4]string) {
+	buf.WriteString(chars[0])
+	for i, w := range widths {
+		if i > 0 {
+			buf.WriteString(chars[1])
+		}
+		buf.WriteString(strings.Repeat(chars[3], w+2))
+	}
+	buf.WriteString(chars[2])
+	buf.WriteByte('\n')
+}
+
+func writeTextRow(buf *bytes.Buffer, cells []textCell, widths []int, right []bool, b textTableBorders) {
+	height := 1
+	for _, c := range cells {
+		if len(c) > height {
+			height = len(c)
+		}
+	}
+	for l := 0; l < height; l++ {
+		buf.WriteString(b.vertical)
+		for i, c := range cells {
+			var s string
+			if l < len(c) {
+				s = truncateWidth(c[l], widths[i], b.ellipsis)
+			}
+			pad := strings.Repeat(" ", widths[i]-stringWidth(s))
+			buf.WriteByte(' ')
+			if right[i] {
+				buf.WriteString(pad + s)
+			} else {
+				buf.WriteString(s + pad)
+			}
+			buf.WriteByte(' ')
+			buf.WriteString(b.vertical)
+		}
+		buf.WriteByte('\n')
+	}
+}
+
+// truncateWidth truncates s to fit into width w with ellipsis at the end.
+func truncateWidth(s string, w int, ellipsis string) string {
+	if stringWidth(s) <= w {
+		return s
+	}
+	ew := stringWidth(ellipsis)
+	if w < ew {
+		ellipsis, ew = "", 0
+	}
+	var buf bytes.Buffer
+	cur := 0
+	for _, r := range s {
+		rw := runeWidth(r)
+		if cur+rw > w-ew {
+			break
+		}
+		buf.WriteRune(r)
+		cur += rw
+	}
+	return buf.String() + ellipsis
+}
+
+// stringWidth returns the width of s in columns of monospace fonts.
+func stringWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		w += runeWidth(r)
+	}
+	return w
+}
+
+// wideRanges are the ranges of wide and fullwidth characters of the East Asian Width (UAX #11)
+// occupying two columns. This is an approximation which covers major CJK scripts and emoji.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115f},   // Hangul Jamo
+	{0x231a, 0x231b},   // Watch, hourglass
+	{0x2329, 0x232a},   // Angle brackets
+	{0x23e9, 0x23ec},   // Media controls
+	{0x25fd, 0x25fe},   // Medium small squares
+	{0x2614, 0x2615},   // Umbrella, hot beverage
+	{0x26aa, 0x26ab},   // Medium circles
+	{0x2e80, 0x303e},   // CJK radicals, symbols and punctuation
+	{0x3041, 0x33ff},   // Hiragana, Katakana, Bopomofo, CJK compatibility
+	{0x3400, 0x4dbf},   // CJK unified ideographs extension A
+	{0x4e00, 0x9fff},   // CJK unified ideographs
+	{0xa000, 0xa4cf},   // Yi
+	{0xa960, 0xa97f},   // Hangul Jamo extended-A
+	{0xac00, 0xd7a3},   // Hangul syllables
+	{0xf900, 0xfaff},   // CJK compatibility ideographs
+	{0xfe10, 0xfe19},   // Vertical forms
+	{0xfe30, 0xfe6f},   // CJK compatibility forms, small form variants
+	{0xff00, 0xff60},   // Fullwidth forms
+	{0xffe0, 0xffe6},   // Fullwidth signs
+	{0x1f300, 0x1f64f}, // Miscellaneous symbols and pictographs, emoticons
+	{0x1f680, 0x1f6ff}, // Transport and map symbols
+	{0x1f900, 0x1f9ff}, // Supplemental symbols and pictographs
+	{0x20000, 0x2fffd}, // CJK unified ideographs extension B and later
+	{0x30000, 0x3fffd}, // CJK unified ideographs extension G and later
+}
+
+// runeWidth returns the width of r in columns of monospace fonts.
+// Combining marks and format characters have no width and wide characters occupy two columns.
+func runeWidth(r rune) int {
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) || unicode.IsControl(r) {
+		return 0
+	}
+	if r < 0x1100 {
+		return 1
+	}
+	for _, rg := range wideRanges {
+		if r < rg[0] {
+			break
+		}
+		if r <= rg[1] {
+			return 2
+		}
+	}
+	return 1
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestDisplayTextTable(t *testing.T) {
+	tbl := &sliceTable{
+		cols: []string{"name", "n"},
+		rows: [][]interface{}{
+			{"apple", 1},
+			{"日本語", 120},
+			{"two\nlines", 3.5},
+		},
+	}
+	var d fakeDisplayer
+	id := "tbl"
+	if err := DisplayTextTable(&d, tbl, &id); err != nil {
+		t.Fatal(err)
+	}
+	want := "" +
+		"+--------+-----+\n" +
+		"| name   |   n |\n" +
+		"+--------+-----+\n" +
+		"| apple  |   1 |\n" +
+		"| 日本語 | 120 |\n" +
+		"| two    | 3.5 |\n" +
+		"| lines  |     |\n" +
+		"+--------+-----+\n"
+	if len(d.contents) != 1 {
+		t.Fatalf("Got %d contents; want 1", len(d.contents))
+	}
+	if c := d.contents[0]; c.contentType != "text/plain" || c.content != want || c.id != "tbl" {
+		t.Errorf("Got %s %q (id: %q); want text/plain %q", c.contentType, c.content, c.id, want)
+	}
+}
+
+func TestDisplayTextTableOpts(t *testing.T) {
+	tbl := &sliceTable{
+		cols: []string{"a", "b"},
+		rows: [][]interface{}{
+			{"0123456789", "x"},
+			{"あいうえお", "y"},
+		},
+	}
+	var d fakeDisplayer
+	if err := DisplayTextTableOpts(&d, tbl, TextTableOptions{BoxDrawing: true, MaxWidth: 14}, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := "" +
+		"┌────────┬───┐\n" +
+		"│ a      │ b │\n" +
+		"├────────┼───┤\n" +
+		"│ 01234… │ x │\n" +
+		"│ あい…  │ y │\n" +
+		"└────────┴───┘\n"
+	if got := d.contents[0].content; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+}
+
+func TestDisplayTextTableError(t *testing.T) {
+	tbl := &sliceTable{
+		cols: []string{"a", "b"},
+		rows: [][]interface{}{{1}},
+	}
+	var d fakeDisplayer
+	err := DisplayTextTable(&d, tbl, nil)
+	if want := "row 0 has 1 values but the table has 2 columns"; err == nil || err.Error() != want {
+		t.Errorf("Got %v; want %q", err, want)
+	}
+	if len(d.contents) != 0 {
+		t.Errorf("Unexpected contents: %v", d.contents)
+	}
+}
+
+func TestStringWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"abc", 3},
+		{"日本", 4},
+		{"é", 1},
+		{"ｈｉ", 4},
+		{"😀", 2},
+		{"", 0},
+	}
+	for _, tc := range tests {
+		if got := stringWidth(tc.s); got != tc.want {
+			t.Errorf("stringWidth(%q) = %d; want %d", tc.s, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// throttler calls render at most once per interval. Updates in an interval are coalesced into
+// a delayed render. Renders are serialized.
+type throttler struct {
+	render func()
+
+	mu       sync.Mutex
+	interval time.Duration
+	last     time.Time
+	timer    *time.Timer
+	// stopped is true if stop is called. render is not called after that.
+	stopped bool
+}
+
+func (t *throttler) setInterval(interval time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.interval = interval
+}
+
+// update calls render or schedules render if render was called recently.
+// The first update always calls render immediately.
+func (t *throttler) update() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopped {
+		return
+	}
+	if t.timer != nil {
+		// The scheduled render shows the latest state.
+		return
+	}
+	if wait := t.interval - time.Since(t.last); !t.last.IsZero() && wait > 0 {
+		t.timer = time.AfterFunc(wait, guardCallback("a throttled render", func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.stopped {
+				// The timer fired while stop was rendering.
+				return
+			}
+			t.timer = nil
+			t.callRender()
+		}))
+		return
+	}
+	t.callRender()
+}
+
+// flush calls render immediately if render is scheduled.
+func (t *throttler) flush() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timer == nil {
+		return
+	}
+	t.timer.Stop()
+	t.timer = nil
+	t.callRender()
+}
+
+// stop calls render immediately if render is scheduled and ignores later updates.
+func (t *throttler) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopped {
+		return
+	}
+	t.stopped = true
+	if t.timer == nil {
+		return
+	}
+	t.timer.Stop()
+	t.timer = nil
+	t.callRender()
+}
+
+// callRender calls render. t.mu must be held.
+func (t *throttler) callRender() {
+	t.render()
+	t.last = time.Now()
+}
@@ -0,0 +1,36 @@
+package core
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// execTimeoutReason is the cancel reason of executions canceled by the timeout set with SetExecTimeout.
+const execTimeoutReason = "timeout exceeded"
+
+// execTimeout is the duration set by SetExecTimeout. To access this var, use atomic.Store/LoadInt64.
+var execTimeout int64
+
+// SetExecTimeout cancels executions which run longer than d. Executions tagged with TagNoTimeout
+// are not canceled (See ExecLgoEntryPointWithTags). The timeout is disabled if d is not positive (the default).
+func SetExecTimeout(d time.Duration) {
+	atomic.StoreInt64(&execTimeout, int64(d))
+}
+
+// startTimeout starts the timer of the execution timeout for e if the timeout is enabled.
+func (e *ExecutionState) startTimeout() {
+	d := time.Duration(atomic.LoadInt64(&execTimeout))
+	if d <= 0 || e.tags.noTimeout {
+		return
+	}
+	e.timeoutTimer = time.AfterFunc(d, func() {
+		e.cancelWithReason(execTimeoutReason)
+	})
+}
+
+// stopTimeout stops the timer started by startTimeout.
+func (e *ExecutionState) stopTimeout() {
+	if e.timeoutTimer != nil {
+		e.timeoutTimer.Stop()
+	}
+}
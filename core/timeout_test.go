@@ -0,0 +1,28 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExecTimeout(t *testing.T) {
+	defer SetExecTimeout(0)
+	SetExecTimeout(10 * time.Millisecond)
+	atomic.StoreUint32(&isRunning, 0)
+	r, err := ExecLgoEntryPointReport(LgoContext{Context: context.Background()}, func() {
+		<-GetExecContext().Done()
+	})
+	if want := "canceled: timeout exceeded"; err == nil || err.Error() != want {
+		t.Errorf("Got %v; want %q", err, want)
+	}
+	if r.CancelReason != execTimeoutReason {
+		t.Errorf("Got %q; want %q", r.CancelReason, execTimeoutReason)
+	}
+	// Executions finished before the timeout are not canceled.
+	SetExecTimeout(time.Hour)
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	timeSeriesWidth  = 600
+	timeSeriesHeight = 200
+	// timeSeriesMargin is the space for labels around the chart.
+	timeSeriesMargin = 40
+)
+
+// chartPoint is a point of a line chart.
+type chartPoint struct {
+	x, y float64
+}
+
+// lttb downsamples points sorted by x to at most threshold points with the largest-triangle-three-buckets algorithm,
+// which keeps the first and last points and picks the point forming the largest triangle in each bucket.
+// threshold must be at least 3.
+func lttb(points []chartPoint, threshold int) []chartPoint {
+	if len(points) <= threshold {
+		return points
+	}
+	sampled := make([]chartPoint, 0, threshold)
+	sampled = append(sampled, points[0])
+	// Buckets except the first and last points.
+	every := float64(len(points)-2) / float64(threshold-2)
+	a := 0
+	for i := 0; i < threshold-2; i++ {
+		// The average of the next bucket.
+		nextStart := int(float64(i+1)*every) + 1
+		nextEnd := int(float64(i+2)*every) + 1
+		if nextEnd > len(points) {
+			nextEnd = len(points)
+		}
+		var avg chartPoint
+		for _, p := range points[nextStart:nextEnd] {
+			avg.x += p.x
+			avg.y += p.y
+		}
+		n := float64(nextEnd - nextStart)
+		avg.x /= n
+		avg.y /= n
+
+		start := int(float64(i)*every) + 1
+		end := nextStart
+		maxArea := -1.0
+		next := start
+		for j := start; j < end; j++ {
+			p := points[j]
+			area := math.Abs((points[a].x-avg.x)*(p.y-points[a].y) - (points[a].x-p.x)*(avg.y-points[a].y))
+			if area > maxArea {
+				maxArea = area
+				next = j
+			}
+		}
+		sampled = append(sampled, points[next])
+		a = next
+	}
+	return append(sampled, points[len(points)-1])
+}
+
+// DisplayTimeSeries displays values at times as an SVG line chart.
+// The series is downsampled to at most maxPoints points with the largest-triangle-three-buckets algorithm,
+// which preserves the shape of the series, to keep the output small. If maxPoints is not positive,
+// all points are drawn. Points are sorted by times and NaNs and ±Inf in values are skipped.
+func DisplayTimeSeries(d DataDisplayer, times []time.Time, values []float64, maxPoints int, id *string) error {
+	if len(times) != len(values) {
+		return fmt.Errorf("times and values have different lengths: %d and %d", len(times), len(values))
+	}
+	if maxPoints > 0 && maxPoints < 3 {
+		return errors.New("maxPoints must be at least 3")
+	}
+	type sample struct {
+		t time.Time
+		v float64
+	}
+	var samples []sample
+	for i, v := range values {
+		if isFinite(v) {
+			samples = append(samples, sample{times[i], v})
+		}
+	}
+	if len(samples) == 0 {
+		return errors.New("no values to display")
+	}
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].t.Before(samples[j].t) })
+	points := make([]chartPoint, len(samples))
+	for i, s := range samples {
+		points[i] = chartPoint{float64(s.t.Sub(samples[0].t)), s.v}
+	}
+	if maxPoints > 0 {
+		points = lttb(points, maxPoints)
+	}
+	d.SVG(timeSeriesSVG(points, samples[0].t, samples[len(samples)-1].t), id)
+	return nil
+}
+
+// timeSeriesSVG renders points as an SVG line chart. x of points is the duration from start in nanoseconds.
+func timeSeriesSVG(points []chartPoint, start, end time.Time) string {
+	minY, maxY := points[0].y, points[0].y
+	for _, p := range points {
+		minY = math.Min(minY, p.y)
+		maxY = math.Max(maxY, p.y)
+	}
+	maxX := points[len(points)-1].x
+	const w, h, m = timeSeriesWidth, timeSeriesHeight, timeSeriesMargin
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg width="%d" height="%d" xmlns="http://www.w3.org/2000/svg" font-size="10" font-family="sans-serif">`, w, h)
+	fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d" fill="none" stroke="#ccc"/>`, m, m/2, w-2*m, h-m)
+	buf.WriteString(`<polyline fill="none" stroke="steelblue" stroke-width="1" points="`)
+	for i, p := range points {
+		x := float64(w) / 2
+		if maxX > 0 {
+			x = m + p.x*(w-2*m)/maxX
+		}
+		y := float64(h) / 2
+		if maxY > minY {
+			y = m/2 + (maxY-p.y)*(h-m)/(maxY-minY)
+		}
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		fmt.Fprintf(&buf, "%.1f,%.1f", x, y)
+	}
+	buf.WriteString(`"/>`)
+	label := func(x, y int, anchor, text string) {
+		fmt.Fprintf(&buf, `<text x="%d" y="%d" text-anchor="%s">%s</text>`, x, y, anchor, html.EscapeString(text))
+	}
+	label(m-4, m/2+10, "end", fmt.Sprintf("%.4g", maxY))
+	label(m-4, h-m/2, "end", fmt.Sprintf("%.4g", minY))
+	label(m, h-m/2+14, "start", start.Format(time.RFC3339))
+	label(w-m, h-m/2+14, "end", end.Format(time.RFC3339))
+	buf.WriteString("</svg>")
+	return buf.String()
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLTTB(t *testing.T) {
+	var points []chartPoint
+	for i := 0; i < 100; i++ {
+		points = append(points, chartPoint{float64(i), 0})
+	}
+	// A spike must be kept.
+	points[50].y = 10
+	got := lttb(points, 10)
+	if len(got) != 10 {
+		t.Fatalf("Got %d points; want 10", len(got))
+	}
+	if got[0] != points[0] || got[9] != points[99] {
+		t.Errorf("The first and last points are not kept: %v", got)
+	}
+	found := false
+	for i, p := range got {
+		if i > 0 && p.x <= got[i-1].x {
+			t.Errorf("Points are not sorted: %v", got)
+		}
+		if p == points[50] {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("The spike is dropped: %v", got)
+	}
+	if got := lttb(points[:5], 10); !reflect.DeepEqual(got, points[:5]) {
+		t.Errorf("Got %v; want %v", got, points[:5])
+	}
+}
+
+func TestDisplayTimeSeries(t *testing.T) {
+	start := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	var times []time.Time
+	var values []float64
+	for i := 999; i >= 0; i-- {
+		// Unsorted times.
+		times = append(times, start.Add(time.Duration(i)*time.Second))
+		values = append(values, math.Sin(float64(i)/100))
+	}
+	values[10] = math.NaN()
+	values[20] = math.Inf(1)
+	values[30] = math.Inf(-1)
+	var d fakeDisplayer
+	if err := DisplayTimeSeries(&d, times, values, 50, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.contents) != 1 || d.contents[0].contentType != "image/svg+xml" {
+		t.Fatalf("Unexpected outputs: %v", d.contents)
+	}
+	svg := d.contents[0].content.(string)
+	i := strings.Index(svg, `points="`)
+	pts := strings.Fields(svg[i+len(`points="`) : i+strings.Index(svg[i:], `"/>`)])
+	if len(pts) != 50 {
+		t.Errorf("Got %d points; want 50", len(pts))
+	}
+	// ±Inf are skipped and do not break the scale.
+	if strings.Contains(svg, "NaN") || strings.Contains(svg, "Inf") {
+		t.Errorf("Non-finite values are rendered: %s", svg)
+	}
+	if pts[0] != "40.0,100.0" {
+		// sin(0) is at the middle of the chart.
+		t.Errorf("Unexpected first point: %s", pts[0])
+	}
+	for _, want := range []string{">2018-01-01T00:00:00Z<", ">2018-01-01T00:16:39Z<"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("%q does not contain %q", svg, want)
+		}
+	}
+}
+
+func TestDisplayTimeSeriesError(t *testing.T) {
+	var d fakeDisplayer
+	now := time.Now()
+	if err := DisplayTimeSeries(&d, []time.Time{now}, []float64{1, 2}, 0, nil); err == nil {
+		t.Error("DisplayTimeSeries with different lengths succeeded unexpectedly")
+	}
+	if err := DisplayTimeSeries(&d, []time.Time{now}, []float64{1}, 2, nil); err == nil {
+		t.Error("DisplayTimeSeries with maxPoints = 2 succeeded unexpectedly")
+	}
+	if err := DisplayTimeSeries(&d, []time.Time{now}, []float64{math.NaN()}, 0, nil); err == nil {
+		t.Error("DisplayTimeSeries without values succeeded unexpectedly")
+	}
+	// A single point is drawn at the center.
+	if err := DisplayTimeSeries(&d, []time.Time{now}, []float64{1}, 0, nil); err != nil {
+		t.Error(err)
+	}
+}
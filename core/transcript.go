@@ -0,0 +1,196 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// OutputEvent is an output recorded in a transcript of an execution.
+type OutputEvent struct {
+	// Time is when the output was emitted.
+	Time time.Time
+	// MIMEType is the type of Payload. The results of LgoPrintln are recorded as "text/plain".
+	MIMEType string
+	// Payload is the content of the output, which is a string, []byte or a value passed to DataDisplayer.Raw.
+	Payload interface{}
+	// ID is the display ID of the output. Empty if the output does not have an ID.
+	ID string
+	// Truncated is true if Payload is truncated (or dropped) to bound the size of the transcript.
+	Truncated bool
+}
+
+// defaultTranscriptLimit is the default total bytes of payloads recorded in a transcript.
+const defaultTranscriptLimit = 1 << 20
+
+var transcriptMu sync.Mutex
+var transcriptEnabled bool
+var transcriptLimit = defaultTranscriptLimit
+
+// lastTranscript is the transcript of the last (or current) execution.
+var lastTranscript *transcript
+
+// finishedTranscript is the transcript of the last finished execution.
+var finishedTranscript *transcript
+
+// SetTranscriptEnabled enables or disables recording of outputs of executions.
+// When enabled, outputs printed with LgoPrintln and displayed with the DataDisplayer of an execution are
+// recorded and available from LastExecutionTranscript. The transcript is reset at the start of each execution.
+func SetTranscriptEnabled(enabled bool) {
+	transcriptMu.Lock()
+	defer transcriptMu.Unlock()
+	transcriptEnabled = enabled
+}
+
+// SetTranscriptLimit sets the max total bytes of payloads recorded in a transcript.
+// The payload exceeding the limit is truncated and outputs after it are not recorded. The default is 1MB.
+// If n is not positive, only the first event is recorded without its payload.
+func SetTranscriptLimit(n int) {
+	if n < 0 {
+		n = 0
+	}
+	transcriptMu.Lock()
+	defer transcriptMu.Unlock()
+	transcriptLimit = n
+}
+
+// LastExecutionTranscript returns the outputs recorded in the last execution.
+// It returns nil if transcripts are disabled.
+func LastExecutionTranscript() []OutputEvent {
+	transcriptMu.Lock()
+	t := lastTranscript
+	transcriptMu.Unlock()
+	if t == nil {
+		return nil
+	}
+	return t.snapshot()
+}
+
+type transcript struct {
+	mu     sync.Mutex
+	events []OutputEvent
+	// remaining is the number of bytes which can be recorded.
+	remaining int
+	// full is true if a payload was truncated. Events are not recorded after that.
+	full bool
+}
+
+func (t *transcript) snapshot() []OutputEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]OutputEvent(nil), t.events...)
+}
+
+func (t *transcript) record(contentType string, payload interface{}, id *string) {
+	ev := OutputEvent{
+		Time:     time.Now(),
+		MIMEType: contentType,
+		Payload:  payload,
+	}
+	if id != nil {
+		ev.ID = *id
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.full {
+		return
+	}
+	switch p := payload.(type) {
+	case string:
+		if len(p) > t.remaining {
+			// Don't split a multi-byte character.
+			n := t.remaining
+			for n > 0 && !utf8.RuneStart(p[n]) {
+				n--
+			}
+			ev.Payload = p[:n]
+			ev.Truncated = true
+		}
+		t.remaining -= len(ev.Payload.(string))
+	case []byte:
+		if len(p) > t.remaining {
+			p = p[:t.remaining]
+			ev.Truncated = true
+		}
+		// Copy the bytes because callers may reuse them.
+		ev.Payload = append([]byte(nil), p...)
+		t.remaining -= len(p)
+	default:
+		b, err := json.Marshal(payload)
+		if len(b) > t.remaining || err != nil {
+			ev.Payload = nil
+			ev.Truncated = true
+		} else {
+			t.remaining -= len(b)
+		}
+	}
+	t.events = append(t.events, ev)
+	t.full = ev.Truncated
+}
+
+// startOutputRecording wraps the displayer of e to record outputs of e.
+// Outputs are recorded to the transcript of e if transcripts are enabled.
+func (e *ExecutionState) startOutputRecording() {
+	transcriptMu.Lock()
+	defer transcriptMu.Unlock()
+	if transcriptEnabled {
+		e.transcript = &transcript{remaining: transcriptLimit}
+	}
+	lastTranscript = e.transcript
+	if e.Context.Display != nil {
+		e.Context.Display = &recordingDisplayer{e.Context.Display, e}
+	}
+}
+
+// finishOutputRecording stores the transcript of e as the transcript of the last finished execution.
+func (e *ExecutionState) finishOutputRecording() {
+	transcriptMu.Lock()
+	defer transcriptMu.Unlock()
+	finishedTranscript = e.transcript
+}
+
+// recordOutput records an output of e.
+// Only the sizes of string and []byte payloads are counted to OutputBytes of ExecReport.
+func (e *ExecutionState) recordOutput(contentType string, payload interface{}, id *string) {
+	switch p := payload.(type) {
+	case string:
+		atomic.AddInt64(&e.outputBytes, int64(len(p)))
+	case []byte:
+		atomic.AddInt64(&e.outputBytes, int64(len(p)))
+	}
+	if e.transcript != nil {
+		e.transcript.record(contentType, payload, id)
+	}
+}
+
+// recordPrint records the output of LgoPrintln to the current execution.
+func recordPrint(args []interface{}) {
+	e := getExecState()
+	if e == nil {
+		return
+	}
+	e.recordPrint(args)
+}
+
+// recordPrint records args printed with LgoPrintln to e.
+func (e *ExecutionState) recordPrint(args []interface{}) {
+	if e.transcript != nil {
+		e.recordOutput("text/plain", fmt.Sprintln(args...), nil)
+		return
+	}
+	// Count the bytes without building the string.
+	var c byteCounter
+	fmt.Fprintln(&c, args...)
+	atomic.AddInt64(&e.outputBytes, int64(c))
+}
+
+// byteCounter is an io.Writer which counts the bytes written.
+type byteCounter int
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
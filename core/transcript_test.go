@@ -0,0 +1,99 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTranscript(t *testing.T) {
+	SetTranscriptEnabled(true)
+	defer SetTranscriptEnabled(false)
+	SetTranscriptLimit(10)
+	defer SetTranscriptLimit(defaultTranscriptLimit)
+
+	atomic.StoreUint32(&isRunning, 0)
+	var d fakeDisplayer
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Display: &d}, func() {
+		ctx := GetExecContext()
+		LgoPrintln("a", 1)
+		id := ""
+		ctx.Display.HTML("<b>", &id)
+		ctx.Display.PNG([]byte("0123456789"), nil)
+		// Outputs after the truncated output are not recorded.
+		ctx.Display.Text("", nil)
+		LgoPrintln("b")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	events := LastExecutionTranscript()
+	if len(events) != 3 {
+		t.Fatalf("Got %d events; want 3: %v", len(events), events)
+	}
+	if ev := events[0]; ev.MIMEType != "text/plain" || ev.Payload != "a 1\n" || ev.Truncated {
+		t.Errorf("Unexpected event: %+v", ev)
+	}
+	if ev := events[1]; ev.MIMEType != "text/html" || ev.Payload != "<b>" || ev.ID != "id1" {
+		t.Errorf("Unexpected event: %+v", ev)
+	}
+	if ev := events[2]; ev.MIMEType != "image/png" || string(ev.Payload.([]byte)) != "012" || !ev.Truncated {
+		t.Errorf("Unexpected event: %+v", ev)
+	}
+	if len(d.contents) != 3 {
+		t.Errorf("Contents are not displayed: %v", d.contents)
+	}
+
+	// The transcript is reset.
+	atomic.StoreUint32(&isRunning, 0)
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Display: &d}, func() {}); err != nil {
+		t.Fatal(err)
+	}
+	if events := LastExecutionTranscript(); len(events) != 0 {
+		t.Errorf("Transcript is not reset: %v", events)
+	}
+}
+
+func TestTranscriptNegativeLimit(t *testing.T) {
+	SetTranscriptEnabled(true)
+	defer SetTranscriptEnabled(false)
+	SetTranscriptLimit(-1)
+	defer SetTranscriptLimit(defaultTranscriptLimit)
+
+	atomic.StoreUint32(&isRunning, 0)
+	var d fakeDisplayer
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Display: &d}, func() {
+		GetExecContext().Display.HTML("<b>", nil)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	events := LastExecutionTranscript()
+	if len(events) != 1 {
+		t.Fatalf("Got %d events; want 1: %v", len(events), events)
+	}
+	if ev := events[0]; ev.MIMEType != "text/html" || ev.Payload != "" || !ev.Truncated {
+		t.Errorf("Unexpected event: %+v", ev)
+	}
+}
+
+func TestTranscriptTruncateRune(t *testing.T) {
+	SetTranscriptEnabled(true)
+	defer SetTranscriptEnabled(false)
+	SetTranscriptLimit(5)
+	defer SetTranscriptLimit(defaultTranscriptLimit)
+
+	atomic.StoreUint32(&isRunning, 0)
+	var d fakeDisplayer
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Display: &d}, func() {
+		// "あ" is encoded in 3 bytes.
+		GetExecContext().Display.Text("aあい", nil)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	events := LastExecutionTranscript()
+	if len(events) != 1 {
+		t.Fatalf("Got %d events; want 1: %v", len(events), events)
+	}
+	if ev := events[0]; ev.Payload != "aあ" || !ev.Truncated {
+		t.Errorf("Unexpected event: %+v", ev)
+	}
+}
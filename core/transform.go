@@ -0,0 +1,187 @@
+package core
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// transformMu protects displayTransform.
+var transformMu sync.Mutex
+
+// displayTransform is the function set by SetDisplayTransform.
+var displayTransform func(mime string, payload interface{}) interface{}
+
+// SetDisplayTransform sets the function to transform every representation displayed with
+// the DataDisplayer of code executions before it is sent (e.g. to inject CSS, rewrite image URLs
+// or sanitize HTML). fn is called for each MIME type of a display with the payload
+// (string for text types, []byte for images and PDF) and the returned value is displayed instead.
+// If fn returns nil, the representation is dropped.
+//
+// Outputs displayed in fn are not transformed again to avoid infinite recursion.
+// The transform is applied to executions started after SetDisplayTransform is called.
+// If fn is nil, outputs are not transformed (the default).
+func SetDisplayTransform(fn func(mime string, payload interface{}) interface{}) {
+	transformMu.Lock()
+	defer transformMu.Unlock()
+	displayTransform = fn
+}
+
+// startDisplayTransform wraps the displayer of e with the transform set by SetDisplayTransform.
+func (e *ExecutionState) startDisplayTransform() {
+	transformMu.Lock()
+	fn := displayTransform
+	transformMu.Unlock()
+	if fn != nil && e.Context.Display != nil {
+		e.Context.Display = &transformingDisplayer{d: e.Context.Display, fn: fn, active: make(map[uint64]bool)}
+	}
+}
+
+// goroutineID returns the ID of the current goroutine parsed from its stack trace.
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// transformingDisplayer is a DataDisplayer which applies a transform to outputs.
+type transformingDisplayer struct {
+	d  DataDisplayer
+	fn func(mime string, payload interface{}) interface{}
+
+	mu sync.Mutex
+	// active keeps the IDs of goroutines in which fn is running.
+	active map[uint64]bool
+}
+
+// apply returns the payload transformed by fn.
+// The payload is returned as is if apply is called from fn.
+func (t *transformingDisplayer) apply(mime string, payload interface{}) interface{} {
+	gid := goroutineID()
+	t.mu.Lock()
+	if t.active[gid] {
+		t.mu.Unlock()
+		return payload
+	}
+	t.active[gid] = true
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.active, gid)
+		t.mu.Unlock()
+	}()
+	return t.fn(mime, payload)
+}
+
+// text displays a transformed text output with show.
+// The output is displayed with Raw if fn changes the type of the payload.
+func (t *transformingDisplayer) text(mime, s string, id *string, show func(s string, id *string)) {
+	v := t.apply(mime, s)
+	if v == nil {
+		return
+	}
+	if s, ok := v.(string); ok {
+		show(s, id)
+		return
+	}
+	t.d.Raw(mime, v, id)
+}
+
+// binary displays a transformed binary output with show.
+func (t *transformingDisplayer) binary(mime string, b []byte, id *string, show func(b []byte, id *string)) {
+	v := t.apply(mime, b)
+	if v == nil {
+		return
+	}
+	if b, ok := v.([]byte); ok {
+		show(b, id)
+		return
+	}
+	t.d.Raw(mime, v, id)
+}
+
+// bundle returns the bundle transformed per MIME type. Dropped representations are removed.
+func (t *transformingDisplayer) bundle(bundle map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{})
+	for mime, v := range bundle {
+		if v = t.apply(mime, v); v != nil {
+			out[mime] = v
+		}
+	}
+	return out
+}
+
+func (t *transformingDisplayer) JavaScript(s string, id *string) {
+	t.text("application/javascript", s, id, t.d.JavaScript)
+}
+func (t *transformingDisplayer) HTML(s string, id *string) { t.text("text/html", s, id, t.d.HTML) }
+func (t *transformingDisplayer) Markdown(s string, id *string) {
+	t.text("text/markdown", s, id, t.d.Markdown)
+}
+func (t *transformingDisplayer) Latex(s string, id *string) { t.text("text/latex", s, id, t.d.Latex) }
+func (t *transformingDisplayer) SVG(s string, id *string)   { t.text("image/svg+xml", s, id, t.d.SVG) }
+func (t *transformingDisplayer) Text(s string, id *string)  { t.text("text/plain", s, id, t.d.Text) }
+func (t *transformingDisplayer) PNG(b []byte, id *string)   { t.binary("image/png", b, id, t.d.PNG) }
+func (t *transformingDisplayer) JPEG(b []byte, id *string)  { t.binary("image/jpeg", b, id, t.d.JPEG) }
+func (t *transformingDisplayer) GIF(b []byte, id *string)   { t.binary("image/gif", b, id, t.d.GIF) }
+func (t *transformingDisplayer) PDF(b []byte, id *string) {
+	t.binary("application/pdf", b, id, t.d.PDF)
+}
+
+func (t *transformingDisplayer) Raw(contentType string, v interface{}, id *string) error {
+	if v = t.apply(contentType, v); v == nil {
+		return nil
+	}
+	return t.d.Raw(contentType, v, id)
+}
+
+func (t *transformingDisplayer) Widget(modelID string, id *string) error {
+	view, err := WidgetView(modelID)
+	if err != nil {
+		return err
+	}
+	v := t.apply(WidgetViewMIMEType, view)
+	if v == nil {
+		return nil
+	}
+	return t.d.Raw(WidgetViewMIMEType, v, id)
+}
+
+func (t *transformingDisplayer) UpdateDisplay(id string, bundle map[string]interface{}) error {
+	if err := CheckDisplayUpdate(id, bundle); err != nil {
+		return err
+	}
+	bundle = t.bundle(bundle)
+	if len(bundle) == 0 {
+		return nil
+	}
+	return t.d.UpdateDisplay(id, bundle)
+}
+
+func (t *transformingDisplayer) SupportedMIMETypes() []string {
+	return t.d.SupportedMIMETypes()
+}
+
+// DisplayBundle implements BundleDisplayer if the wrapped DataDisplayer implements BundleDisplayer.
+func (t *transformingDisplayer) DisplayBundle(bundle map[string]interface{}, metadata map[string]interface{}, id *string) error {
+	bd, ok := t.d.(BundleDisplayer)
+	if !ok {
+		return errBundleUnsupported
+	}
+	bundle = t.bundle(bundle)
+	if len(bundle) == 0 {
+		return nil
+	}
+	return bd.DisplayBundle(bundle, metadata, id)
+}
+
+// DisplayLazy implements LazyDisplayer. Contents are computed immediately to apply fn.
+func (t *transformingDisplayer) DisplayLazy(fn func() (mime string, payload interface{}, err error), id *string) error {
+	return errLazyUnsupported
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDisplayTransform(t *testing.T) {
+	SetDisplayTransform(func(mime string, payload interface{}) interface{} {
+		switch mime {
+		case "text/html":
+			// Outputs displayed in the transform are not transformed again.
+			CurrentDisplay().Text("transformed", nil)
+			return "<style></style>" + payload.(string)
+		case "image/png":
+			return nil
+		}
+		return payload
+	})
+	defer SetDisplayTransform(nil)
+
+	atomic.StoreUint32(&isRunning, 0)
+	var d fakeDisplayer
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Display: &d}, func() {
+		display := GetExecContext().Display
+		display.HTML("<b>x</b>", nil)
+		display.PNG([]byte("png"), nil)
+		display.Markdown("*y*", nil)
+		if err := display.UpdateDisplay("id", map[string]interface{}{
+			"text/html":  "<i>z</i>",
+			"image/png":  []byte("png"),
+			"text/plain": "z",
+		}); err != nil {
+			t.Error(err)
+		}
+		// All representations are dropped.
+		if err := display.UpdateDisplay("id", map[string]interface{}{"image/png": []byte("png")}); err != nil {
+			t.Error(err)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := []displayed{
+		{"text/plain", "transformed", ""},
+		{"text/html", "<style></style><b>x</b>", ""},
+		{"text/markdown", "*y*", ""},
+		{"text/plain", "transformed", ""},
+		{"update", map[string]interface{}{
+			"text/html":  "<style></style><i>z</i>",
+			"text/plain": "z",
+		}, "id"},
+	}
+	if !reflect.DeepEqual(d.contents, want) {
+		t.Errorf("Got %v; want %v", d.contents, want)
+	}
+}
+
+func TestDisplayTransformChangeType(t *testing.T) {
+	SetDisplayTransform(func(mime string, payload interface{}) interface{} {
+		if mime == "text/plain" {
+			return map[string]interface{}{"text": payload}
+		}
+		return payload
+	})
+	defer SetDisplayTransform(nil)
+
+	atomic.StoreUint32(&isRunning, 0)
+	var d fakeDisplayer
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background(), Display: &d}, func() {
+		GetExecContext().Display.Text("a", nil)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := []displayed{{"text/plain", map[string]interface{}{"text": "a"}, ""}}
+	if !reflect.DeepEqual(d.contents, want) {
+		t.Errorf("Got %v; want %v", d.contents, want)
+	}
+}
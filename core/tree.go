@@ -0,0 +1,123 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"reflect"
+	"sync/atomic"
+)
+
+const (
+	// treeMaxDepth is the maximum depth of nodes rendered with DisplayTree.
+	treeMaxDepth = 32
+	// treeMaxNodes is the maximum number of nodes rendered with DisplayTree.
+	treeMaxNodes = 10000
+)
+
+// TreeNode is the interface that wraps nodes of trees displayed with DisplayTree (e.g. ASTs, file trees
+// and JSON structures).
+type TreeNode interface {
+	// Label returns the text of the node.
+	Label() string
+	// Children returns the child nodes.
+	Children() []TreeNode
+}
+
+// treeSeq makes IDs of trees unique in a notebook. Use atomic.AddUint64.
+var treeSeq uint64
+
+// treeScript makes the nodes of the tree whose element ID is %[1]q collapsible.
+const treeScript = `<script>
+(function() {
+  var root = document.getElementById(%[1]q);
+  if (!root) {
+    return;
+  }
+  var toggles = root.querySelectorAll(".lgo-tree-toggle");
+  for (var i = 0; i < toggles.length; i++) {
+    toggles[i].onclick = function() {
+      var children = this.parentNode.querySelector(":scope > ul");
+      var open = children.style.display === "none";
+      children.style.display = open ? "" : "none";
+      this.textContent = open ? "▾" : "▸";
+    };
+  }
+})();
+</script>`
+
+// DisplayTree displays the tree rooted at root as nested lists in HTML. Nodes are collapsible with
+// JavaScript if SetJavaScriptEnabled(true) is called. Otherwise, all nodes are expanded because
+// JavaScript output is disabled in JupyterLab.
+//
+// Nodes which are pointers and appear again in their descendants are rendered with "(cycle)" and
+// not expanded. Nodes deeper than 32 levels or beyond the first 10000 nodes are omitted with "…".
+func DisplayTree(d DataDisplayer, root TreeNode, id *string) {
+	r := &treeRenderer{js: isJavaScriptEnabled(), visiting: make(map[visitKey]bool)}
+	elemID := fmt.Sprintf("lgo-tree-%d", atomic.AddUint64(&treeSeq, 1))
+	fmt.Fprintf(&r.buf, `<ul class="lgo-tree" id="%s" style="list-style:none;padding-left:1em">`, elemID)
+	if root != nil {
+		r.node(root, 0)
+	}
+	r.buf.WriteString("</ul>")
+	if r.js {
+		fmt.Fprintf(&r.buf, treeScript, elemID)
+	}
+	d.HTML(r.buf.String(), id)
+}
+
+type treeRenderer struct {
+	buf bytes.Buffer
+	js  bool
+	// nodes is the number of rendered nodes.
+	nodes int
+	// visiting keeps the pointer nodes on the path from the root to detect cycles.
+	visiting map[visitKey]bool
+}
+
+// node renders n in depth as a list item.
+func (r *treeRenderer) node(n TreeNode, depth int) {
+	if depth >= treeMaxDepth {
+		r.buf.WriteString("<li>…</li>")
+		return
+	}
+	r.nodes++
+	label := html.EscapeString(n.Label())
+	if v := reflect.ValueOf(n); v.Kind() == reflect.Ptr {
+		k := visitKey{ptr: v.Pointer(), typ: v.Type()}
+		if r.visiting[k] {
+			fmt.Fprintf(&r.buf, "<li>%s <i>(cycle)</i></li>", label)
+			return
+		}
+		r.visiting[k] = true
+		defer delete(r.visiting, k)
+	}
+	children := n.Children()
+	if len(children) == 0 {
+		if r.js {
+			// Align leaves with nodes which have toggles.
+			r.buf.WriteString(`<li><span style="display:inline-block;width:1em"></span>` + label + "</li>")
+		} else {
+			r.buf.WriteString("<li>" + label + "</li>")
+		}
+		return
+	}
+	r.buf.WriteString("<li>")
+	if r.js {
+		r.buf.WriteString(`<span class="lgo-tree-toggle" style="cursor:pointer;display:inline-block;width:1em">▾</span>`)
+	}
+	r.buf.WriteString(label)
+	r.buf.WriteString(`<ul style="list-style:none;padding-left:1em">`)
+	for _, c := range children {
+		if c == nil {
+			continue
+		}
+		if r.nodes >= treeMaxNodes {
+			// Omit the rest of children with an item.
+			r.buf.WriteString("<li>…</li>")
+			break
+		}
+		r.node(c, depth+1)
+	}
+	r.buf.WriteString("</ul></li>")
+}
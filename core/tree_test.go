@@ -0,0 +1,97 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+type testTreeNode struct {
+	label    string
+	children []TreeNode
+}
+
+func (n *testTreeNode) Label() string        { return n.label }
+func (n *testTreeNode) Children() []TreeNode { return n.children }
+
+func TestDisplayTree(t *testing.T) {
+	root := &testTreeNode{label: "root", children: []TreeNode{
+		&testTreeNode{label: "<a>"},
+		&testTreeNode{label: "b", children: []TreeNode{&testTreeNode{label: "c"}}},
+	}}
+	var d fakeDisplayer
+	id := ""
+	DisplayTree(&d, root, &id)
+	if len(d.contents) != 1 || id != "id1" {
+		t.Fatalf("Unexpected contents: %v (id: %q)", d.contents, id)
+	}
+	got := d.contents[0].content.(string)
+	const list = `<ul style="list-style:none;padding-left:1em">`
+	want := "<li>root" + list + "<li>&lt;a&gt;</li><li>b" + list + "<li>c</li></ul></li></ul></li></ul>"
+	if !strings.HasPrefix(got, `<ul class="lgo-tree" id="lgo-tree-`) || !strings.HasSuffix(got, want) {
+		t.Errorf("Got %q; want the suffix %q", got, want)
+	}
+	if strings.Contains(got, "<script>") {
+		t.Errorf("Unexpected script: %q", got)
+	}
+}
+
+func TestDisplayTreeJavaScript(t *testing.T) {
+	defer SetJavaScriptEnabled(false)
+	SetJavaScriptEnabled(true)
+	root := &testTreeNode{label: "root", children: []TreeNode{&testTreeNode{label: "a"}}}
+	var d fakeDisplayer
+	DisplayTree(&d, root, nil)
+	got := d.contents[0].content.(string)
+	for _, s := range []string{`<span class="lgo-tree-toggle"`, "<script>"} {
+		if !strings.Contains(got, s) {
+			t.Errorf("%q does not contain %q", got, s)
+		}
+	}
+}
+
+func TestDisplayTreeCycle(t *testing.T) {
+	root := &testTreeNode{label: "root"}
+	child := &testTreeNode{label: "child", children: []TreeNode{root}}
+	root.children = []TreeNode{child, child}
+	var d fakeDisplayer
+	DisplayTree(&d, root, nil)
+	got := d.contents[0].content.(string)
+	// child is not a cycle when it appears twice as siblings.
+	if n := strings.Count(got, "<li>root <i>(cycle)</i></li>"); n != 2 {
+		t.Errorf("Got %d cycles; want 2: %q", n, got)
+	}
+}
+
+// chainNode is a non-pointer node whose descendants never end.
+type chainNode int
+
+func (n chainNode) Label() string        { return "n" }
+func (n chainNode) Children() []TreeNode { return []TreeNode{n + 1} }
+
+func TestDisplayTreeDepth(t *testing.T) {
+	var d fakeDisplayer
+	DisplayTree(&d, chainNode(0), nil)
+	got := d.contents[0].content.(string)
+	if n := strings.Count(got, "<li>n"); n != treeMaxDepth {
+		t.Errorf("Got %d nodes; want %d", n, treeMaxDepth)
+	}
+	if !strings.Contains(got, "<li>…</li>") {
+		t.Errorf("The omitted nodes are not rendered: %q", got)
+	}
+}
+
+func TestDisplayTreeMaxNodes(t *testing.T) {
+	root := &testTreeNode{label: "root"}
+	for i := 0; i < treeMaxNodes+100; i++ {
+		root.children = append(root.children, &testTreeNode{label: "c"})
+	}
+	var d fakeDisplayer
+	DisplayTree(&d, root, nil)
+	got := d.contents[0].content.(string)
+	if n := strings.Count(got, "<li>c</li>"); n != treeMaxNodes-1 {
+		t.Errorf("Got %d nodes; want %d", n, treeMaxNodes-1)
+	}
+	if n := strings.Count(got, "<li>…</li>"); n != 1 {
+		t.Errorf("Got %d omitted items; want 1", n)
+	}
+}
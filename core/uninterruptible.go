@@ -0,0 +1,43 @@
+package core
+
+// RunUninterruptible runs fn which can not be interrupted (e.g. a blocking cgo call) in a new goroutine
+// and returns the error of fn. If the current code execution is canceled before fn returns, RunUninterruptible
+// returns Bailout without waiting for fn so that the execution can finish. Note that fn keeps running
+// in background in that case and its result is discarded. The goroutine is tracked like goroutines started
+// with Go, so it is listed by Goroutines and reported as hanging if it does not return in time after
+// the cancellation. A panic in fn is handled as a panic of the goroutine, which cancels the execution.
+//
+// If fn returns when the execution is canceled, the result of fn is returned if it is ready.
+// RunUninterruptible returns Bailout without calling fn if the execution is canceled already
+// or lgo does not execute any code blocks.
+func RunUninterruptible(fn func() error) error {
+	e := getExecState()
+	if e == nil || e.Context.Err() != nil {
+		return Bailout
+	}
+	const name = "RunUninterruptible"
+	id, started := e.addGoroutine(name, func() {})
+	l := goroutineLabel{id: id, name: name, start: started}
+	e.initGoroutine(l)
+	// Buffered so that the goroutine does not block after RunUninterruptible returns.
+	c := make(chan error, 1)
+	go func() {
+		defer func() {
+			e.finalizeGoroutine(recover(), l)
+		}()
+		defer e.removeGoroutine(id)
+		c <- fn()
+	}()
+	select {
+	case err := <-c:
+		return err
+	case <-e.Context.Done():
+	}
+	// fn may return at the same time as the cancellation.
+	select {
+	case err := <-c:
+		return err
+	default:
+		return Bailout
+	}
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunUninterruptible(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	fnErr := errors.New("fn failed")
+	var errs []error
+	r, err := ExecLgoEntryPointReport(LgoContext{Context: context.Background()}, func() {
+		errs = append(errs, RunUninterruptible(func() error { return nil }))
+		errs = append(errs, RunUninterruptible(func() error { return fnErr }))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 2 || errs[0] != nil || errs[1] != fnErr {
+		t.Errorf("Got %v; want [<nil> %v]", errs, fnErr)
+	}
+	if r.Goroutines != 2 {
+		t.Errorf("Got %d; want 2", r.Goroutines)
+	}
+	if err := RunUninterruptible(func() error {
+		t.Error("fn must not be called when lgo does not execute any code blocks")
+		return nil
+	}); err != Bailout {
+		t.Errorf("Got %v; want %v", err, Bailout)
+	}
+}
+
+func TestRunUninterruptibleCanceled(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	var runErr error
+	var running int
+	e := startExec(LgoContext{Context: ctx}, func() {
+		runErr = RunUninterruptible(func() error {
+			cancel()
+			// Blocks like a cgo call which ignores the cancellation.
+			<-release
+			close(finished)
+			return nil
+		})
+		running = len(Goroutines())
+	})
+	<-e.mainDone
+	if runErr != Bailout {
+		t.Errorf("Got %v; want %v", runErr, Bailout)
+	}
+	if running != 1 {
+		t.Errorf("Got %d; want 1", running)
+	}
+	close(release)
+	r, _ := finalizeExecReport(e)
+	<-finished
+	if r.Goroutines != 1 || r.HangingGoroutines != 0 {
+		t.Errorf("Unexpected report: %+v", r)
+	}
+}
@@ -0,0 +1,247 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"reflect"
+	"sort"
+)
+
+// valueDiffKind is the kind of a difference between two values.
+type valueDiffKind int
+
+const (
+	valueChanged valueDiffKind = iota
+	valueAdded
+	valueRemoved
+	// valueCycle marks a reference compared again in a cycle. It is not a difference.
+	valueCycle
+)
+
+// valueDiff is a difference between two values at path.
+type valueDiff struct {
+	kind valueDiffKind
+	// path is the location of the difference in the values (e.g. ".Field[0]").
+	path string
+	// a and b are the formatted values. a is empty for valueAdded and b is empty for valueRemoved.
+	a, b string
+}
+
+const (
+	// maxValueDiffDepth limits the depth of nested values compared by diffValues.
+	maxValueDiffDepth = 32
+	// maxValueDiffs is the maximum number of differences reported by diffValues.
+	maxValueDiffs = 1000
+)
+
+// diffVisitKey identifies a pair of references compared by valueDiffer.
+type diffVisitKey struct {
+	a, b uintptr
+	typ  reflect.Type
+	len  int
+}
+
+// valueDiffer collects the differences between two values.
+type valueDiffer struct {
+	diffs []valueDiff
+	// visiting keeps the pairs of references being compared to detect cycles.
+	visiting map[diffVisitKey]bool
+	// done keeps the pairs of references already compared.
+	done map[diffVisitKey]bool
+	// truncated indicates differences are omitted because of maxValueDiffs.
+	truncated bool
+}
+
+// diffValues returns the differences between a and b.
+// Structs are compared field by field, slices and arrays by index and maps by key.
+// truncated is true if differences exceeding maxValueDiffs are omitted.
+func diffValues(a, b reflect.Value) (diffs []valueDiff, truncated bool) {
+	df := &valueDiffer{visiting: make(map[diffVisitKey]bool), done: make(map[diffVisitKey]bool)}
+	df.diff("", a, b, 0)
+	return df.diffs, df.truncated
+}
+
+// add adds a difference unless the number of differences reaches maxValueDiffs.
+func (df *valueDiffer) add(d valueDiff) {
+	if len(df.diffs) >= maxValueDiffs {
+		df.truncated = true
+		return
+	}
+	df.diffs = append(df.diffs, d)
+}
+
+// enter marks the references of a and b as being compared. It returns false if they are
+// already compared or being compared in a cycle. leave must be called after a and b are compared.
+func (df *valueDiffer) enter(path string, k diffVisitKey) bool {
+	if df.visiting[k] {
+		df.add(valueDiff{kind: valueCycle, path: path, a: "(cycle)", b: "(cycle)"})
+		return false
+	}
+	if df.done[k] {
+		return false
+	}
+	df.visiting[k] = true
+	return true
+}
+
+func (df *valueDiffer) leave(k diffVisitKey) {
+	delete(df.visiting, k)
+	df.done[k] = true
+}
+
+// refKey returns the key of the references of a and b of the same type. ok is false if they are not references.
+func refKey(a, b reflect.Value) (k diffVisitKey, ok bool) {
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if a.Pointer() == 0 || b.Pointer() == 0 {
+			return k, false
+		}
+		k = diffVisitKey{a: a.Pointer(), b: b.Pointer(), typ: a.Type()}
+		if a.Kind() == reflect.Slice {
+			k.len = a.Len()
+		}
+		return k, true
+	}
+	return k, false
+}
+
+// diff adds the differences between a and b at path.
+func (df *valueDiffer) diff(path string, a, b reflect.Value, depth int) {
+	if df.truncated {
+		return
+	}
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			df.add(valueDiff{kind: valueChanged, path: path, a: formatReflectValue(a), b: formatReflectValue(b)})
+		}
+		return
+	}
+	if a.Type() != b.Type() || depth > maxValueDiffDepth {
+		if !reflectValuesEqual(a, b) {
+			df.add(valueDiff{kind: valueChanged, path: path, a: formatReflectValue(a), b: formatReflectValue(b)})
+		}
+		return
+	}
+	if k, ok := refKey(a, b); ok {
+		if !df.enter(path, k) {
+			return
+		}
+		defer df.leave(k)
+	}
+	switch a.Kind() {
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			df.diff(path+"."+a.Type().Field(i).Name, a.Field(i), b.Field(i), depth+1)
+		}
+		return
+	case reflect.Slice, reflect.Array:
+		n := a.Len()
+		if b.Len() < n {
+			n = b.Len()
+		}
+		for i := 0; i < n; i++ {
+			df.diff(fmt.Sprintf("%s[%d]", path, i), a.Index(i), b.Index(i), depth+1)
+		}
+		for i := n; i < a.Len(); i++ {
+			df.add(valueDiff{kind: valueRemoved, path: fmt.Sprintf("%s[%d]", path, i), a: formatReflectValue(a.Index(i))})
+		}
+		for i := n; i < b.Len(); i++ {
+			df.add(valueDiff{kind: valueAdded, path: fmt.Sprintf("%s[%d]", path, i), b: formatReflectValue(b.Index(i))})
+		}
+		return
+	case reflect.Map:
+		keys := make(map[string]reflect.Value)
+		for _, k := range a.MapKeys() {
+			keys[fmt.Sprintf("%#v", k)] = k
+		}
+		for _, k := range b.MapKeys() {
+			keys[fmt.Sprintf("%#v", k)] = k
+		}
+		names := make([]string, 0, len(keys))
+		for name := range keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			k := keys[name]
+			p := path + "[" + name + "]"
+			av, bv := a.MapIndex(k), b.MapIndex(k)
+			switch {
+			case !bv.IsValid():
+				df.add(valueDiff{kind: valueRemoved, path: p, a: formatReflectValue(av)})
+			case !av.IsValid():
+				df.add(valueDiff{kind: valueAdded, path: p, b: formatReflectValue(bv)})
+			default:
+				df.diff(p, av, bv, depth+1)
+			}
+		}
+		return
+	case reflect.Ptr, reflect.Interface:
+		if !a.IsNil() && !b.IsNil() {
+			df.diff(path, a.Elem(), b.Elem(), depth+1)
+			return
+		}
+	}
+	if !reflectValuesEqual(a, b) {
+		df.add(valueDiff{kind: valueChanged, path: path, a: formatReflectValue(a), b: formatReflectValue(b)})
+	}
+}
+
+// reflectValuesEqual reports whether a and b are deeply equal.
+// Values in unexported fields, which can not be accessed with Interface, are compared by their formatted strings.
+func reflectValuesEqual(a, b reflect.Value) bool {
+	if a.IsValid() && b.IsValid() && a.CanInterface() && b.CanInterface() {
+		return reflect.DeepEqual(a.Interface(), b.Interface())
+	}
+	return formatReflectValue(a) == formatReflectValue(b)
+}
+
+func formatReflectValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%#v", v)
+}
+
+// DisplayDiff displays the differences between a and b as an HTML table for debugging
+// (e.g. comparing expected and actual values). Structs are compared field by field,
+// slices by index and maps by key. Added, removed and changed values are colored differently.
+func DisplayDiff(d DataDisplayer, a, b interface{}, id *string) {
+	diffs, truncated := diffValues(reflect.ValueOf(a), reflect.ValueOf(b))
+	if !hasValueDiffs(diffs) {
+		d.HTML(`<div class="lgo-diff">no differences</div>`, id)
+		return
+	}
+	var buf bytes.Buffer
+	buf.WriteString(`<table class="lgo-diff"><thead><tr><th>path</th><th>a</th><th>b</th></tr></thead><tbody>`)
+	for _, diff := range diffs {
+		path := diff.path
+		if path == "" {
+			path = "(value)"
+		}
+		color := map[valueDiffKind]string{
+			valueChanged: "#ffc",
+			valueAdded:   "#dfd",
+			valueRemoved: "#fdd",
+			valueCycle:   "#eee",
+		}[diff.kind]
+		fmt.Fprintf(&buf, `<tr style="background:%s"><td>%s</td><td><code>%s</code></td><td><code>%s</code></td></tr>`,
+			color, html.EscapeString(path), html.EscapeString(diff.a), html.EscapeString(diff.b))
+	}
+	if truncated {
+		fmt.Fprintf(&buf, `<tr><td colspan="3">(more than %d differences are omitted)</td></tr>`, maxValueDiffs)
+	}
+	buf.WriteString("</tbody></table>")
+	d.HTML(buf.String(), id)
+}
+
+// hasValueDiffs returns whether diffs contain differences other than cycles.
+func hasValueDiffs(diffs []valueDiff) bool {
+	for _, d := range diffs {
+		if d.kind != valueCycle {
+			return true
+		}
+	}
+	return false
+}
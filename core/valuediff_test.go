@@ -0,0 +1,122 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type diffInner struct {
+	N int
+}
+
+type diffOuter struct {
+	Name  string
+	Inner *diffInner
+	List  []int
+	Map   map[string]int
+	priv  bool
+}
+
+// diffsOf returns the differences returned from diffValues.
+func diffsOf(a, b reflect.Value) []valueDiff {
+	diffs, _ := diffValues(a, b)
+	return diffs
+}
+
+func TestDiffValues(t *testing.T) {
+	a := diffOuter{
+		Name:  "a",
+		Inner: &diffInner{1},
+		List:  []int{1, 2, 3},
+		Map:   map[string]int{"x": 1, "y": 2},
+	}
+	b := diffOuter{
+		Name:  "b",
+		Inner: &diffInner{2},
+		List:  []int{1, 4},
+		Map:   map[string]int{"y": 2, "z": 3},
+		priv:  true,
+	}
+	got, _ := diffValues(reflect.ValueOf(a), reflect.ValueOf(b))
+	want := []valueDiff{
+		{valueChanged, ".Name", `"a"`, `"b"`},
+		{valueChanged, ".Inner.N", "1", "2"},
+		{valueChanged, ".List[1]", "2", "4"},
+		{valueRemoved, ".List[2]", "3", ""},
+		{valueRemoved, `.Map["x"]`, "1", ""},
+		{valueAdded, `.Map["z"]`, "", "3"},
+		{valueChanged, ".priv", "false", "true"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v; want %v", got, want)
+	}
+	if diffs, _ := diffValues(reflect.ValueOf(a), reflect.ValueOf(a)); len(diffs) != 0 {
+		t.Errorf("Unexpected diffs: %v", diffs)
+	}
+	if got, want := diffsOf(reflect.ValueOf(1), reflect.ValueOf("1")), []valueDiff{{valueChanged, "", "1", `"1"`}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v; want %v", got, want)
+	}
+}
+
+func TestDiffValuesCycle(t *testing.T) {
+	type node struct {
+		Prev, Next *node
+		V          int
+	}
+	// ring returns a ring of two nodes.
+	ring := func(v0, v1 int) *node {
+		n0, n1 := &node{V: v0}, &node{V: v1}
+		n0.Prev, n0.Next, n1.Prev, n1.Next = n1, n1, n0, n0
+		return n0
+	}
+	got, truncated := diffValues(reflect.ValueOf(ring(1, 2)), reflect.ValueOf(ring(1, 3)))
+	want := []valueDiff{
+		{valueCycle, ".Prev.Prev", "(cycle)", "(cycle)"},
+		{valueCycle, ".Prev.Next", "(cycle)", "(cycle)"},
+		{valueChanged, ".Prev.V", "2", "3"},
+	}
+	if !reflect.DeepEqual(got, want) || truncated {
+		t.Errorf("Got %v (truncated: %v); want %v", got, truncated, want)
+	}
+
+	var d fakeDisplayer
+	DisplayDiff(&d, ring(1, 2), ring(1, 2), nil)
+	if got, want := d.contents[0].content, `<div class="lgo-diff">no differences</div>`; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+}
+
+func TestDiffValuesTruncated(t *testing.T) {
+	a, b := make([]int, maxValueDiffs+10), make([]int, maxValueDiffs+10)
+	for i := range b {
+		b[i] = 1
+	}
+	diffs, truncated := diffValues(reflect.ValueOf(a), reflect.ValueOf(b))
+	if len(diffs) != maxValueDiffs || !truncated {
+		t.Errorf("Got %d diffs (truncated: %v); want %d", len(diffs), truncated, maxValueDiffs)
+	}
+	var d fakeDisplayer
+	DisplayDiff(&d, a, b, nil)
+	if got, want := d.contents[0].content.(string), "(more than 1000 differences are omitted)"; !strings.Contains(got, want) {
+		t.Errorf("%q does not contain %q", got[len(got)-100:], want)
+	}
+}
+
+func TestDisplayDiff(t *testing.T) {
+	var d fakeDisplayer
+	DisplayDiff(&d, map[string]string{"k": "<a>"}, map[string]string{"k": "<b>"}, nil)
+	DisplayDiff(&d, 1, 1, nil)
+	if len(d.contents) != 2 {
+		t.Fatalf("Unexpected outputs: %v", d.contents)
+	}
+	got := d.contents[0].content.(string)
+	for _, want := range []string{`<td>[&#34;k&#34;]</td>`, `<code>&#34;&lt;a&gt;&#34;</code>`, `background:#ffc`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%q does not contain %q", got, want)
+		}
+	}
+	if got, want := d.contents[1].content, `<div class="lgo-diff">no differences</div>`; got != want {
+		t.Errorf("Got %q; want %q", got, want)
+	}
+}
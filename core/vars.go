@@ -0,0 +1,151 @@
+package core
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+)
+
+// latestVar returns the pointer to the latest variable registered with name.
+func latestVar(name string) (interface{}, bool) {
+	allVarsMu.Lock()
+	defer allVarsMu.Unlock()
+	vars := AllVars[name]
+	if len(vars) == 0 {
+		return nil, false
+	}
+	return vars[len(vars)-1], true
+}
+
+// GetVarValue returns the current value of the variable defined in lgo with name.
+// If the variable is redeclared, the latest one is used. It returns false if name is unknown.
+func GetVarValue(name string) (interface{}, bool) {
+	p, ok := latestVar(name)
+	if !ok {
+		return nil, false
+	}
+	return reflect.ValueOf(p).Elem().Interface(), true
+}
+
+// VarCodec is the interface that serializes variables for ExportVars and ImportVars.
+// vars is keyed by variable names.
+type VarCodec interface {
+	Encode(w io.Writer, vars map[string]interface{}) error
+	Decode(r io.Reader) (map[string]interface{}, error)
+}
+
+// JSONCodec is a VarCodec which serializes variables as a JSON object.
+// Decode returns values as json.RawMessage, which ImportVars unmarshals into the variables.
+type JSONCodec struct{}
+
+// Encode implements VarCodec.
+func (JSONCodec) Encode(w io.Writer, vars map[string]interface{}) error {
+	return json.NewEncoder(w).Encode(vars)
+}
+
+// Decode implements VarCodec.
+func (JSONCodec) Decode(r io.Reader) (map[string]interface{}, error) {
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	vars := make(map[string]interface{})
+	for k, v := range raw {
+		vars[k] = v
+	}
+	return vars, nil
+}
+
+// GobCodec is a VarCodec which serializes variables with encoding/gob.
+// GobCodec handles more Go types than JSONCodec, but the types of values except basic types
+// must be registered with gob.Register before Encode and Decode.
+type GobCodec struct{}
+
+// Encode implements VarCodec.
+func (GobCodec) Encode(w io.Writer, vars map[string]interface{}) error {
+	return gob.NewEncoder(w).Encode(vars)
+}
+
+// Decode implements VarCodec.
+func (GobCodec) Decode(r io.Reader) (map[string]interface{}, error) {
+	var vars map[string]interface{}
+	if err := gob.NewDecoder(r).Decode(&vars); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// ExportVars writes the current values of the variables defined in lgo to w with codec.
+// If names is empty, all variables are exported. If a variable is redeclared, the latest one is used.
+func ExportVars(w io.Writer, codec VarCodec, names ...string) error {
+	if len(names) == 0 {
+		allVarsMu.Lock()
+		for name := range AllVars {
+			names = append(names, name)
+		}
+		allVarsMu.Unlock()
+	}
+	vars := make(map[string]interface{})
+	for _, name := range names {
+		v, ok := GetVarValue(name)
+		if !ok {
+			return fmt.Errorf("unknown variable: %s", name)
+		}
+		vars[name] = v
+	}
+	return codec.Encode(w, vars)
+}
+
+// ImportVars reads variables written with ExportVars from r with codec and assigns them to
+// the variables defined in lgo with the same names. No variables are modified if an error is returned.
+func ImportVars(r io.Reader, codec VarCodec) error {
+	vars, err := codec.Decode(r)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var dsts, srcs []reflect.Value
+	for _, name := range names {
+		p, ok := latestVar(name)
+		if !ok {
+			return fmt.Errorf("unknown variable: %s", name)
+		}
+		dst := reflect.ValueOf(p).Elem()
+		src, err := importValue(dst.Type(), vars[name])
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %v", name, err)
+		}
+		dsts = append(dsts, dst)
+		srcs = append(srcs, src)
+	}
+	for i, dst := range dsts {
+		dst.Set(srcs[i])
+	}
+	return nil
+}
+
+// importValue converts a decoded value v to a value of t.
+func importValue(t reflect.Type, v interface{}) (reflect.Value, error) {
+	if raw, ok := v.(json.RawMessage); ok {
+		p := reflect.New(t)
+		if err := json.Unmarshal(raw, p.Interface()); err != nil {
+			return reflect.Value{}, err
+		}
+		return p.Elem(), nil
+	}
+	if v == nil {
+		return reflect.Zero(t), nil
+	}
+	rv := reflect.ValueOf(v)
+	if !rv.Type().AssignableTo(t) {
+		return reflect.Value{}, fmt.Errorf("cannot assign %v to %v", rv.Type(), t)
+	}
+	return rv, nil
+}
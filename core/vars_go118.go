@@ -0,0 +1,21 @@
+//go:build go1.18
+// +build go1.18
+
+package core
+
+// GetVar returns the current value of the variable defined in lgo with name as T.
+// If the variable is redeclared, the latest one is used.
+// It returns false if name is unknown or the value of the variable is not T.
+func GetVar[T any](name string) (T, bool) {
+	var zero T
+	p, ok := latestVar(name)
+	if !ok {
+		return zero, false
+	}
+	if tp, ok := p.(*T); ok {
+		return *tp, true
+	}
+	v, _ := GetVarValue(name)
+	t, ok := v.(T)
+	return t, ok
+}
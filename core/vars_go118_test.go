@@ -0,0 +1,37 @@
+//go:build go1.18
+// +build go1.18
+
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetVar(t *testing.T) {
+	defer func() {
+		AllVars = make(map[string][]interface{})
+		varAccessed = make(map[string]bool)
+	}()
+	n := 3
+	var err error
+	LgoRegisterVar("n", &n)
+	LgoRegisterVar("err", &err)
+	if v, ok := GetVar[int]("n"); !ok || v != 3 {
+		t.Errorf("Got (%v, %v); want (3, true)", v, ok)
+	}
+	if _, ok := GetVar[string]("n"); ok {
+		t.Error("GetVar returned true for a wrong type")
+	}
+	if _, ok := GetVar[int]("unknown"); ok {
+		t.Error("GetVar returned true for an unknown name")
+	}
+	// nil interface value
+	if v, ok := GetVar[error]("err"); !ok || v != nil {
+		t.Errorf("Got (%v, %v); want (nil, true)", v, ok)
+	}
+	err = errors.New("e")
+	if v, ok := GetVar[interface{}]("err"); !ok || v != err {
+		t.Errorf("Got (%v, %v); want (%v, true)", v, ok, err)
+	}
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"testing"
+)
+
+func TestGetVarValue(t *testing.T) {
+	defer func() {
+		AllVars = make(map[string][]interface{})
+		varAccessed = make(map[string]bool)
+	}()
+	a := 10
+	LgoRegisterVar("a", &a)
+	a = 20
+	if v, ok := GetVarValue("a"); !ok || v != 20 {
+		t.Errorf("Got (%v, %v); want (20, true)", v, ok)
+	}
+	// Redeclared
+	s := "hello"
+	LgoRegisterVar("a", &s)
+	if v, ok := GetVarValue("a"); !ok || v != "hello" {
+		t.Errorf("Got (%v, %v); want (hello, true)", v, ok)
+	}
+	if _, ok := GetVarValue("unknown"); ok {
+		t.Error("GetVarValue returned true for an unknown name")
+	}
+}
+
+type exportedPoint struct {
+	X, Y int
+	Tags []string
+}
+
+func init() {
+	gob.Register(exportedPoint{})
+}
+
+func TestExportImportVars(t *testing.T) {
+	for _, codec := range []VarCodec{JSONCodec{}, GobCodec{}} {
+		func() {
+			defer func() {
+				AllVars = make(map[string][]interface{})
+				varAccessed = make(map[string]bool)
+			}()
+			p := exportedPoint{1, 2, []string{"a", "b"}}
+			n := 10
+			LgoRegisterVar("p", &p)
+			LgoRegisterVar("n", &n)
+			var buf bytes.Buffer
+			if err := ExportVars(&buf, codec); err != nil {
+				t.Fatalf("%T: %v", codec, err)
+			}
+			p, n = exportedPoint{}, 0
+			if err := ImportVars(&buf, codec); err != nil {
+				t.Fatalf("%T: %v", codec, err)
+			}
+			if want := (exportedPoint{1, 2, []string{"a", "b"}}); !reflect.DeepEqual(p, want) {
+				t.Errorf("%T: Got %v; want %v", codec, p, want)
+			}
+			if n != 10 {
+				t.Errorf("%T: Got %d; want 10", codec, n)
+			}
+		}()
+	}
+}
+
+func TestImportVarsError(t *testing.T) {
+	defer func() {
+		AllVars = make(map[string][]interface{})
+		varAccessed = make(map[string]bool)
+	}()
+	n := 10
+	s := "hello"
+	LgoRegisterVar("n", &n)
+	LgoRegisterVar("s", &s)
+	if err := ExportVars(&bytes.Buffer{}, GobCodec{}, "unknown"); err == nil {
+		t.Error("ExportVars must fail with an unknown name")
+	}
+	var buf bytes.Buffer
+	if err := (GobCodec{}).Encode(&buf, map[string]interface{}{"n": 20, "s": 30}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ImportVars(&buf, GobCodec{}); err == nil {
+		t.Error("ImportVars must fail with an unassignable value")
+	}
+	if n != 10 || s != "hello" {
+		t.Errorf("Variables are modified: n = %d, s = %q", n, s)
+	}
+}
@@ -0,0 +1,22 @@
+package core
+
+import "sync"
+
+// WaitCtx waits for wg like wg.Wait but returns Bailout when the current code execution is canceled.
+// It returns nil when the counter of wg becomes zero.
+//
+// Note that sync.WaitGroup can not be interrupted. WaitCtx calls wg.Wait in a goroutine and
+// the goroutine keeps waiting after WaitCtx returns Bailout until the counter of wg becomes zero.
+func WaitCtx(wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wg.Wait()
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-GetExecContext().Done():
+		return Bailout
+	}
+}
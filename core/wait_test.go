@@ -0,0 +1,45 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWaitCtx(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var err error
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go wg.Done()
+		}
+		err = WaitCtx(&wg)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestWaitCtxCancel(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	// Release the goroutine waiting for wg in WaitCtx.
+	defer wg.Done()
+	var err error
+	if err := ExecLgoEntryPoint(LgoContext{Context: ctx}, func() {
+		cancel()
+		err = WaitCtx(&wg)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err != Bailout {
+		t.Errorf("Got %v; want %v", err, Bailout)
+	}
+}
@@ -0,0 +1,25 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WalkCtx is filepath.Walk which stops walking the file tree rooted at root when the current code execution
+// is canceled. The context is checked before fn is called for each file or directory and WalkCtx returns
+// Bailout if the execution is canceled. Errors returned from fn (including filepath.SkipDir) are handled
+// as filepath.Walk does.
+//
+// Note that filepath.Walk reads all entries of a directory before walking them, so reading a huge directory
+// is not interrupted.
+func WalkCtx(root string, fn filepath.WalkFunc) error {
+	ctx := GetExecContext()
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		select {
+		case <-ctx.Done():
+			return Bailout
+		default:
+		}
+		return fn(path, info, err)
+	})
+}
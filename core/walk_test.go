@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+// makeTree creates dirs directories with files files each under a new temporary directory.
+func makeTree(t *testing.T, dirs, files int) string {
+	root, err := ioutil.TempDir("", "lgo-walk-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < dirs; i++ {
+		dir := filepath.Join(root, fmt.Sprintf("d%d", i))
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for j := 0; j < files; j++ {
+			if err := ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("f%d", j)), nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return root
+}
+
+func TestWalkCtx(t *testing.T) {
+	root := makeTree(t, 3, 2)
+	defer os.RemoveAll(root)
+	atomic.StoreUint32(&isRunning, 0)
+	var visited []string
+	var walkErr error
+	if err := ExecLgoEntryPoint(LgoContext{Context: context.Background()}, func() {
+		walkErr = WalkCtx(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, _ := filepath.Rel(root, path)
+			if info.IsDir() && rel == "d1" {
+				return filepath.SkipDir
+			}
+			visited = append(visited, filepath.ToSlash(rel))
+			return nil
+		})
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if walkErr != nil {
+		t.Error(walkErr)
+	}
+	want := fmt.Sprint([]string{".", "d0", "d0/f0", "d0/f1", "d2", "d2/f0", "d2/f1"})
+	if got := fmt.Sprint(visited); got != want {
+		t.Errorf("Got %s; want %s", got, want)
+	}
+}
+
+func TestWalkCtxCancel(t *testing.T) {
+	root := makeTree(t, 20, 50)
+	defer os.RemoveAll(root)
+	atomic.StoreUint32(&isRunning, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	var n int
+	var walkErr error
+	ExecLgoEntryPoint(LgoContext{Context: ctx}, func() {
+		walkErr = WalkCtx(root, func(path string, info os.FileInfo, err error) error {
+			n++
+			if n == 100 {
+				cancel()
+			}
+			return nil
+		})
+	})
+	if walkErr != Bailout {
+		t.Errorf("Got %v; want %v", walkErr, Bailout)
+	}
+	// The walk stops at the entry right after the cancellation.
+	if n != 100 {
+		t.Errorf("Got %d; want 100", n)
+	}
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"sync"
+)
+
+// warningHandlerMu protects warningHandler.
+var warningHandlerMu sync.Mutex
+
+// warningHandler is the function set by SetWarningHandler.
+var warningHandler func(string)
+
+// SetWarningHandler sets the function which receives warnings emitted with Warn instead of the default output.
+// If fn is nil, the default output is used.
+func SetWarningHandler(fn func(string)) {
+	warningHandlerMu.Lock()
+	defer warningHandlerMu.Unlock()
+	warningHandler = fn
+}
+
+// Warn emits a warning (e.g. a deprecation) formatted with fmt.Sprintf without failing the code execution.
+// Warnings are displayed as warning-styled HTML with the DataDisplayer of the current execution,
+// or printed to stderr when lgo does not execute any code blocks. Warnings are counted in the ExecReport.
+func Warn(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	e := getExecState()
+	if e != nil {
+		e.warnMu.Lock()
+		e.warnings = append(e.warnings, msg)
+		e.warnMu.Unlock()
+	}
+	warningHandlerMu.Lock()
+	fn := warningHandler
+	warningHandlerMu.Unlock()
+	if fn != nil {
+		fn(msg)
+		return
+	}
+	if e != nil && e.Context.Display != nil {
+		e.Context.Display.HTML(warningHTML(msg), nil)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "warning: %s\n", msg)
+}
+
+func warningHTML(msg string) string {
+	return `<div class="lgo-warning" style="background:#fff8e1;border-left:4px solid #ffb300;padding:4px 8px">` +
+		"<b>Warning:</b> " + html.EscapeString(msg) + "</div>"
+}
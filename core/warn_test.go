@@ -0,0 +1,53 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWarn(t *testing.T) {
+	atomic.StoreUint32(&isRunning, 0)
+	var d fakeDisplayer
+	r, err := ExecLgoEntryPointReport(LgoContext{Context: context.Background(), Display: &d}, func() {
+		Warn("%s is deprecated", "<Foo>")
+	})
+	if err != nil {
+		t.Errorf("Warnings must not fail the execution: %v", err)
+	}
+	if want := []string{"<Foo> is deprecated"}; !reflect.DeepEqual(r.Warnings, want) {
+		t.Errorf("Got %v; want %v", r.Warnings, want)
+	}
+	if r.Summary != "" {
+		t.Errorf("Got %q; want an empty summary", r.Summary)
+	}
+	want := `<div class="lgo-warning" style="background:#fff8e1;border-left:4px solid #ffb300;padding:4px 8px"><b>Warning:</b> &lt;Foo&gt; is deprecated</div>`
+	if len(d.contents) != 1 || d.contents[0].content != want {
+		t.Errorf("Unexpected contents: %v", d.contents)
+	}
+}
+
+func TestWarningHandler(t *testing.T) {
+	defer SetWarningHandler(nil)
+	var got []string
+	SetWarningHandler(func(msg string) { got = append(got, msg) })
+	atomic.StoreUint32(&isRunning, 0)
+	var d fakeDisplayer
+	r, err := ExecLgoEntryPointReport(LgoContext{Context: context.Background(), Display: &d}, func() {
+		Warn("a")
+		Warn("b")
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v; want %v", got, want)
+	}
+	if len(r.Warnings) != 2 {
+		t.Errorf("Got %d warnings; want 2", len(r.Warnings))
+	}
+	if len(d.contents) != 0 {
+		t.Errorf("Unexpected contents: %v", d.contents)
+	}
+}
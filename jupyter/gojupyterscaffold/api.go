@@ -1,6 +1,9 @@
 package gojupyterscaffold
 
-import "context"
+import (
+	"context"
+	"errors"
+)
 
 // RequestHandlers is the interface to define handlers to handle Jupyter messages.
 // Except for HandleGoFmt, all mesages are defined in
@@ -21,6 +24,21 @@ type RequestHandlers interface {
 	HandleGoFmt(req *GoFmtRequest) (*GoFmtReply, error)
 }
 
+// statusPublisherKey is the key of the context value which publishes status messages of an execute_request.
+type statusPublisherKey struct{}
+
+// PublishStatusMetadata publishes a busy status message with metadata while the execute_request of ctx,
+// which is passed to HandleExecuteRequest, is handled. Front-ends can show the metadata
+// (e.g. the progress of the execution) even when the outputs of the cell are scrolled away.
+// It returns an error if ctx is not the context of an execute_request or the request has been handled.
+func PublishStatusMetadata(ctx context.Context, metadata map[string]interface{}) error {
+	publish, ok := ctx.Value(statusPublisherKey{}).(func(map[string]interface{}) error)
+	if !ok {
+		return errors.New("not in an execute_request")
+	}
+	return publish(metadata)
+}
+
 // KernelInfo is a reply to kernel_info_request.
 type KernelInfo struct {
 	ProtocolVersion       string             `json:"protocol_version"`
@@ -45,6 +63,8 @@ type ExecuteRequest struct {
 	StoreHistory bool   `json:"store_history"`
 	AllowStdin   bool   `json:"allow_stdin"`
 	StopOnError  bool   `json:"stop_on_error"`
+	// Metadata is the metadata of the execute_request message (e.g. tags of the cell).
+	Metadata map[string]interface{} `json:"-"`
 }
 
 // See http://jupyter-client.readthedocs.io/en/stable/messaging.html#request-reply
@@ -89,9 +89,19 @@ loop:
 		}
 
 		exReq := item.req.Content.(*ExecuteRequest)
+		if md, ok := item.req.Metadata.(*map[string]interface{}); ok {
+			exReq.Metadata = *md
+		}
 		err := q.iopub.WithOngoingContext(func(ctx context.Context) error {
 			cur, cancel := context.WithCancel(ctx)
 			q.currentCtx = &contextAndCancel{cur, cancel}
+			cur = context.WithValue(cur, statusPublisherKey{}, func(metadata map[string]interface{}) error {
+				if err := cur.Err(); err != nil {
+					// Don't publish busy after the execution finishes.
+					return err
+				}
+				return q.iopub.publishStatusWithMetadata("busy", item.req, metadata)
+			})
 			defer func() {
 				cancel()
 				q.currentCtx = nil
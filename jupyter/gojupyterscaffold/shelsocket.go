@@ -95,6 +95,10 @@ func (s *iopubSocket) sendMessage(msg *message) error {
 }
 
 func (s *iopubSocket) publishStatus(status string, parent *message) error {
+	return s.publishStatusWithMetadata(status, parent, nil)
+}
+
+func (s *iopubSocket) publishStatusWithMetadata(status string, parent *message, metadata map[string]interface{}) error {
 	var msg message
 	// TODO: Change the format of Identity to kernel.<uuid>.MsgType.
 	// http://jupyter-client.readthedocs.io/en/latest/messaging.html#the-wire-protocol
@@ -104,6 +108,9 @@ func (s *iopubSocket) publishStatus(status string, parent *message) error {
 	msg.Header.Username = "username"
 	msg.Header.MsgID = genMsgID()
 	msg.ParentHeader = parent.Header
+	if metadata != nil {
+		msg.Metadata = metadata
+	}
 	msg.Content = &struct {
 		ExecutionState string `json:"execution_state"`
 	}{